@@ -0,0 +1,76 @@
+// Decrypt backups archives offline, to disaster recovery without panel.
+//
+//	ENCRYPT_PASSWORD=key decrypt [-o backup.zip] backup.zip.enc
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"sirherobrine23.com.br/go-bds/bds/module/encrypt"
+)
+
+func main() {
+	key := flag.String("key", os.Getenv("ENCRYPT_PASSWORD"), "instance encrypt key, default from ENCRYPT_PASSWORD environment")
+	output := flag.String("o", "", "output file, \"-\" to stdout, default is input without .enc extension")
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "usage: %s [-key key] [-o output] <backup.zip.enc>\n", os.Args[0])
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	if flag.NArg() != 1 {
+		flag.Usage()
+		os.Exit(2)
+	} else if *key == "" {
+		fmt.Fprintln(os.Stderr, "encrypt key not set, use -key or ENCRYPT_PASSWORD")
+		os.Exit(2)
+	}
+
+	input := flag.Arg(0)
+	if *output == "" {
+		*output = strings.TrimSuffix(input, ".enc")
+		if *output == input {
+			*output += ".zip"
+		}
+	}
+
+	if err := decrypt(input, *output, *key); err != nil {
+		fmt.Fprintf(os.Stderr, "cannot decrypt %s: %s\n", input, err)
+		os.Exit(1)
+	}
+}
+
+func decrypt(input, output, key string) error {
+	in, err := os.Open(input)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	r, err := encrypt.NewReader(in, key)
+	if err != nil {
+		return err
+	}
+
+	if output == "-" {
+		_, err = io.Copy(os.Stdout, r)
+		return err
+	}
+
+	out, err := os.Create(output)
+	if err != nil {
+		return err
+	}
+	defer out.Close()
+
+	if _, err = io.Copy(out, r); err != nil {
+		out.Close()
+		os.Remove(output)
+		return err
+	}
+	return out.Close()
+}
@@ -1,14 +1,17 @@
 module sirherobrine23.com.br/go-bds/bds
 
-go 1.24
+go 1.25
 
 require (
+	github.com/coder/websocket v1.8.15
 	github.com/denisenkom/go-mssqldb v0.12.3
 	github.com/docker/docker v28.1.1+incompatible
 	github.com/go-chi/chi/v5 v5.2.2
 	github.com/go-sql-driver/mysql v1.9.2
+	github.com/google/uuid v1.6.0
 	github.com/lib/pq v1.10.9
 	github.com/mattn/go-sqlite3 v1.14.28
+	github.com/pkg/sftp v1.13.9
 	golang.org/x/crypto v0.38.0
 	modernc.org/sqlite v1.37.1
 )
@@ -18,7 +21,7 @@ require (
 	github.com/dustin/go-humanize v1.0.1 // indirect
 	github.com/golang-sql/civil v0.0.0-20220223132316-b832511892a9 // indirect
 	github.com/golang-sql/sqlexp v0.1.0 // indirect
-	github.com/google/uuid v1.6.0 // indirect
+	github.com/kr/fs v0.1.0 // indirect
 	github.com/mattn/go-isatty v0.0.20 // indirect
 	github.com/ncruces/go-strftime v0.1.9 // indirect
 	github.com/remyoudompheng/bigfft v0.0.0-20230129092748-24d4a6f8daec // indirect
@@ -0,0 +1,416 @@
+// Bedrock behavior and resource packs: manifest.json, .mcpack/.mcaddon install and worlds packs
+package addons
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/google/uuid"
+	"sirherobrine23.com.br/go-bds/bds/module/backup"
+)
+
+const ManifestFile = "manifest.json"
+
+var (
+	ErrInvalidManifest error = errors.New("invalid pack manifest.json")
+	ErrNoPacks         error = errors.New("archive not have packs")
+	ErrPackNotExists   error = errors.New("pack not installed")
+	ErrPackExists      error = errors.New("pack already installed in this version")
+	ErrDependency      error = errors.New("pack dependencies conflict")
+
+	MaxNested = 2 // Max .mcpack inside .mcaddon depth
+)
+
+var packsMu sync.Mutex // Lock packs directories and worlds packs files
+
+// Pack type by install directory
+type PackType string
+
+const (
+	Behavior PackType = "behavior"
+	Resource PackType = "resource"
+)
+
+// Directory in server with installed packs
+func (packType PackType) Dir() string { return string(packType) + "_packs" }
+
+// World file with enabled packs
+func (packType PackType) WorldFile() string { return "world_" + string(packType) + "_packs.json" }
+
+// Pack version, manifest v2 use [1, 0, 0] and v3 can use "1.0.0"
+type Version [3]int
+
+func (version Version) String() string {
+	return fmt.Sprintf("%d.%d.%d", version[0], version[1], version[2])
+}
+
+// Compare versions, return -1, 0 or 1
+func (version Version) Compare(other Version) int { return slices.Compare(version[:], other[:]) }
+
+func (version *Version) UnmarshalJSON(data []byte) error {
+	var numbers []int
+	if err := json.Unmarshal(data, &numbers); err == nil {
+		*version = Version{}
+		copy(version[:], numbers)
+		return nil
+	}
+
+	var text string
+	if err := json.Unmarshal(data, &text); err != nil {
+		return fmt.Errorf("invalid version: %s", data)
+	}
+	text, _, _ = strings.Cut(text, "-") // Prerelease like "1.0.0-beta"
+	*version = Version{}
+	for index, part := range strings.SplitN(text, ".", 3) {
+		number, err := strconv.Atoi(part)
+		if err != nil {
+			return fmt.Errorf("invalid version: %s", data)
+		}
+		version[index] = number
+	}
+	return nil
+}
+
+// Pack or module dependency, script packs use module_name to @minecraft modules
+type Dependency struct {
+	UUID       string  `json:"uuid,omitempty"`
+	ModuleName string  `json:"module_name,omitempty"`
+	Version    Version `json:"version"`
+}
+
+type Module struct {
+	Type        string  `json:"type"` // data, resources, script, client_data, skin_pack, world_template
+	UUID        string  `json:"uuid"`
+	Version     Version `json:"version"`
+	Description string  `json:"description,omitempty"`
+}
+
+type Header struct {
+	Name             string   `json:"name"`
+	Description      string   `json:"description"`
+	UUID             string   `json:"uuid"`
+	Version          Version  `json:"version"`
+	MinEngineVersion *Version `json:"min_engine_version,omitempty"`
+}
+
+// Pack manifest.json
+type Manifest struct {
+	FormatVersion int          `json:"format_version"`
+	Header        Header       `json:"header"`
+	Modules       []Module     `json:"modules"`
+	Dependencies  []Dependency `json:"dependencies,omitempty"`
+}
+
+// Pack type from modules, empty if pack cannot be installed on server
+func (manifest *Manifest) Type() PackType {
+	for _, module := range manifest.Modules {
+		switch module.Type {
+		case "data", "script":
+			return Behavior
+		case "resources":
+			return Resource
+		}
+	}
+	return ""
+}
+
+// Remove "//" line comments, manifests made by hand commonly have it
+func stripComments(data []byte) []byte {
+	var out bytes.Buffer
+	inString, escaped := false, false
+	for index := 0; index < len(data); index++ {
+		char := data[index]
+		if inString {
+			switch {
+			case escaped:
+				escaped = false
+			case char == '\\':
+				escaped = true
+			case char == '"':
+				inString = false
+			}
+		} else if char == '"' {
+			inString = true
+		} else if char == '/' && index+1 < len(data) && data[index+1] == '/' {
+			for index < len(data) && data[index] != '\n' {
+				index++
+			}
+		}
+		if index < len(data) {
+			out.WriteByte(data[index])
+		}
+	}
+	return out.Bytes()
+}
+
+// Parse manifest.json
+func ParseManifest(r io.Reader) (*Manifest, error) {
+	data, err := io.ReadAll(io.LimitReader(r, 1<<20))
+	if err != nil {
+		return nil, err
+	}
+	data = bytes.TrimPrefix(data, []byte("\xef\xbb\xbf")) // BOM
+
+	var manifest Manifest
+	if err := json.Unmarshal(stripComments(data), &manifest); err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrInvalidManifest, err)
+	} else if len(manifest.Modules) == 0 {
+		return nil, ErrInvalidManifest
+	}
+
+	// UUID is used as pack directory name
+	id, err := uuid.Parse(manifest.Header.UUID)
+	if err != nil {
+		return nil, fmt.Errorf("%w: invalid header uuid %q", ErrInvalidManifest, manifest.Header.UUID)
+	}
+	manifest.Header.UUID = id.String()
+	for index, dep := range manifest.Dependencies {
+		if dep.UUID == "" {
+			continue // Module dependency
+		} else if id, err = uuid.Parse(dep.UUID); err != nil {
+			return nil, fmt.Errorf("%w: invalid dependency uuid %q", ErrInvalidManifest, dep.UUID)
+		}
+		manifest.Dependencies[index].UUID = id.String()
+	}
+	return &manifest, nil
+}
+
+// Installed pack
+type Pack struct {
+	*Manifest
+	Type PackType `json:"type"`
+	Dir  string   `json:"dir"` // Path relative to server directory
+}
+
+func readPack(dir string) (*Manifest, error) {
+	file, err := os.Open(filepath.Join(dir, ManifestFile))
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	return ParseManifest(file)
+}
+
+// List packs installed by [Install] in server behavior_packs and resource_packs, directory name is pack UUID.
+// Vanilla packs shipped with server are not listed
+func List(dir string) ([]*Pack, error) {
+	packsMu.Lock()
+	defer packsMu.Unlock()
+	return list(dir)
+}
+
+func list(dir string) ([]*Pack, error) {
+	packs := []*Pack{}
+	for _, packType := range []PackType{Behavior, Resource} {
+		entries, err := os.ReadDir(filepath.Join(dir, packType.Dir()))
+		if err != nil {
+			if errors.Is(err, fs.ErrNotExist) {
+				continue
+			}
+			return nil, err
+		}
+		for _, entry := range entries {
+			if !entry.IsDir() {
+				continue
+			}
+			manifest, err := readPack(filepath.Join(dir, packType.Dir(), entry.Name()))
+			if err != nil || manifest.Header.UUID != entry.Name() {
+				continue // Not pack or not installed by panel
+			}
+			packs = append(packs, &Pack{Manifest: manifest, Type: packType, Dir: packType.Dir() + "/" + entry.Name()})
+		}
+	}
+	return packs, nil
+}
+
+// Find installed pack by header UUID
+func Find(packs []*Pack, uuid string) *Pack {
+	uuid = strings.ToLower(uuid)
+	for _, pack := range packs {
+		if pack.Header.UUID == uuid {
+			return pack
+		}
+	}
+	return nil
+}
+
+// Pack directories with manifest.json in extracted archive, nested .mcpack files are extracted
+func findPacks(root string, depth int) ([]string, error) {
+	var dirs []string
+	err := filepath.WalkDir(root, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if entry.IsDir() {
+			if _, err := os.Stat(filepath.Join(path, ManifestFile)); err == nil {
+				dirs = append(dirs, path)
+				return fs.SkipDir // Subpacks are part of pack
+			}
+			return nil
+		}
+
+		ext := strings.ToLower(filepath.Ext(path))
+		if depth >= MaxNested || (ext != ".mcpack" && ext != ".zip") {
+			return nil
+		}
+		zr, err := zip.OpenReader(path)
+		if err != nil {
+			return nil // Not archive, keep as pack file
+		}
+		target := strings.TrimSuffix(path, filepath.Ext(path)) + ".extracted"
+		err = backup.Extract(&zr.Reader, target)
+		zr.Close()
+		if err != nil {
+			return err
+		}
+		nested, err := findPacks(target, depth+1)
+		dirs = append(dirs, nested...)
+		return err
+	})
+	return dirs, err
+}
+
+// Install packs from .mcpack or .mcaddon archive to server directory.
+//
+// Packs installed with other version are replaced and worlds packs updated to new version.
+func Install(dir string, r io.ReaderAt, size int64) ([]*Pack, error) {
+	zr, err := zip.NewReader(r, size)
+	if err != nil {
+		return nil, fmt.Errorf("cannot read pack archive: %s", err)
+	}
+
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, err
+	}
+	tmp, err := os.MkdirTemp(dir, ".addon-")
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(tmp)
+	if err = backup.Extract(zr, tmp); err != nil {
+		return nil, fmt.Errorf("cannot extract pack archive: %s", err)
+	}
+	dirs, err := findPacks(tmp, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	type newPack struct {
+		manifest *Manifest
+		dir      string
+	}
+	var found []newPack
+	for _, packDir := range dirs {
+		manifest, err := readPack(packDir)
+		if err != nil {
+			return nil, err
+		} else if manifest.Type() == "" {
+			continue // Skins and world templates
+		}
+		found = append(found, newPack{manifest, packDir})
+	}
+	if len(found) == 0 {
+		return nil, ErrNoPacks
+	}
+
+	packsMu.Lock()
+	defer packsMu.Unlock()
+	installed, err := list(dir)
+	if err != nil {
+		return nil, err
+	}
+	for _, pack := range found {
+		if old := Find(installed, pack.manifest.Header.UUID); old != nil && old.Header.Version == pack.manifest.Header.Version {
+			return nil, fmt.Errorf("%w: %s %s", ErrPackExists, pack.manifest.Header.Name, pack.manifest.Header.Version)
+		}
+	}
+
+	var packs []*Pack
+	for index, pack := range found {
+		packType := pack.manifest.Type()
+		name := packType.Dir() + "/" + pack.manifest.Header.UUID
+		packsDir, target := filepath.Join(dir, packType.Dir()), filepath.Join(dir, filepath.FromSlash(name))
+		if filepath.Dir(target) != packsDir {
+			return packs, fmt.Errorf("%w: pack directory outside %s", ErrInvalidManifest, packType.Dir())
+		} else if err := os.MkdirAll(packsDir, 0755); err != nil {
+			return packs, err
+		}
+
+		// Old version is moved aside and removed with temporary directory after new version is in place
+		var oldDir, aside string
+		if old := Find(installed, pack.manifest.Header.UUID); old != nil {
+			oldDir, aside = filepath.Join(dir, filepath.FromSlash(old.Dir)), filepath.Join(tmp, ".old-"+strconv.Itoa(index))
+			if err := os.Rename(oldDir, aside); err != nil {
+				return packs, err
+			}
+		}
+		if err := os.Rename(pack.dir, target); err != nil {
+			if aside != "" {
+				os.Rename(aside, oldDir)
+			}
+			return packs, err
+		}
+		if err := updateWorldsVersion(dir, packType, pack.manifest.Header.UUID, pack.manifest.Header.Version); err != nil {
+			return packs, err
+		}
+		packs = append(packs, &Pack{Manifest: pack.manifest, Type: packType, Dir: name})
+	}
+	return packs, nil
+}
+
+// Remove pack files and disable it in all worlds, packs required by other installed packs cannot be removed
+func Remove(dir, uuid string) error {
+	packsMu.Lock()
+	defer packsMu.Unlock()
+
+	installed, err := list(dir)
+	if err != nil {
+		return err
+	}
+	pack := Find(installed, uuid)
+	if pack == nil {
+		return ErrPackNotExists
+	}
+	if dependents := requiredBy(installed, pack.Header.UUID); len(dependents) > 0 {
+		return fmt.Errorf("%w: required by %s", ErrDependency, strings.Join(dependents, ", "))
+	}
+
+	worlds, err := worldsNames(dir)
+	if err != nil {
+		return err
+	}
+	for _, world := range worlds {
+		refs, err := readWorldPacks(dir, world, pack.Type)
+		if err != nil {
+			return err
+		}
+		if index := refs.index(pack.Header.UUID); index != -1 {
+			if err := writeWorldPacks(dir, world, pack.Type, slices.Delete(refs, index, index+1)); err != nil {
+				return err
+			}
+		}
+	}
+	return os.RemoveAll(filepath.Join(dir, filepath.FromSlash(pack.Dir)))
+}
+
+// Names of installed packs depending on uuid
+func requiredBy(packs []*Pack, uuid string) []string {
+	var names []string
+	for _, pack := range packs {
+		if slices.ContainsFunc(pack.Dependencies, func(dep Dependency) bool { return dep.UUID == uuid }) {
+			names = append(names, pack.Header.Name)
+		}
+	}
+	return names
+}
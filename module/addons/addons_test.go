@@ -0,0 +1,130 @@
+package addons
+
+import (
+	"archive/zip"
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const (
+	behaviorUUID = "8c5d2f3e-0000-4000-8000-000000000001"
+	resourceUUID = "8c5d2f3e-0000-4000-8000-000000000002"
+)
+
+func archive(files map[string][]byte) []byte {
+	var buff bytes.Buffer
+	zw := zip.NewWriter(&buff)
+	for name, data := range files {
+		w, _ := zw.Create(name)
+		w.Write(data)
+	}
+	zw.Close()
+	return buff.Bytes()
+}
+
+func manifest(uuid, moduleType, version, deps string) []byte {
+	return []byte(`{
+  // Made by hand
+  "format_version": 2,
+  "header": {"name": "pack ` + moduleType + `", "uuid": "` + uuid + `", "version": ` + version + `},
+  "modules": [{"type": "` + moduleType + `", "uuid": "` + strings.Repeat("0", 8) + uuid[8:] + `", "version": [1, 0, 0]}],
+  "dependencies": [` + deps + `]
+}`)
+}
+
+func install(dir string, data []byte) ([]*Pack, error) {
+	return Install(dir, bytes.NewReader(data), int64(len(data)))
+}
+
+func TestAddons(t *testing.T) {
+	dir := t.TempDir()
+	os.MkdirAll(filepath.Join(dir, "worlds", "Bedrock level"), 0755)
+
+	// Vanilla packs shipped with server are not managed
+	vanillaUUID := "8c5d2f3e-0000-4000-8000-0000000000ff"
+	os.MkdirAll(filepath.Join(dir, "behavior_packs", "vanilla"), 0755)
+	os.WriteFile(filepath.Join(dir, "behavior_packs", "vanilla", ManifestFile), manifest(vanillaUUID, "data", "[1, 0, 0]", ""), 0644)
+	if packs, err := List(dir); err != nil || len(packs) != 0 {
+		t.Errorf("vanilla pack listed: %d packs, %v", len(packs), err)
+		return
+	} else if err = Remove(dir, vanillaUUID); err != ErrPackNotExists {
+		t.Errorf("vanilla pack removed: %v", err)
+		return
+	}
+
+	// .mcaddon with behavior pack and nested resource .mcpack
+	resource := archive(map[string][]byte{"manifest.json": manifest(resourceUUID, "resources", `"1.0.0"`, "")})
+	addon := archive(map[string][]byte{
+		"behavior/manifest.json": manifest(behaviorUUID, "data", "[1, 0, 0]", `{"uuid": "`+resourceUUID+`", "version": [1, 0, 0]}, {"module_name": "@minecraft/server", "version": "1.8.0"}`),
+		"resource.mcpack":        resource,
+	})
+	packs, err := install(dir, addon)
+	if err != nil {
+		t.Errorf("cannot install addon: %s", err)
+		return
+	} else if len(packs) != 2 {
+		t.Errorf("invalid installed packs: %d", len(packs))
+		return
+	} else if _, err = install(dir, addon); !errors.Is(err, ErrPackExists) {
+		t.Errorf("same pack installed twice: %v", err)
+		return
+	} else if _, err = install(dir, archive(map[string][]byte{"manifest.json": manifest("../../../escaped", "data", `"1.0.0"`, "")})); !errors.Is(err, ErrInvalidManifest) {
+		t.Errorf("pack with invalid uuid installed: %v", err)
+		return
+	}
+
+	world, err := Enable(dir, "Bedrock level", behaviorUUID)
+	if err != nil {
+		t.Errorf("cannot enable pack: %s", err)
+		return
+	} else if len(world.Behavior) != 1 || len(world.Resource) != 1 || world.Resource[0].PackID != resourceUUID || len(world.Conflicts) != 0 {
+		t.Errorf("dependency not enabled: %+v", world)
+		return
+	} else if _, err = Disable(dir, "Bedrock level", resourceUUID); !errors.Is(err, ErrDependency) {
+		t.Errorf("required pack disabled: %v", err)
+		return
+	} else if err = Remove(dir, resourceUUID); !errors.Is(err, ErrDependency) {
+		t.Errorf("required pack removed: %v", err)
+		return
+	}
+
+	// Upgrade resource pack, world version is updated
+	if _, err = install(dir, archive(map[string][]byte{"manifest.json": manifest(resourceUUID, "resources", "[1, 1, 0]", "")})); err != nil {
+		t.Errorf("cannot upgrade pack: %s", err)
+		return
+	} else if world, _ = World(dir, "Bedrock level"); world.Resource[0].Version != (Version{1, 1, 0}) {
+		t.Errorf("world pack version not updated: %+v", world.Resource)
+		return
+	} else if packs, _ = List(dir); len(packs) != 2 || Find(packs, resourceUUID).Header.Version != (Version{1, 1, 0}) {
+		t.Errorf("old pack version not replaced: %d packs", len(packs))
+		return
+	}
+
+	// Missing dependency
+	missing := archive(map[string][]byte{"manifest.json": manifest("8c5d2f3e-0000-4000-8000-000000000003", "data", "[1, 0, 0]", `{"uuid": "8c5d2f3e-0000-4000-8000-000000000009", "version": [1, 0, 0]}`)})
+	var conflict *ConflictError
+	if _, err = install(dir, missing); err != nil {
+		t.Errorf("cannot install pack: %s", err)
+		return
+	} else if _, err = Enable(dir, "Bedrock level", "8c5d2f3e-0000-4000-8000-000000000003"); !errors.As(err, &conflict) || conflict.Conflicts[0].Reason != "dependency not installed" {
+		t.Errorf("missing dependency not detected: %v", err)
+		return
+	}
+
+	if _, err = Disable(dir, "Bedrock level", behaviorUUID); err != nil {
+		t.Errorf("cannot disable pack: %s", err)
+		return
+	} else if err = Remove(dir, behaviorUUID); err != nil {
+		t.Errorf("cannot remove pack: %s", err)
+		return
+	} else if err = Remove(dir, resourceUUID); err != nil {
+		t.Errorf("cannot remove pack: %s", err)
+		return
+	} else if world, _ = World(dir, "Bedrock level"); len(world.Resource) != 0 {
+		t.Errorf("removed pack still enabled: %+v", world.Resource)
+	}
+}
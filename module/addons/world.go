@@ -0,0 +1,319 @@
+package addons
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"sirherobrine23.com.br/go-bds/bds/module/worlds"
+)
+
+var ErrWorldNotExists error = errors.New("world not exists")
+
+// Pack enabled in world, first pack in list have highest priority
+type PackRef struct {
+	PackID  string  `json:"pack_id"`
+	Version Version `json:"version"`
+}
+
+// world_behavior_packs.json or world_resource_packs.json
+type WorldPacks []PackRef
+
+func (refs WorldPacks) index(uuid string) int {
+	return slices.IndexFunc(refs, func(ref PackRef) bool { return strings.EqualFold(ref.PackID, uuid) })
+}
+
+// Dependency problem in world
+type Conflict struct {
+	Pack       string     `json:"pack"`       // Pack UUID
+	Name       string     `json:"name"`       // Pack name
+	Dependency Dependency `json:"dependency"` // Dependency with problem
+	Installed  *Version   `json:"installed"`  // Installed dependency version, nil if not installed
+	Reason     string     `json:"reason"`
+}
+
+func (conflict Conflict) String() string {
+	return fmt.Sprintf("%s: %s %s %s", conflict.Name, conflict.Dependency.UUID, conflict.Dependency.Version, conflict.Reason)
+}
+
+// Error with world conflicts
+type ConflictError struct{ Conflicts []Conflict }
+
+func (err *ConflictError) Error() string {
+	var lines []string
+	for _, conflict := range err.Conflicts {
+		lines = append(lines, conflict.String())
+	}
+	return fmt.Sprintf("%s: %s", ErrDependency, strings.Join(lines, "; "))
+}
+
+func (err *ConflictError) Unwrap() error { return ErrDependency }
+
+// Worlds directories names in server
+func worldsNames(dir string) ([]string, error) {
+	entries, err := os.ReadDir(filepath.Join(dir, worlds.Dir))
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	var names []string
+	for _, entry := range entries {
+		if entry.IsDir() && worlds.ValidName(entry.Name()) {
+			names = append(names, entry.Name())
+		}
+	}
+	return names, nil
+}
+
+func worldDir(dir, world string) (string, error) {
+	if !worlds.ValidName(world) {
+		return "", ErrWorldNotExists
+	}
+	target := filepath.Join(dir, worlds.Dir, world)
+	if info, err := os.Stat(target); err != nil || !info.IsDir() {
+		return "", ErrWorldNotExists
+	}
+	return target, nil
+}
+
+func readWorldPacks(dir, world string, packType PackType) (WorldPacks, error) {
+	target, err := worldDir(dir, world)
+	if err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(filepath.Join(target, packType.WorldFile()))
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return WorldPacks{}, nil
+		}
+		return nil, err
+	}
+	refs := WorldPacks{}
+	if err := json.Unmarshal(stripComments(data), &refs); err != nil {
+		return nil, fmt.Errorf("invalid %s: %s", packType.WorldFile(), err)
+	}
+	return refs, nil
+}
+
+func writeWorldPacks(dir, world string, packType PackType, refs WorldPacks) error {
+	target, err := worldDir(dir, world)
+	if err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(refs, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(target, packType.WorldFile()), data, 0644)
+}
+
+// Update pack version in all worlds after pack upgrade
+func updateWorldsVersion(dir string, packType PackType, uuid string, version Version) error {
+	names, err := worldsNames(dir)
+	if err != nil {
+		return err
+	}
+	for _, world := range names {
+		refs, err := readWorldPacks(dir, world, packType)
+		if err != nil {
+			return err
+		}
+		if index := refs.index(uuid); index != -1 && refs[index].Version != version {
+			refs[index].Version = version
+			if err := writeWorldPacks(dir, world, packType, refs); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// Enabled packs in world
+type WorldAddons struct {
+	Behavior  WorldPacks `json:"behavior"`
+	Resource  WorldPacks `json:"resource"`
+	Conflicts []Conflict `json:"conflicts"` // Missing or incompatible dependencies
+}
+
+// Get world enabled packs and dependencies conflicts
+func World(dir, world string) (*WorldAddons, error) {
+	packsMu.Lock()
+	defer packsMu.Unlock()
+
+	installed, err := list(dir)
+	if err != nil {
+		return nil, err
+	}
+	addons := &WorldAddons{}
+	if addons.Behavior, err = readWorldPacks(dir, world, Behavior); err != nil {
+		return nil, err
+	} else if addons.Resource, err = readWorldPacks(dir, world, Resource); err != nil {
+		return nil, err
+	}
+	addons.Conflicts = conflicts(installed, append(slices.Clone(addons.Behavior), addons.Resource...))
+	return addons, nil
+}
+
+// Check dependencies of enabled packs: dependency must be installed, enabled, same major version and not older
+func conflicts(installed []*Pack, enabled WorldPacks) []Conflict {
+	found := []Conflict{}
+	for _, ref := range enabled {
+		pack := Find(installed, ref.PackID)
+		if pack == nil {
+			found = append(found, Conflict{Pack: ref.PackID, Name: ref.PackID, Reason: "pack enabled but not installed"})
+			continue
+		}
+		for _, dep := range pack.Dependencies {
+			if dep.UUID == "" {
+				continue // Script modules are from server
+			}
+			conflict := Conflict{Pack: pack.Header.UUID, Name: pack.Header.Name, Dependency: dep}
+			depPack := Find(installed, dep.UUID)
+			if depPack == nil {
+				conflict.Reason = "dependency not installed"
+				found = append(found, conflict)
+				continue
+			}
+			conflict.Installed = &depPack.Header.Version
+			switch {
+			case depPack.Header.Version[0] != dep.Version[0]:
+				conflict.Reason = "dependency major version incompatible"
+			case depPack.Header.Version.Compare(dep.Version) < 0:
+				conflict.Reason = "dependency version older than required"
+			case enabled.index(dep.UUID) == -1:
+				conflict.Reason = "dependency not enabled in world"
+			default:
+				continue
+			}
+			found = append(found, conflict)
+		}
+	}
+	return found
+}
+
+// Enable pack in world with highest priority, dependencies not enabled are enabled after pack.
+// Pack is not enabled if dependencies have conflicts.
+func Enable(dir, world, uuid string) (*WorldAddons, error) {
+	packsMu.Lock()
+	defer packsMu.Unlock()
+
+	installed, err := list(dir)
+	if err != nil {
+		return nil, err
+	}
+	pack := Find(installed, uuid)
+	if pack == nil {
+		return nil, ErrPackNotExists
+	}
+
+	refs := map[PackType]WorldPacks{}
+	for _, packType := range []PackType{Behavior, Resource} {
+		if refs[packType], err = readWorldPacks(dir, world, packType); err != nil {
+			return nil, err
+		}
+	}
+
+	// Pack and dependencies tree, dependencies after dependents
+	var order []*Pack
+	var walk func(pack *Pack)
+	walk = func(pack *Pack) {
+		if slices.Contains(order, pack) {
+			return
+		}
+		order = append(order, pack)
+		for _, dep := range pack.Dependencies {
+			if depPack := Find(installed, dep.UUID); dep.UUID != "" && depPack != nil {
+				walk(depPack)
+			}
+		}
+	}
+	walk(pack)
+
+	insert := map[PackType]WorldPacks{}
+	for _, item := range order {
+		if index := refs[item.Type].index(item.Header.UUID); index != -1 {
+			if item != pack {
+				continue // Keep dependency priority
+			}
+			refs[item.Type] = slices.Delete(refs[item.Type], index, index+1)
+		}
+		insert[item.Type] = append(insert[item.Type], PackRef{PackID: item.Header.UUID, Version: item.Header.Version})
+	}
+	for packType, items := range insert {
+		refs[packType] = append(items, refs[packType]...)
+	}
+
+	enabled := append(slices.Clone(refs[Behavior]), refs[Resource]...)
+	if found := conflicts(installed, enabled); len(found) > 0 {
+		// Only conflicts from new packs block enable
+		var blocking []Conflict
+		for _, conflict := range found {
+			if slices.ContainsFunc(order, func(item *Pack) bool { return item.Header.UUID == conflict.Pack }) {
+				blocking = append(blocking, conflict)
+			}
+		}
+		if len(blocking) > 0 {
+			return nil, &ConflictError{blocking}
+		}
+	}
+
+	for _, packType := range []PackType{Behavior, Resource} {
+		if err := writeWorldPacks(dir, world, packType, refs[packType]); err != nil {
+			return nil, err
+		}
+	}
+	return &WorldAddons{Behavior: refs[Behavior], Resource: refs[Resource], Conflicts: conflicts(installed, enabled)}, nil
+}
+
+// Disable pack in world, packs enabled depending on it block disable
+func Disable(dir, world, uuid string) (*WorldAddons, error) {
+	packsMu.Lock()
+	defer packsMu.Unlock()
+
+	installed, err := list(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	refs := map[PackType]WorldPacks{}
+	for _, packType := range []PackType{Behavior, Resource} {
+		if refs[packType], err = readWorldPacks(dir, world, packType); err != nil {
+			return nil, err
+		}
+	}
+
+	uuid, disabled := strings.ToLower(uuid), false
+	for _, packType := range []PackType{Behavior, Resource} {
+		if index := refs[packType].index(uuid); index != -1 {
+			refs[packType], disabled = slices.Delete(refs[packType], index, index+1), true
+		}
+	}
+	if !disabled {
+		return nil, ErrPackNotExists
+	}
+
+	enabled := append(slices.Clone(refs[Behavior]), refs[Resource]...)
+	var dependents []string
+	for _, ref := range enabled {
+		if pack := Find(installed, ref.PackID); pack != nil && slices.ContainsFunc(pack.Dependencies, func(dep Dependency) bool { return dep.UUID == uuid }) {
+			dependents = append(dependents, pack.Header.Name)
+		}
+	}
+	if len(dependents) > 0 {
+		return nil, fmt.Errorf("%w: required by %s", ErrDependency, strings.Join(dependents, ", "))
+	}
+
+	for _, packType := range []PackType{Behavior, Resource} {
+		if err := writeWorldPacks(dir, world, packType, refs[packType]); err != nil {
+			return nil, err
+		}
+	}
+	return &WorldAddons{Behavior: refs[Behavior], Resource: refs[Resource], Conflicts: conflicts(installed, enabled)}, nil
+}
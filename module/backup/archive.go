@@ -0,0 +1,127 @@
+package backup
+
+import (
+	"archive/zip"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"sirherobrine23.com.br/go-bds/bds/module/properties"
+)
+
+// Config files included in all backups
+var (
+	BedrockFiles = []string{properties.FileName, "allowlist.json", "permissions.json", "config"}
+	JavaFiles    = []string{properties.FileName, "whitelist.json", "ops.json", "banned-players.json", "banned-ips.json", "bukkit.yml", "spigot.yml", "config"}
+)
+
+// File to add in archive
+type File struct {
+	Path string // Path relative to server directory
+	Size int64  // Bytes to copy, if < 0 copy all file
+}
+
+// Return paths relative to dir to include in backup, only existing paths
+func Paths(software, dir string) []string {
+	var paths []string
+	if strings.EqualFold(software, "bedrock") {
+		paths = append([]string{"worlds"}, BedrockFiles...)
+	} else {
+		level := "world"
+		if file, err := properties.Open(filepath.Join(dir, properties.FileName)); err == nil {
+			if name, ok := file.Get("level-name"); ok && name != "" && properties.ValidLevel(software, name) {
+				level = name
+			}
+		}
+		if level == "worlds" || strings.HasPrefix(level, "worlds/") {
+			paths = append([]string{"worlds"}, JavaFiles...)
+		} else {
+			// Other worlds are kept in worlds directory
+			paths = append([]string{level, level + "_nether", level + "_the_end", "worlds"}, JavaFiles...)
+		}
+	}
+
+	return existing(dir, paths)
+}
+
+// Filter paths exists in dir
+func existing(dir string, paths []string) []string {
+	var exists []string
+	for _, path := range paths {
+		if _, err := os.Lstat(filepath.Join(dir, path)); err == nil {
+			exists = append(exists, path)
+		}
+	}
+	return exists
+}
+
+// List regular files in paths relative to dir, directories are walked recursively and symlinks ignored
+func Walk(dir string, paths []string) ([]File, error) {
+	var files []File
+	for _, root := range paths {
+		err := filepath.WalkDir(filepath.Join(dir, root), func(path string, entry fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			} else if !entry.Type().IsRegular() {
+				return nil // Skip directories, symlinks and special files
+			}
+
+			name, err := filepath.Rel(dir, path)
+			if err != nil {
+				return err
+			}
+			files = append(files, File{Path: filepath.ToSlash(name), Size: -1})
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	return files, nil
+}
+
+// Write zip archive with files from dir
+func Archive(w io.Writer, dir string, files []File) error {
+	zw := zip.NewWriter(w)
+	for _, file := range files {
+		if err := addFile(zw, dir, file); err != nil {
+			zw.Close()
+			return err
+		}
+	}
+	return zw.Close()
+}
+
+// Add file to zip, copy only file.Size bytes if file.Size >= 0
+func addFile(zw *zip.Writer, dir string, file File) error {
+	osFile, err := os.Open(filepath.Join(dir, filepath.FromSlash(file.Path)))
+	if err != nil {
+		return err
+	}
+	defer osFile.Close()
+
+	info, err := osFile.Stat()
+	if err != nil {
+		return err
+	}
+
+	header, err := zip.FileInfoHeader(info)
+	if err != nil {
+		return err
+	}
+	header.Name, header.Method = file.Path, zip.Deflate
+
+	fw, err := zw.CreateHeader(header)
+	if err != nil {
+		return err
+	}
+
+	var r io.Reader = osFile
+	if file.Size >= 0 {
+		r = io.LimitReader(osFile, file.Size)
+	}
+	_, err = io.Copy(fw, r)
+	return err
+}
@@ -0,0 +1,283 @@
+// Create, list and remove servers backups
+package backup
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"maps"
+	"slices"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"sirherobrine23.com.br/go-bds/bds/module/backup/storage"
+	"sirherobrine23.com.br/go-bds/bds/module/db"
+	"sirherobrine23.com.br/go-bds/bds/module/encrypt"
+	"sirherobrine23.com.br/go-bds/bds/module/runner"
+	"sirherobrine23.com.br/go-bds/bds/module/server"
+)
+
+// Name of local storage, always registered by [NewManager]
+const LocalStorage = "local"
+
+var (
+	ErrBackupRunning    error = errors.New("backup already running to server")
+	ErrNoFiles          error = errors.New("server not have files to backup")
+	ErrStorageNotExists error = errors.New("backup storage not exists")
+	ErrEncryptKey       error = errors.New("backup is encrypted and encrypt key is not set")
+)
+
+// Backups maneger
+type Manager struct {
+	Root           string                     // Directory to storage backups archives in local storage
+	Database       db.Database                // Database to record backups
+	Runner         *runner.Manager            // Servers runner, used to find servers files and running servers
+	SaveTimeout    time.Duration              // Max time to wait running server save, if <= 0 use [DefaultSaveTimeout]
+	Storages       map[string]storage.Storage // Storage backends by name
+	DefaultStorage string                     // Storage to servers without storage config
+	EncryptKey     string                     // Instance key to encrypt new backups before storage, empty to not encrypt
+
+	mu       sync.Mutex
+	running  map[int64]bool
+	chunksMu sync.Mutex // Lock chunks references
+}
+
+// Create new backup maneger with local storage in root
+func NewManager(root string, database db.Database, manager *runner.Manager) *Manager {
+	return &Manager{
+		Root:           root,
+		Database:       database,
+		Runner:         manager,
+		Storages:       map[string]storage.Storage{LocalStorage: storage.NewLocal(root)},
+		DefaultStorage: LocalStorage,
+		running:        map[int64]bool{},
+	}
+}
+
+// Archive or manifest name to backup in storage
+func (mg *Manager) Name(backup *server.ServerBackup) string {
+	name := strconv.FormatInt(backup.ServerID, 10) + "/" + backup.UUID + ".zip"
+	if backup.Incremental {
+		name = strconv.FormatInt(backup.ServerID, 10) + "/" + backup.UUID + ".json"
+	}
+	if backup.Encrypted {
+		name += ".enc"
+	}
+	return name
+}
+
+// Get storage by name, empty name is local storage
+func (mg *Manager) Storage(name string) (storage.Storage, error) {
+	if name == "" {
+		name = LocalStorage
+	}
+	if store, ok := mg.Storages[name]; ok {
+		return store, nil
+	}
+	return nil, ErrStorageNotExists
+}
+
+// Storages names sorted
+func (mg *Manager) StorageNames() []string {
+	return slices.Sorted(maps.Keys(mg.Storages))
+}
+
+// Get storage name to save server new backups, server config or global default
+func (mg *Manager) ServerStorage(serverID int64) (string, error) {
+	config, err := mg.Database.BackupConfig(serverID)
+	if err != nil {
+		return "", err
+	} else if config.Storage != "" {
+		return config.Storage, nil
+	} else if mg.DefaultStorage != "" {
+		return mg.DefaultStorage, nil
+	}
+	return LocalStorage, nil
+}
+
+// Lock server to only one backup at time
+func (mg *Manager) lock(serverID int64) error {
+	mg.mu.Lock()
+	defer mg.mu.Unlock()
+	if mg.running[serverID] {
+		return ErrBackupRunning
+	}
+	mg.running[serverID] = true
+	return nil
+}
+
+func (mg *Manager) unlock(serverID int64) {
+	mg.mu.Lock()
+	defer mg.mu.Unlock()
+	delete(mg.running, serverID)
+}
+
+// Archive server world and config and record backup in database.
+//
+// Running servers are archived with save hold/query/resume on Bedrock and save-off/save-all/save-on on Java
+func (mg *Manager) Create(srv *server.Server) (*server.ServerBackup, error) {
+	if err := mg.lock(srv.ID); err != nil {
+		return nil, err
+	}
+	defer mg.unlock(srv.ID)
+	return mg.create(srv, false)
+}
+
+// Create safety backup before change server files, safety backups are not removed by retention policy
+func (mg *Manager) CreateSafety(srv *server.Server) (*server.ServerBackup, error) {
+	if err := mg.lock(srv.ID); err != nil {
+		return nil, err
+	}
+	defer mg.unlock(srv.ID)
+	return mg.create(srv, true)
+}
+
+func (mg *Manager) create(srv *server.Server, safety bool) (*server.ServerBackup, error) {
+	dir := mg.Runner.Dir(srv.ID)
+	paths := Paths(srv.Software, dir)
+	if len(paths) == 0 {
+		return nil, ErrNoFiles
+	}
+
+	config, err := mg.Database.BackupConfig(srv.ID)
+	if err != nil {
+		return nil, fmt.Errorf("cannot get backup config: %s", err)
+	}
+	storageName, err := mg.ServerStorage(srv.ID)
+	if err != nil {
+		return nil, fmt.Errorf("cannot get backup config: %s", err)
+	}
+	store, err := mg.Storage(storageName)
+	if err != nil {
+		return nil, err
+	}
+
+	backup := &server.ServerBackup{
+		ServerID:    srv.ID,
+		UUID:        uuid.NewString(),
+		Software:    srv.Software,
+		Version:     srv.Version,
+		Storage:     storageName,
+		Encrypted:   mg.EncryptKey != "",
+		Incremental: config.Incremental,
+		Safety:      safety,
+	}
+
+	// Files from running server or from disk
+	withFiles := func(fn func(files []File) error) error {
+		if proc := mg.Runner.Process(srv.ID); proc != nil {
+			return mg.hotFiles(proc, dir, fn)
+		}
+		files, err := Walk(dir, paths)
+		if err != nil {
+			return err
+		}
+		return fn(files)
+	}
+	if backup.Incremental {
+		return mg.createIncremental(store, backup, dir, withFiles)
+	}
+
+	// Stream archive to storage, zip is never fully in memory
+	pr, pw := io.Pipe()
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		var w io.Writer = pw
+		var encrypter *encrypt.Writer
+		if backup.Encrypted {
+			var err error
+			if encrypter, err = encrypt.NewWriter(pw, mg.EncryptKey); err != nil {
+				pw.CloseWithError(fmt.Errorf("cannot encrypt backup: %s", err))
+				return
+			}
+			w = encrypter
+		}
+
+		err := withFiles(func(files []File) error { return Archive(w, dir, files) })
+		if err != nil {
+			err = fmt.Errorf("cannot archive server files: %s", err)
+		} else if encrypter != nil {
+			err = encrypter.Close()
+		}
+		pw.CloseWithError(err)
+	}()
+
+	err = store.Put(mg.Name(backup), pr)
+	pr.CloseWithError(err)
+	<-done
+	if err != nil {
+		return nil, err
+	}
+
+	created, err := mg.Database.CreateBackup(backup)
+	if err != nil {
+		store.Delete(mg.Name(backup))
+		return nil, err
+	}
+	return created, nil
+}
+
+// Open backup archive from storage, encrypted archives are decrypted on read
+func (mg *Manager) Open(backup *server.ServerBackup) (storage.File, error) {
+	store, err := mg.Storage(backup.Storage)
+	if err != nil {
+		return nil, err
+	} else if backup.Encrypted && mg.EncryptKey == "" {
+		return nil, ErrEncryptKey
+	} else if backup.Incremental {
+		return mg.openIncremental(store, backup)
+	}
+
+	file, err := store.Open(mg.Name(backup))
+	if err != nil || !backup.Encrypted {
+		return file, err
+	}
+
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return nil, err
+	}
+	decrypter, err := encrypt.OpenFile(file, info.Size(), mg.EncryptKey)
+	if err != nil {
+		file.Close()
+		return nil, fmt.Errorf("cannot decrypt backup: %s", err)
+	}
+	return &decryptedFile{File: decrypter, source: file, info: info}, nil
+}
+
+// Encrypted archive opened to read plaintext
+type decryptedFile struct {
+	*encrypt.File
+	source storage.File
+	info   fs.FileInfo
+}
+
+type decryptedInfo struct {
+	fs.FileInfo
+	size int64
+}
+
+func (info decryptedInfo) Size() int64 { return info.size }
+
+func (file *decryptedFile) Close() error { return file.source.Close() }
+func (file *decryptedFile) Stat() (fs.FileInfo, error) {
+	return decryptedInfo{file.info, file.File.Size()}, nil
+}
+
+// Remove backup archive and database row
+func (mg *Manager) Delete(backup *server.ServerBackup) error {
+	store, err := mg.Storage(backup.Storage)
+	if err != nil {
+		return err
+	} else if backup.Incremental {
+		return mg.deleteIncremental(store, backup)
+	} else if err = store.Delete(mg.Name(backup)); err != nil {
+		return err
+	}
+	return mg.Database.DeleteBackup(backup)
+}
@@ -0,0 +1,231 @@
+package backup
+
+import (
+	"archive/zip"
+	"bytes"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"sirherobrine23.com.br/go-bds/bds/module/backup/storage"
+	"sirherobrine23.com.br/go-bds/bds/module/db"
+	"sirherobrine23.com.br/go-bds/bds/module/runner"
+	"sirherobrine23.com.br/go-bds/bds/module/server"
+	"sirherobrine23.com.br/go-bds/bds/module/users"
+)
+
+// Create database with bedrock server and fake server files
+func testManager(t *testing.T, command runner.CommandBuilder) (*Manager, *server.Server, error) {
+	database, err := db.NewSqliteConnection(":memory:")
+	if err != nil {
+		return nil, nil, err
+	}
+	user, err := database.CreateNewUser(&users.User{Username: "backup"}, &users.Password{Password: "test1234"})
+	if err != nil {
+		return nil, nil, fmt.Errorf("cannot make new user in database: %s", err)
+	}
+	mcServer, err := database.CreateServer(user, &server.Server{Software: "bedrock", Version: "1.21.2.02", Owner: user.UserID})
+	if err != nil {
+		return nil, nil, fmt.Errorf("cannot make new server in database: %s", err)
+	}
+
+	root := t.TempDir()
+	manager := NewManager(filepath.Join(root, "backups"), database, runner.NewManager(filepath.Join(root, "servers"), command))
+	dir := manager.Runner.Dir(mcServer.ID)
+	os.MkdirAll(filepath.Join(dir, "worlds", "Bedrock level", "db"), 0755)
+	os.WriteFile(filepath.Join(dir, "worlds", "Bedrock level", "db", "CURRENT"), []byte("MANIFEST-000001\n"), 0644)
+	os.WriteFile(filepath.Join(dir, "worlds", "Bedrock level", "db", "000005.ldb"), bytes.Repeat([]byte{1}, 100), 0644)
+	os.WriteFile(filepath.Join(dir, "server.properties"), []byte("level-name=Bedrock level\n"), 0644)
+	os.WriteFile(filepath.Join(dir, "bedrock_server"), []byte("binary"), 0755)
+	return manager, mcServer, nil
+}
+
+func TestBackup(t *testing.T) {
+	manager, mcServer, err := testManager(t, nil)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	database := manager.Database
+
+	mcBackup, err := manager.Create(mcServer)
+	if err != nil {
+		t.Errorf("cannot create backup: %s", err)
+		return
+	} else if mcBackup.UUID == "" || mcBackup.Version != "1.21.2.02" {
+		t.Errorf("invalid backup: %+v", mcBackup)
+		return
+	}
+
+	zr, err := zip.OpenReader(filepath.Join(manager.Root, manager.Name(mcBackup)))
+	if err != nil {
+		t.Errorf("cannot open archive: %s", err)
+		return
+	}
+	var names []string
+	for _, file := range zr.File {
+		names = append(names, file.Name)
+	}
+	zr.Close()
+	if !slices.Contains(names, "worlds/Bedrock level/db/CURRENT") || !slices.Contains(names, "server.properties") || slices.Contains(names, "bedrock_server") {
+		t.Errorf("invalid archive files: %v", names)
+		return
+	}
+
+	if err = manager.Delete(mcBackup); err != nil {
+		t.Errorf("cannot delete backup: %s", err)
+		return
+	}
+	if backups, _ := database.ServerBackups(mcServer.ID); len(backups) != 0 {
+		t.Errorf("backup not removed from database")
+		return
+	}
+
+	// Server storage config
+	remoteRoot := t.TempDir()
+	manager.Storages["remote"] = storage.NewLocal(remoteRoot)
+	if err = database.SetBackupConfig(&server.BackupConfig{ServerID: mcServer.ID, Storage: "remote"}); err != nil {
+		t.Errorf("cannot set backup config: %s", err)
+		return
+	} else if mcBackup, err = manager.Create(mcServer); err != nil {
+		t.Errorf("cannot create backup in remote storage: %s", err)
+		return
+	} else if mcBackup.Storage != "remote" {
+		t.Errorf("backup saved in %q storage", mcBackup.Storage)
+		return
+	} else if _, err = os.Stat(filepath.Join(remoteRoot, manager.Name(mcBackup))); err != nil {
+		t.Errorf("backup not saved in remote storage: %s", err)
+	}
+}
+
+// Fake bedrock_server responding save commands
+const fakeBedrock = `
+queries=0
+echo "[2024-06-12 10:00:00:000 INFO] Server started."
+while read -r line; do
+  case "$line" in
+    "save hold") echo "[2024-06-12 10:00:00:000 INFO] Saving..." ;;
+    "save query")
+      queries=$((queries+1))
+      if [ $queries -lt 2 ]; then
+        echo "[2024-06-12 10:00:00:000 INFO] A previous save has not been completed."
+      else
+        echo "[2024-06-12 10:00:00:000 INFO] Data saved. Files are now ready to be copied."
+        echo "[2024-06-12 10:00:00:000 INFO] Player connected: Steve, xuid: 2535412345678901"
+        echo "Bedrock level/db/000005.ldb:40, Bedrock level/db/CURRENT:16"
+      fi ;;
+    "save resume") echo "[2024-06-12 10:00:00:000 INFO] Changes to the level are resumed." ;;
+    "stop") exit 0 ;;
+  esac
+done
+`
+
+func TestHotBackup(t *testing.T) {
+	manager, mcServer, err := testManager(t, func(srv *server.Server, dir string) (*exec.Cmd, error) {
+		return exec.Command("sh", "-c", fakeBedrock), nil
+	})
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	SaveQueryInterval = time.Millisecond * 10
+
+	proc, err := manager.Runner.Start(mcServer)
+	if err != nil {
+		t.Errorf("cannot start fake server: %s", err)
+		return
+	}
+	defer proc.Kill()
+
+	mcBackup, err := manager.Create(mcServer)
+	if err != nil {
+		t.Errorf("cannot create hot backup: %s", err)
+		return
+	}
+
+	zr, err := zip.OpenReader(filepath.Join(manager.Root, manager.Name(mcBackup)))
+	if err != nil {
+		t.Errorf("cannot open archive: %s", err)
+		return
+	}
+	defer zr.Close()
+	for _, file := range zr.File {
+		if file.Name == "worlds/Bedrock level/db/000005.ldb" && file.UncompressedSize64 != 40 {
+			t.Errorf("file not truncated to save query length: %d", file.UncompressedSize64)
+			return
+		}
+	}
+
+	if !slices.ContainsFunc(proc.Output.Scrollback(), func(line runner.Line) bool {
+		return line.Stream == runner.Stdin && strings.TrimSpace(line.Text) == "save resume"
+	}) {
+		t.Errorf("save resume not sent")
+	}
+}
+
+func TestEncryptedBackup(t *testing.T) {
+	manager, mcServer, err := testManager(t, nil)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	manager.EncryptKey = "golang_test"
+
+	mcBackup, err := manager.Create(mcServer)
+	if err != nil {
+		t.Errorf("cannot create backup: %s", err)
+		return
+	} else if !mcBackup.Encrypted {
+		t.Errorf("backup not marked as encrypted")
+		return
+	}
+
+	raw, err := os.ReadFile(filepath.Join(manager.Root, manager.Name(mcBackup)))
+	if err != nil {
+		t.Errorf("cannot read raw archive: %s", err)
+		return
+	} else if bytes.HasPrefix(raw, []byte("PK")) || bytes.Contains(raw, []byte("Bedrock level")) {
+		t.Errorf("archive saved without encryption")
+		return
+	}
+
+	file, err := manager.Open(mcBackup)
+	if err != nil {
+		t.Errorf("cannot open encrypted backup: %s", err)
+		return
+	}
+	defer file.Close()
+	info, _ := file.Stat()
+	zr, err := zip.NewReader(file, info.Size())
+	if err != nil {
+		t.Errorf("cannot read decrypted archive: %s", err)
+		return
+	} else if len(zr.File) == 0 {
+		t.Errorf("decrypted archive is empty")
+		return
+	}
+
+	manager.EncryptKey = ""
+	if _, err = manager.Open(mcBackup); err != ErrEncryptKey {
+		t.Errorf("encrypted backup opened without key: %v", err)
+	}
+}
+
+func TestParseSaveQuery(t *testing.T) {
+	files, err := parseSaveQuery("Hello, world/db/000005.ldb:1234, Hello, world/level.dat:2548")
+	if err != nil {
+		t.Errorf("cannot parse save query: %s", err)
+		return
+	} else if len(files) != 2 || files[0].Path != "worlds/Hello, world/db/000005.ldb" || files[0].Size != 1234 || files[1].Path != "worlds/Hello, world/level.dat" || files[1].Size != 2548 {
+		t.Errorf("invalid files: %+v", files)
+		return
+	}
+	if _, err = parseSaveQuery("Hello, world/level.dat"); err == nil {
+		t.Errorf("entry without length accepted")
+	}
+}
@@ -0,0 +1,88 @@
+package backup
+
+import "io"
+
+// Content-defined chunking sizes, inserted or removed bytes only change near chunks
+const (
+	MinChunkSize = 64 << 10  // Chunks are never smaller, except last chunk
+	AvgChunkSize = 256 << 10 // Expected chunk size
+	MaxChunkSize = 1 << 20   // Chunks are never bigger
+
+	// Normalized chunking masks, harder before avg size and easier after, high bits depends on last 64 bytes
+	chunkMaskHard uint64 = (1<<20 - 1) << (64 - 20)
+	chunkMaskEasy uint64 = (1<<16 - 1) << (64 - 16)
+)
+
+// Gear table, generated with fixed seed so chunks boundaries never change between versions
+var gearTable = func() (table [256]uint64) {
+	seed := uint64(0x62647320676f2121) // splitmix64
+	for index := range table {
+		seed += 0x9e3779b97f4a7c15
+		value := seed
+		value = (value ^ (value >> 30)) * 0xbf58476d1ce4e5b9
+		value = (value ^ (value >> 27)) * 0x94d049bb133111eb
+		table[index] = value ^ (value >> 31)
+	}
+	return
+}()
+
+// Find cut point in data with gear hash, data is limited to [MaxChunkSize]
+func cutPoint(data []byte) int {
+	size := len(data)
+	if size <= MinChunkSize {
+		return size
+	}
+
+	var hash uint64
+	index, normal := MinChunkSize, min(size, AvgChunkSize)
+	for ; index < normal; index++ {
+		if hash = (hash << 1) + gearTable[data[index]]; hash&chunkMaskHard == 0 {
+			return index + 1
+		}
+	}
+	for ; index < size; index++ {
+		if hash = (hash << 1) + gearTable[data[index]]; hash&chunkMaskEasy == 0 {
+			return index + 1
+		}
+	}
+	return size
+}
+
+// Split reader in content-defined chunks
+type Chunker struct {
+	r     io.Reader
+	buff  []byte
+	start int // Start of data not returned
+	end   int // End of data in buff
+	eof   bool
+}
+
+// Create new chunker to reader
+func NewChunker(r io.Reader) *Chunker {
+	return &Chunker{r: r, buff: make([]byte, MaxChunkSize*2)}
+}
+
+// Return next chunk, chunk is valid only until next call. Return io.EOF after last chunk
+func (chunker *Chunker) Next() ([]byte, error) {
+	// Fill buffer to have at least max chunk size
+	if chunker.end-chunker.start < MaxChunkSize && !chunker.eof {
+		chunker.end = copy(chunker.buff, chunker.buff[chunker.start:chunker.end])
+		chunker.start = 0
+		n, err := io.ReadFull(chunker.r, chunker.buff[chunker.end:])
+		chunker.end += n
+		if err == io.EOF || err == io.ErrUnexpectedEOF {
+			chunker.eof = true
+		} else if err != nil {
+			return nil, err
+		}
+	}
+
+	if chunker.start == chunker.end {
+		return nil, io.EOF
+	}
+
+	data := chunker.buff[chunker.start:min(chunker.end, chunker.start+MaxChunkSize)]
+	chunk := data[:cutPoint(data)]
+	chunker.start += len(chunk)
+	return chunk, nil
+}
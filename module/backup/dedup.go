@@ -0,0 +1,498 @@
+package backup
+
+import (
+	"archive/zip"
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"time"
+
+	"sirherobrine23.com.br/go-bds/bds/module/backup/storage"
+	"sirherobrine23.com.br/go-bds/bds/module/db"
+	"sirherobrine23.com.br/go-bds/bds/module/encrypt"
+	"sirherobrine23.com.br/go-bds/bds/module/server"
+)
+
+// Incremental backup manifest version
+const ManifestVersion = 1
+
+var ErrChunkCorrupted error = errors.New("backup chunk content not match chunk ID")
+
+// Files in incremental backup
+type Manifest struct {
+	Version int            `json:"version"`
+	Files   []ManifestFile `json:"files"`
+}
+
+// File splited in chunks
+type ManifestFile struct {
+	Path    string      `json:"path"`     // Path relative to server directory
+	Mode    fs.FileMode `json:"mode"`     // File mode
+	ModTime time.Time   `json:"mod_time"` // Last modification
+	Size    int64       `json:"size"`     // File size
+	Chunks  []string    `json:"chunks"`   // Chunks IDs in order
+}
+
+// Chunks storage verification result
+type FsckReport struct {
+	Backups   int      `json:"backups"`   // Backups checked
+	Chunks    int      `json:"chunks"`    // Unique chunks checked
+	Missing   []string `json:"missing"`   // Chunks or archives not found in storage
+	Corrupted []string `json:"corrupted"` // Chunks with content not matching ID
+	Damaged   []int64  `json:"damaged"`   // Backups cannot be restored
+}
+
+// Chunk path in storage, split in directories to not make big directory
+func chunkName(id string) string {
+	return "chunks/" + id[:2] + "/" + id
+}
+
+// Chunk ID is sha256 of content, encrypted chunks use HMAC with encrypt key to not leak content hash
+func (mg *Manager) chunkID(data []byte, encrypted bool) string {
+	if encrypted {
+		hash := hmac.New(sha256.New, []byte(mg.EncryptKey))
+		hash.Write(data)
+		return hex.EncodeToString(hash.Sum(nil))
+	}
+	hash := sha256.Sum256(data)
+	return hex.EncodeToString(hash[:])
+}
+
+// Write data to storage, encrypted if backup is encrypted, return size in storage
+func (mg *Manager) putBlob(store storage.Storage, name string, data []byte, encrypted bool) (int64, error) {
+	if encrypted {
+		var buff bytes.Buffer
+		encrypter, err := encrypt.NewWriter(&buff, mg.EncryptKey)
+		if err != nil {
+			return 0, err
+		} else if _, err = encrypter.Write(data); err != nil {
+			return 0, err
+		} else if err = encrypter.Close(); err != nil {
+			return 0, err
+		}
+		data = buff.Bytes()
+	}
+	return int64(len(data)), store.Put(name, bytes.NewReader(data))
+}
+
+// Read data from storage, decrypt if backup is encrypted
+func (mg *Manager) getBlob(store storage.Storage, name string, encrypted bool) ([]byte, error) {
+	if encrypted && mg.EncryptKey == "" {
+		return nil, ErrEncryptKey
+	}
+
+	file, err := store.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	var r io.Reader = file
+	if encrypted {
+		if r, err = encrypt.NewReader(file, mg.EncryptKey); err != nil {
+			return nil, err
+		}
+	}
+	return io.ReadAll(r)
+}
+
+// Read chunk and check content
+func (mg *Manager) readChunk(store storage.Storage, id string, encrypted bool) ([]byte, error) {
+	data, err := mg.getBlob(store, chunkName(id), encrypted)
+	if err != nil {
+		return nil, err
+	} else if mg.chunkID(data, encrypted) != id {
+		return nil, ErrChunkCorrupted
+	}
+	return data, nil
+}
+
+// Split files in chunks and upload only chunks not in store.
+//
+// Return manifest, chunks used by backup and chunks uploaded, caller must hold chunks lock
+func (mg *Manager) writeChunks(store storage.Storage, backup *server.ServerBackup, dir string, files []File) (*Manifest, []*server.BackupChunk, []string, error) {
+	manifest := &Manifest{Version: ManifestVersion, Files: []ManifestFile{}}
+	used, uploaded := map[string]*server.BackupChunk{}, []string{}
+	for _, file := range files {
+		manifestFile, err := mg.writeFileChunks(store, backup, dir, file, used, &uploaded)
+		if err != nil {
+			return nil, nil, uploaded, fmt.Errorf("cannot backup %s: %s", file.Path, err)
+		}
+		manifest.Files = append(manifest.Files, *manifestFile)
+	}
+
+	chunks := make([]*server.BackupChunk, 0, len(used))
+	for _, chunk := range used {
+		chunks = append(chunks, chunk)
+	}
+	return manifest, chunks, uploaded, nil
+}
+
+func (mg *Manager) writeFileChunks(store storage.Storage, backup *server.ServerBackup, dir string, file File, used map[string]*server.BackupChunk, uploaded *[]string) (*ManifestFile, error) {
+	osFile, err := os.Open(filepath.Join(dir, filepath.FromSlash(file.Path)))
+	if err != nil {
+		return nil, err
+	}
+	defer osFile.Close()
+
+	info, err := osFile.Stat()
+	if err != nil {
+		return nil, err
+	}
+
+	var r io.Reader = osFile
+	if file.Size >= 0 {
+		r = io.LimitReader(osFile, file.Size)
+	}
+
+	manifestFile := &ManifestFile{Path: file.Path, Mode: info.Mode(), ModTime: info.ModTime(), Chunks: []string{}}
+	chunker := NewChunker(r)
+	for {
+		data, err := chunker.Next()
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			return nil, err
+		}
+
+		id := mg.chunkID(data, backup.Encrypted)
+		manifestFile.Size += int64(len(data))
+		manifestFile.Chunks = append(manifestFile.Chunks, id)
+		if _, ok := used[id]; ok {
+			continue
+		}
+
+		chunk, err := mg.Database.BackupChunk(backup.Storage, id)
+		if err == db.ErrChunkNotExists {
+			chunk = &server.BackupChunk{Storage: backup.Storage, Hash: id}
+			if chunk.Size, err = mg.putBlob(store, chunkName(id), data, backup.Encrypted); err != nil {
+				return nil, err
+			}
+			*uploaded = append(*uploaded, id)
+		} else if err != nil {
+			return nil, err
+		}
+		used[id] = chunk
+	}
+	return manifestFile, nil
+}
+
+// Create incremental backup, only chunks not in store are uploaded
+func (mg *Manager) createIncremental(store storage.Storage, backup *server.ServerBackup, dir string, withFiles func(fn func(files []File) error) error) (*server.ServerBackup, error) {
+	mg.chunksMu.Lock()
+	defer mg.chunksMu.Unlock()
+
+	var manifest *Manifest
+	var chunks []*server.BackupChunk
+	var uploaded []string
+	err := withFiles(func(files []File) (err error) {
+		manifest, chunks, uploaded, err = mg.writeChunks(store, backup, dir, files)
+		return
+	})
+
+	// Remove chunks uploaded to this backup
+	cleanChunks := func() {
+		for _, id := range uploaded {
+			store.Delete(chunkName(id))
+		}
+	}
+	if err != nil {
+		cleanChunks()
+		return nil, err
+	}
+
+	data, err := json.Marshal(manifest)
+	if err != nil {
+		cleanChunks()
+		return nil, err
+	} else if _, err = mg.putBlob(store, mg.Name(backup), data, backup.Encrypted); err != nil {
+		cleanChunks()
+		return nil, fmt.Errorf("cannot save manifest: %s", err)
+	}
+
+	if err = mg.Database.RefBackupChunks(chunks...); err != nil {
+		store.Delete(mg.Name(backup))
+		cleanChunks()
+		return nil, err
+	}
+
+	created, err := mg.Database.CreateBackup(backup)
+	if err != nil {
+		mg.unrefChunks(store, backup.Storage, chunks)
+		store.Delete(mg.Name(backup))
+		return nil, err
+	}
+	return created, nil
+}
+
+// Remove chunks references and delete chunks without references, caller must hold chunks lock
+func (mg *Manager) unrefChunks(store storage.Storage, storageName string, chunks []*server.BackupChunk) error {
+	hashes := make([]string, len(chunks))
+	for index, chunk := range chunks {
+		hashes[index] = chunk.Hash
+	}
+
+	unused, err := mg.Database.UnrefBackupChunks(storageName, hashes...)
+	if err != nil {
+		return err
+	}
+	for _, id := range unused {
+		if err = store.Delete(chunkName(id)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Read backup manifest
+func (mg *Manager) Manifest(backup *server.ServerBackup) (*Manifest, error) {
+	store, err := mg.Storage(backup.Storage)
+	if err != nil {
+		return nil, err
+	}
+	data, err := mg.getBlob(store, mg.Name(backup), backup.Encrypted)
+	if err != nil {
+		return nil, fmt.Errorf("cannot read manifest: %w", err)
+	}
+
+	manifest := new(Manifest)
+	if err = json.Unmarshal(data, manifest); err != nil {
+		return nil, fmt.Errorf("cannot decode manifest: %s", err)
+	}
+	return manifest, nil
+}
+
+// Remove incremental backup, chunks are deleted when no backups use it
+func (mg *Manager) deleteIncremental(store storage.Storage, backup *server.ServerBackup) error {
+	mg.chunksMu.Lock()
+	defer mg.chunksMu.Unlock()
+
+	manifest, err := mg.Manifest(backup)
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+
+	if manifest != nil {
+		var chunks []*server.BackupChunk
+		for _, id := range manifest.chunks() {
+			chunks = append(chunks, &server.BackupChunk{Storage: backup.Storage, Hash: id})
+		}
+		if err = mg.unrefChunks(store, backup.Storage, chunks); err != nil {
+			return err
+		}
+	}
+
+	if err = store.Delete(mg.Name(backup)); err != nil {
+		return err
+	} else if err = mg.Database.DeleteBackup(backup); err != nil {
+		return err
+	} else if manifest == nil {
+		// Manifest lost, references rebuilt from others backups manifests
+		if _, err = mg.rebuildRefs(store, backup.Storage); err != nil {
+			return fmt.Errorf("backup deleted, cannot rebuild chunks references: %s", err)
+		}
+	}
+	return nil
+}
+
+// Unique chunks in manifest
+func (manifest *Manifest) chunks() []string {
+	var chunks []string
+	seen := map[string]bool{}
+	for _, file := range manifest.Files {
+		for _, id := range file.Chunks {
+			if !seen[id] {
+				seen[id] = true
+				chunks = append(chunks, id)
+			}
+		}
+	}
+	return chunks
+}
+
+// Set chunks references in storage to incremental backups manifests and delete chunks without references,
+// return chunks deleted. Caller must hold chunks lock.
+//
+// All manifests in storage must be readable, otherwise chunks used by unreadable manifests could be deleted
+func (mg *Manager) rebuildRefs(store storage.Storage, storageName string) ([]string, error) {
+	backups, err := mg.Database.StorageBackups(storageName)
+	if err != nil {
+		return nil, err
+	}
+
+	refs := map[string]int64{}
+	for _, backup := range backups {
+		if !backup.Incremental {
+			continue
+		}
+		manifest, err := mg.Manifest(backup)
+		if err != nil {
+			return nil, fmt.Errorf("backup %d: %w", backup.ID, err)
+		}
+		for _, id := range manifest.chunks() {
+			refs[id]++
+		}
+	}
+
+	chunks, err := mg.Database.BackupChunks(storageName)
+	if err != nil {
+		return nil, err
+	}
+	var add []*server.BackupChunk
+	var remove []string
+	for _, chunk := range chunks {
+		expected := refs[chunk.Hash]
+		delete(refs, chunk.Hash)
+		for ; chunk.Refs > expected; chunk.Refs-- {
+			remove = append(remove, chunk.Hash)
+		}
+		for ; chunk.Refs < expected; chunk.Refs++ {
+			add = append(add, chunk)
+		}
+	}
+	for id, expected := range refs {
+		for range expected {
+			add = append(add, &server.BackupChunk{Storage: storageName, Hash: id}) // Chunk size not in manifest
+		}
+	}
+
+	if err = mg.Database.RefBackupChunks(add...); err != nil {
+		return nil, err
+	}
+	unused, err := mg.Database.UnrefBackupChunks(storageName, remove...)
+	if err != nil {
+		return nil, err
+	}
+	for _, id := range unused {
+		if err = store.Delete(chunkName(id)); err != nil {
+			return nil, err
+		}
+	}
+	return unused, nil
+}
+
+// Build zip archive from manifest in temporary file, file is removed on close
+func (mg *Manager) openIncremental(store storage.Storage, backup *server.ServerBackup) (storage.File, error) {
+	manifest, err := mg.Manifest(backup)
+	if err != nil {
+		return nil, err
+	}
+
+	file, err := os.CreateTemp("", "bds-backup-*.zip")
+	if err != nil {
+		return nil, err
+	}
+	tmp := &tempFile{file}
+
+	zw := zip.NewWriter(file)
+	for _, manifestFile := range manifest.Files {
+		header := &zip.FileHeader{Name: manifestFile.Path, Method: zip.Deflate, Modified: manifestFile.ModTime}
+		header.SetMode(manifestFile.Mode)
+		fw, err := zw.CreateHeader(header)
+		if err != nil {
+			tmp.Close()
+			return nil, err
+		}
+
+		for _, id := range manifestFile.Chunks {
+			data, err := mg.readChunk(store, id, backup.Encrypted)
+			if err != nil {
+				tmp.Close()
+				return nil, fmt.Errorf("cannot read chunk %s: %s", id, err)
+			} else if _, err = fw.Write(data); err != nil {
+				tmp.Close()
+				return nil, err
+			}
+		}
+	}
+
+	if err = zw.Close(); err != nil {
+		tmp.Close()
+		return nil, err
+	} else if _, err = file.Seek(0, io.SeekStart); err != nil {
+		tmp.Close()
+		return nil, err
+	}
+	return tmp, nil
+}
+
+// Temporary file removed on close
+type tempFile struct{ *os.File }
+
+func (file *tempFile) Close() error {
+	defer os.Remove(file.Name())
+	return file.File.Close()
+}
+
+// Check server backups, incremental chunks are read and checked with ID
+func (mg *Manager) Fsck(serverID int64) (*FsckReport, error) {
+	backups, err := mg.Database.ServerBackups(serverID)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &FsckReport{Missing: []string{}, Corrupted: []string{}, Damaged: []int64{}}
+	checked := map[string]error{}
+	for _, backup := range backups {
+		report.Backups++
+		damaged, err := mg.fsckBackup(backup, report, checked)
+		if err != nil {
+			return nil, err
+		} else if damaged {
+			report.Damaged = append(report.Damaged, backup.ID)
+		}
+	}
+	report.Chunks = len(checked)
+	return report, nil
+}
+
+func (mg *Manager) fsckBackup(backup *server.ServerBackup, report *FsckReport, checked map[string]error) (bool, error) {
+	store, err := mg.Storage(backup.Storage)
+	if err != nil {
+		return false, err
+	}
+
+	if !backup.Incremental {
+		file, err := store.Open(mg.Name(backup))
+		if err != nil {
+			report.Missing = append(report.Missing, mg.Name(backup))
+			return true, nil
+		}
+		return false, file.Close()
+	}
+
+	manifest, err := mg.Manifest(backup)
+	if err != nil {
+		report.Missing = append(report.Missing, mg.Name(backup))
+		return true, nil
+	}
+
+	damaged := false
+	for _, file := range manifest.Files {
+		for _, id := range file.Chunks {
+			key := backup.Storage + "/" + id
+			chunkErr, ok := checked[key]
+			if !ok {
+				_, chunkErr = mg.readChunk(store, id, backup.Encrypted)
+				checked[key] = chunkErr
+				switch {
+				case chunkErr == nil:
+				case errors.Is(chunkErr, fs.ErrNotExist):
+					report.Missing = append(report.Missing, id)
+				default:
+					report.Corrupted = append(report.Corrupted, id)
+				}
+			}
+			damaged = damaged || chunkErr != nil
+		}
+	}
+	return damaged, nil
+}
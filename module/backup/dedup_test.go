@@ -0,0 +1,148 @@
+package backup
+
+import (
+	"bytes"
+	"crypto/rand"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"sirherobrine23.com.br/go-bds/bds/module/server"
+)
+
+func chunks(data []byte) ([][]byte, error) {
+	var list [][]byte
+	chunker := NewChunker(bytes.NewReader(data))
+	for {
+		chunk, err := chunker.Next()
+		if err == io.EOF {
+			return list, nil
+		} else if err != nil {
+			return nil, err
+		}
+		list = append(list, bytes.Clone(chunk))
+	}
+}
+
+func TestChunker(t *testing.T) {
+	data := make([]byte, 8<<20)
+	rand.Read(data)
+
+	original, err := chunks(data)
+	if err != nil {
+		t.Error(err)
+		return
+	} else if !bytes.Equal(bytes.Join(original, nil), data) {
+		t.Errorf("chunks not join to original data")
+		return
+	}
+	for index, chunk := range original {
+		if len(chunk) > MaxChunkSize || (len(chunk) < MinChunkSize && index != len(original)-1) {
+			t.Errorf("invalid chunk size %d", len(chunk))
+			return
+		}
+	}
+
+	// Insert bytes in start, only first chunks must change
+	changed, err := chunks(append([]byte("inserted bytes"), data...))
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	same := 0
+	for _, chunk := range changed {
+		if slices.ContainsFunc(original, func(old []byte) bool { return bytes.Equal(old, chunk) }) {
+			same++
+		}
+	}
+	if same < len(original)-2 {
+		t.Errorf("only %d of %d chunks reused after insert", same, len(original))
+	}
+}
+
+func TestIncrementalBackup(t *testing.T) {
+	manager, mcServer, err := testManager(t, nil)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	database := manager.Database
+	if err = database.SetBackupConfig(&server.BackupConfig{ServerID: mcServer.ID, Incremental: true}); err != nil {
+		t.Errorf("cannot set backup config: %s", err)
+		return
+	}
+
+	dir := manager.Runner.Dir(mcServer.ID)
+	world := make([]byte, 4<<20)
+	rand.Read(world)
+	os.WriteFile(filepath.Join(dir, "worlds", "Bedrock level", "db", "000006.ldb"), world, 0644)
+
+	first, err := manager.Create(mcServer)
+	if err != nil {
+		t.Errorf("cannot create incremental backup: %s", err)
+		return
+	} else if !first.Incremental {
+		t.Errorf("backup not incremental")
+		return
+	}
+	firstChunks, _ := database.BackupChunks(LocalStorage)
+
+	// Change small part of world
+	copy(world[1<<20:], "changed world")
+	os.WriteFile(filepath.Join(dir, "worlds", "Bedrock level", "db", "000006.ldb"), world, 0644)
+	second, err := manager.Create(mcServer)
+	if err != nil {
+		t.Errorf("cannot create second incremental backup: %s", err)
+		return
+	}
+	secondChunks, _ := database.BackupChunks(LocalStorage)
+	if newChunks := len(secondChunks) - len(firstChunks); newChunks < 1 || newChunks > 2 {
+		t.Errorf("second backup stored %d new chunks", newChunks)
+		return
+	}
+
+	// Restore first backup
+	result, err := manager.Restore(first, mcServer, false)
+	if err != nil {
+		t.Errorf("cannot restore incremental backup: %s", err)
+		return
+	} else if data, _ := os.ReadFile(filepath.Join(dir, "worlds", "Bedrock level", "db", "000006.ldb")); bytes.Contains(data, []byte("changed world")) || len(data) != len(world) {
+		t.Errorf("world not restored from incremental backup")
+		return
+	}
+
+	// Corrupt one chunk
+	report, err := manager.Fsck(mcServer.ID)
+	if err != nil || len(report.Damaged) != 0 {
+		t.Errorf("fsck found problems in valid backups: %+v, %v", report, err)
+		return
+	}
+	os.WriteFile(filepath.Join(manager.Root, chunkName(secondChunks[0].Hash)), []byte("corrupted"), 0644)
+	if report, err = manager.Fsck(mcServer.ID); err != nil || len(report.Corrupted) != 1 || len(report.Damaged) == 0 {
+		t.Errorf("fsck not found corrupted chunk: %+v, %v", report, err)
+		return
+	}
+
+	// Remove all backups with one manifest lost, chunks store must be empty
+	os.Remove(filepath.Join(manager.Root, manager.Name(second)))
+	for _, backup := range []*server.ServerBackup{first, second, result.Safety} {
+		if err = manager.Delete(backup); err != nil {
+			t.Errorf("cannot delete backup: %s", err)
+			return
+		}
+	}
+	if chunksList, _ := database.BackupChunks(LocalStorage); len(chunksList) != 0 {
+		t.Errorf("chunks not removed: %d", len(chunksList))
+		return
+	}
+	if entries, _ := os.ReadDir(filepath.Join(manager.Root, "chunks")); len(entries) != 0 {
+		for _, entry := range entries {
+			if files, _ := os.ReadDir(filepath.Join(manager.Root, "chunks", entry.Name())); len(files) != 0 {
+				t.Errorf("chunk files not removed from storage")
+				return
+			}
+		}
+	}
+}
@@ -0,0 +1,202 @@
+package backup
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"sirherobrine23.com.br/go-bds/bds/module/runner"
+)
+
+var (
+	ErrSaveTimeout error = errors.New("timeout waiting server save")
+
+	DefaultSaveTimeout = time.Minute * 5 // Max time to wait server to be ready to copy
+	SaveQueryInterval  = time.Second     // Interval between "save query" commands
+)
+
+// Wait line from process matching function, ignore commands echo
+func waitLine(ctx context.Context, sub *runner.Subscriber, match func(message string) bool) (string, error) {
+	for {
+		select {
+		case <-ctx.Done():
+			return "", ErrSaveTimeout
+		case line, ok := <-sub.C:
+			if !ok {
+				if err := sub.Err(); err != nil && err != runner.ErrOutputClosed {
+					return "", err
+				}
+				return "", runner.ErrProcessExited
+			} else if line.Stream == runner.Stdin {
+				continue
+			} else if message := lineMessage(line.Text); match(message) {
+				return message, nil
+			}
+		}
+	}
+}
+
+// Remove log prefix from line, "[2024-01-01 00:00:00:000 INFO] Message" or "[00:00:00] [Server thread/INFO]: Message"
+func lineMessage(text string) string {
+	for strings.HasPrefix(text, "[") {
+		end := strings.Index(text, "]")
+		if end == -1 {
+			break
+		}
+		text = strings.TrimLeft(strings.TrimPrefix(text[end+1:], ":"), " ")
+	}
+	return text
+}
+
+// "save query" entry, path end in ":<length>" followed by ", " or line end, path can have ", " in level name
+var saveQueryEntry = regexp.MustCompile(`(.+?):(\d+)(?:, |$)`)
+
+// Parse "save query" files list: "Bedrock level/db/000005.ldb:1234, Bedrock level/level.dat:2548"
+func parseSaveQuery(line string) ([]File, error) {
+	var files []File
+	offset := 0
+	for _, match := range saveQueryEntry.FindAllStringSubmatchIndex(line, -1) {
+		if match[0] != offset {
+			return nil, fmt.Errorf("invalid save query entry: %q", line[offset:match[0]])
+		}
+		size, err := strconv.ParseInt(line[match[4]:match[5]], 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid save query size: %q", line[match[0]:match[1]])
+		}
+		files = append(files, File{Path: path.Join("worlds", line[match[2]:match[3]]), Size: size})
+		offset = match[1]
+	}
+	if offset != len(line) || len(files) == 0 {
+		return nil, fmt.Errorf("invalid save query entry: %q", line[offset:])
+	}
+	return files, nil
+}
+
+// Run "save hold" and poll "save query" until files are ready, return world files with length to copy.
+//
+// Caller must send "save resume" after copy
+func bedrockSaveHold(ctx context.Context, proc *runner.Process) ([]File, error) {
+	sub := proc.Output.Subscribe(0)
+	defer sub.Close()
+
+	if err := proc.SendCommand("save hold"); err != nil {
+		return nil, err
+	}
+
+	for {
+		if err := proc.SendCommand("save query"); err != nil {
+			return nil, err
+		}
+
+		message, err := waitLine(ctx, sub, func(message string) bool {
+			return strings.HasPrefix(message, "Data saved") || strings.HasPrefix(message, "A previous save has not been completed")
+		})
+		if err != nil {
+			return nil, err
+		} else if strings.HasPrefix(message, "Data saved") {
+			// Files list, others lines like "Player connected: Steve, xuid: 2535412345678901" can be printed before
+			var files []File
+			_, err := waitLine(ctx, sub, func(message string) bool {
+				list, err := parseSaveQuery(message)
+				files = list
+				return err == nil
+			})
+			if err != nil {
+				return nil, err
+			}
+			return files, nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, ErrSaveTimeout
+		case <-time.After(SaveQueryInterval):
+		}
+	}
+}
+
+// Disable auto save and flush world to disk, caller must send "save-on" after copy
+func javaSaveOff(ctx context.Context, proc *runner.Process) error {
+	sub := proc.Output.Subscribe(0)
+	defer sub.Close()
+
+	if err := proc.SendCommand("save-off"); err != nil {
+		return err
+	} else if err = proc.SendCommand("save-all flush"); err != nil {
+		return err
+	}
+	_, err := waitLine(ctx, sub, func(message string) bool { return strings.HasPrefix(message, "Saved the game") })
+	return err
+}
+
+// Call fn with running server files ready to copy, saving is always resumed after copy or on failure
+func (mg *Manager) hotFiles(proc *runner.Process, dir string, fn func(files []File) error) error {
+	timeout := mg.SaveTimeout
+	if timeout <= 0 {
+		timeout = DefaultSaveTimeout
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	return HoldWorld(ctx, proc, func(worldFiles []File) error {
+		if strings.EqualFold(proc.Server.Software, "bedrock") {
+			configFiles, err := Walk(dir, existing(dir, BedrockFiles))
+			if err != nil {
+				return err
+			}
+			return fn(append(worldFiles, configFiles...))
+		}
+
+		files, err := Walk(dir, Paths(proc.Server.Software, dir))
+		if err != nil {
+			return err
+		}
+		return fn(files)
+	})
+}
+
+// Stop running server from writing world while fn copy world files, saving is resumed after fn return.
+//
+// Bedrock files from "save query" are passed to fn with length to copy, Java world is flushed and fn get nil files.
+func HoldWorld(ctx context.Context, proc *runner.Process, fn func(worldFiles []File) error) error {
+	if strings.EqualFold(proc.Server.Software, "bedrock") {
+		defer proc.SendCommand("save resume")
+		worldFiles, err := bedrockSaveHold(ctx, proc)
+		if err != nil {
+			return fmt.Errorf("cannot hold world save: %s", err)
+		}
+		return fn(worldFiles)
+	}
+
+	defer proc.SendCommand("save-on")
+	if err := javaSaveOff(ctx, proc); err != nil {
+		return fmt.Errorf("cannot flush world save: %s", err)
+	}
+	return fn(nil)
+}
+
+// Flush running server world to disk without stop auto save
+func SaveWorld(ctx context.Context, proc *runner.Process) error {
+	if strings.EqualFold(proc.Server.Software, "bedrock") {
+		defer proc.SendCommand("save resume")
+		if _, err := bedrockSaveHold(ctx, proc); err != nil {
+			return fmt.Errorf("cannot save world: %s", err)
+		}
+		return nil
+	}
+
+	sub := proc.Output.Subscribe(0)
+	defer sub.Close()
+	if err := proc.SendCommand("save-all flush"); err != nil {
+		return err
+	}
+	if _, err := waitLine(ctx, sub, func(message string) bool { return strings.HasPrefix(message, "Saved the game") }); err != nil {
+		return fmt.Errorf("cannot save world: %s", err)
+	}
+	return nil
+}
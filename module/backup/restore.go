@@ -0,0 +1,240 @@
+package backup
+
+import (
+	"archive/zip"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"sirherobrine23.com.br/go-bds/bds/module/mcversion"
+	"sirherobrine23.com.br/go-bds/bds/module/server"
+)
+
+var (
+	ErrSoftwareMismatch error = errors.New("backup software is different from server software")
+	ErrNewerVersion     error = errors.New("backup version is newer than server version")
+	ErrInvalidArchive   error = errors.New("backup archive have invalid file path")
+)
+
+// Restore result
+type RestoreResult struct {
+	Safety    *server.ServerBackup `json:"safety"`    // Backup taken before restore, null if server not have files
+	Warnings  []string             `json:"warnings"`  // Warnings to user
+	Restarted bool                 `json:"restarted"` // Server was running and started again
+}
+
+// Check if backup can be restored in server, return warnings if versions cannot be compared or is newer and force
+func CheckRestore(backup *server.ServerBackup, target *server.Server, force bool) ([]string, error) {
+	if !strings.EqualFold(backup.Software, target.Software) {
+		return nil, ErrSoftwareMismatch
+	}
+
+	warnings := []string{}
+	switch cmp, ok := mcversion.Compare(backup.Version, target.Version); {
+	case !ok && backup.Version != target.Version:
+		warnings = append(warnings, fmt.Sprintf("cannot compare backup version %q with server version %q", backup.Version, target.Version))
+	case cmp > 0 && !force:
+		return nil, ErrNewerVersion
+	case cmp > 0:
+		warnings = append(warnings, fmt.Sprintf("backup version %s is newer than server version %s, world may not load", backup.Version, target.Version))
+	}
+	return warnings, nil
+}
+
+// Stop server, take safety backup, replace world and config with backup files and start server again if was running.
+//
+// target can be other server with same software to clone backup
+func (mg *Manager) Restore(backup *server.ServerBackup, target *server.Server, force bool) (*RestoreResult, error) {
+	warnings, err := CheckRestore(backup, target, force)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := mg.lock(target.ID); err != nil {
+		return nil, err
+	}
+	defer mg.unlock(target.ID)
+
+	result := &RestoreResult{Warnings: warnings}
+	wasRunning := mg.Runner.Process(target.ID) != nil
+	if wasRunning {
+		if err := mg.Runner.Stop(target.ID, 0); err != nil && mg.Runner.Process(target.ID) != nil {
+			return nil, fmt.Errorf("cannot stop server: %s", err)
+		}
+	}
+
+	if result.Safety, err = mg.create(target, true); err != nil && err != ErrNoFiles {
+		err = fmt.Errorf("cannot create safety backup: %s", err)
+	} else {
+		err = mg.extract(backup, target)
+	}
+
+	// Start server again with restored files or with current files if restore failed
+	if wasRunning {
+		if _, startErr := mg.Runner.Start(target); startErr != nil {
+			if err == nil {
+				return result, fmt.Errorf("backup restored but cannot start server: %s", startErr)
+			}
+			return result, errors.Join(err, fmt.Errorf("cannot start server: %s", startErr))
+		}
+		result.Restarted = true
+	}
+	return result, err
+}
+
+// Extract backup to temporary directory and replace server files
+func (mg *Manager) extract(backup *server.ServerBackup, target *server.Server) error {
+	archive, err := mg.Open(backup)
+	if err != nil {
+		return fmt.Errorf("cannot open backup: %s", err)
+	}
+	defer archive.Close()
+
+	dir := mg.Runner.Dir(target.ID)
+	if err = os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+	tmp, err := os.MkdirTemp(dir, ".restore-")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(tmp)
+
+	// Remote storages are downloaded first, zip reader make many small reads
+	switch archive.(type) {
+	case *os.File, *tempFile:
+	default:
+		local, err := os.CreateTemp(dir, ".restore-*.zip")
+		if err != nil {
+			return err
+		}
+		defer os.Remove(local.Name())
+		defer local.Close()
+		if _, err = io.Copy(local, archive); err != nil {
+			return fmt.Errorf("cannot download backup: %s", err)
+		}
+		archive = local
+	}
+
+	stat, err := archive.Stat()
+	if err != nil {
+		return err
+	}
+	zr, err := zip.NewReader(archive, stat.Size())
+	if err != nil {
+		return fmt.Errorf("cannot read backup archive: %s", err)
+	}
+
+	if err = Extract(zr, tmp); err != nil {
+		return err
+	}
+
+	entries, err := os.ReadDir(tmp)
+	if err != nil {
+		return err
+	}
+
+	// Move current files aside, moved back if any restored file cannot be moved in place
+	aside, err := os.MkdirTemp(dir, ".restore-old-")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(aside)
+
+	var moved, placed []string
+	rollback := func(err error) error {
+		for _, name := range placed {
+			os.RemoveAll(filepath.Join(dir, name))
+		}
+		for i := len(moved) - 1; i >= 0; i-- {
+			os.Rename(filepath.Join(aside, moved[i]), filepath.Join(dir, moved[i]))
+		}
+		return err
+	}
+
+	names := Paths(target.Software, dir)
+	for _, entry := range entries {
+		if !slices.Contains(names, entry.Name()) {
+			names = append(names, entry.Name())
+		}
+	}
+	for _, name := range names {
+		src := filepath.Join(dir, name)
+		if _, err = os.Lstat(src); errors.Is(err, fs.ErrNotExist) {
+			continue // Not exists or already moved with parent
+		} else if err != nil {
+			return rollback(err)
+		}
+
+		dst := filepath.Join(aside, name)
+		if err = os.MkdirAll(filepath.Dir(dst), 0755); err != nil {
+			return rollback(err)
+		} else if err = os.Rename(src, dst); err != nil {
+			return rollback(err)
+		}
+		moved = append(moved, name)
+	}
+
+	for _, entry := range entries {
+		if err = os.Rename(filepath.Join(tmp, entry.Name()), filepath.Join(dir, entry.Name())); err != nil {
+			return rollback(err)
+		}
+		placed = append(placed, entry.Name())
+	}
+	return nil
+}
+
+// Extract zip files to dir, refuse absolute paths and paths outside dir
+func Extract(zr *zip.Reader, dir string) error {
+	for _, file := range zr.File {
+		name := path.Clean(file.Name)
+		if path.IsAbs(name) || name == ".." || strings.HasPrefix(name, "../") || strings.Contains(file.Name, "\\") {
+			return ErrInvalidArchive
+		}
+
+		target := filepath.Join(dir, filepath.FromSlash(name))
+		if file.FileInfo().IsDir() {
+			if err := os.MkdirAll(target, 0755); err != nil {
+				return err
+			}
+			continue
+		} else if !file.Mode().IsRegular() {
+			continue // Skip symlinks
+		}
+
+		if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+			return err
+		} else if err := extractFile(file, target); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func extractFile(file *zip.File, target string) error {
+	r, err := file.Open()
+	if err != nil {
+		return err
+	}
+	defer r.Close()
+
+	w, err := os.OpenFile(target, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, file.Mode().Perm()|0600)
+	if err != nil {
+		return err
+	}
+	defer w.Close()
+
+	if _, err = io.Copy(w, r); err != nil {
+		return err
+	}
+	if err = w.Close(); err != nil {
+		return err
+	}
+	return os.Chtimes(target, file.Modified, file.Modified)
+}
@@ -0,0 +1,101 @@
+package backup
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"sirherobrine23.com.br/go-bds/bds/module/server"
+)
+
+func TestRestore(t *testing.T) {
+	manager, mcServer, err := testManager(t, nil)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	dir := manager.Runner.Dir(mcServer.ID)
+
+	mcBackup, err := manager.Create(mcServer)
+	if err != nil {
+		t.Errorf("cannot create backup: %s", err)
+		return
+	}
+
+	// Change world after backup
+	os.WriteFile(filepath.Join(dir, "worlds", "Bedrock level", "db", "CURRENT"), []byte("MANIFEST-000002\n"), 0644)
+	os.WriteFile(filepath.Join(dir, "worlds", "Bedrock level", "db", "000006.ldb"), []byte("new"), 0644)
+
+	result, err := manager.Restore(mcBackup, mcServer, false)
+	if err != nil {
+		t.Errorf("cannot restore backup: %s", err)
+		return
+	} else if result.Safety == nil {
+		t.Errorf("safety backup not created")
+		return
+	}
+
+	if data, _ := os.ReadFile(filepath.Join(dir, "worlds", "Bedrock level", "db", "CURRENT")); string(data) != "MANIFEST-000001\n" {
+		t.Errorf("world not restored: %q", data)
+		return
+	} else if _, err := os.Stat(filepath.Join(dir, "worlds", "Bedrock level", "db", "000006.ldb")); !os.IsNotExist(err) {
+		t.Errorf("file created after backup not removed")
+		return
+	} else if _, err := os.Stat(filepath.Join(dir, "bedrock_server")); err != nil {
+		t.Errorf("server binary removed on restore")
+		return
+	}
+
+	// Newer backup and other software
+	older := *mcServer
+	older.Version = "1.20.0.01"
+	if _, err = manager.Restore(mcBackup, &older, false); err != ErrNewerVersion {
+		t.Errorf("expected newer version error, got %v", err)
+		return
+	}
+	java := *mcServer
+	java.Software = "java"
+	if _, err = manager.Restore(mcBackup, &java, true); err != ErrSoftwareMismatch {
+		t.Errorf("expected software mismatch error, got %v", err)
+		return
+	}
+
+	// Clone to new server
+	user, err := manager.Database.Username("backup")
+	if err != nil {
+		t.Errorf("cannot get user: %s", err)
+		return
+	}
+	clone, err := manager.Database.CreateServer(user, &server.Server{Software: "bedrock", Version: "1.21.2.02", Owner: mcServer.Owner})
+	if err != nil {
+		t.Errorf("cannot create clone server: %s", err)
+		return
+	} else if _, err = manager.Restore(mcBackup, clone, false); err != nil {
+		t.Errorf("cannot clone backup: %s", err)
+		return
+	}
+	if _, err := os.Stat(filepath.Join(manager.Runner.Dir(clone.ID), "worlds", "Bedrock level", "db", "000005.ldb")); err != nil {
+		t.Errorf("world not cloned: %s", err)
+		return
+	}
+
+	// Broken archive keep current files
+	store, err := manager.Storage(mcBackup.Storage)
+	if err != nil {
+		t.Errorf("cannot get storage: %s", err)
+		return
+	} else if err = store.Put(manager.Name(mcBackup), strings.NewReader("not zip")); err != nil {
+		t.Errorf("cannot replace archive: %s", err)
+		return
+	} else if _, err = manager.Restore(mcBackup, mcServer, false); err == nil {
+		t.Errorf("restore of broken archive not failed")
+		return
+	} else if data, _ := os.ReadFile(filepath.Join(dir, "worlds", "Bedrock level", "db", "CURRENT")); string(data) != "MANIFEST-000001\n" {
+		t.Errorf("current world changed on failed restore: %q", data)
+		return
+	} else if entries, _ := os.ReadDir(dir); slices.ContainsFunc(entries, func(entry os.DirEntry) bool { return strings.HasPrefix(entry.Name(), ".restore-") }) {
+		t.Errorf("restore temporary files not removed")
+	}
+}
@@ -0,0 +1,87 @@
+package backup
+
+import (
+	"cmp"
+	"fmt"
+	"slices"
+	"time"
+
+	"sirherobrine23.com.br/go-bds/bds/module/server"
+)
+
+// Split backups to keep and remove with retention policy, policy without any keep value keep all backups.
+//
+// Keep last N backups, newest backup of each day in last D days and newest backup of each week in last W weeks
+func Retain(backups []*server.ServerBackup, policy *server.BackupConfig, now time.Time) (keep, remove []*server.ServerBackup) {
+	sorted := slices.Clone(backups)
+	slices.SortFunc(sorted, func(a, b *server.ServerBackup) int {
+		if order := b.CreateAt.Compare(a.CreateAt); order != 0 {
+			return order
+		}
+		return cmp.Compare(b.ID, a.ID)
+	})
+	if policy.KeepLast <= 0 && policy.KeepDaily <= 0 && policy.KeepWeekly <= 0 {
+		return sorted, nil
+	}
+
+	kept := map[int64]bool{}
+	for index := 0; index < policy.KeepLast && index < len(sorted); index++ {
+		kept[sorted[index].ID] = true
+	}
+
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	if policy.KeepDaily > 0 {
+		cutoff, days := today.AddDate(0, 0, -(policy.KeepDaily-1)), map[string]bool{}
+		for _, backup := range sorted {
+			createAt := backup.CreateAt.In(now.Location())
+			if day := createAt.Format(time.DateOnly); !createAt.Before(cutoff) && !days[day] {
+				days[day], kept[backup.ID] = true, true
+			}
+		}
+	}
+
+	if policy.KeepWeekly > 0 {
+		// Weeks start in monday like ISO weeks
+		weekStart := today.AddDate(0, 0, -(int(today.Weekday())+6)%7)
+		cutoff, weeks := weekStart.AddDate(0, 0, -7*(policy.KeepWeekly-1)), map[string]bool{}
+		for _, backup := range sorted {
+			createAt := backup.CreateAt.In(now.Location())
+			year, week := createAt.ISOWeek()
+			if key := fmt.Sprintf("%d-%d", year, week); !createAt.Before(cutoff) && !weeks[key] {
+				weeks[key], kept[backup.ID] = true, true
+			}
+		}
+	}
+
+	for _, backup := range sorted {
+		if kept[backup.ID] {
+			keep = append(keep, backup)
+		} else {
+			remove = append(remove, backup)
+		}
+	}
+	return
+}
+
+// Remove server backups not kept by server retention policy, return removed backups count.
+//
+// Safety backups are not included in retention, only removed manually
+func (mg *Manager) Prune(serverID int64) (int, error) {
+	policy, err := mg.Database.BackupConfig(serverID)
+	if err != nil {
+		return 0, err
+	}
+	backups, err := mg.Database.ServerBackups(serverID)
+	if err != nil {
+		return 0, err
+	}
+
+	backups = slices.DeleteFunc(backups, func(backup *server.ServerBackup) bool { return backup.Safety })
+	_, remove := Retain(backups, policy, time.Now())
+	for index, backup := range remove {
+		if err = mg.Delete(backup); err != nil {
+			return index, fmt.Errorf("cannot remove backup %d: %s", backup.ID, err)
+		}
+	}
+	return len(remove), nil
+}
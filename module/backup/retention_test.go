@@ -0,0 +1,37 @@
+package backup
+
+import (
+	"slices"
+	"testing"
+	"time"
+
+	"sirherobrine23.com.br/go-bds/bds/module/server"
+)
+
+func TestRetain(t *testing.T) {
+	now := time.Date(2024, time.June, 12, 12, 0, 0, 0, time.UTC)
+	var backups []*server.ServerBackup
+	// Backup every 6 hours in last 30 days
+	for index := range 30 * 4 {
+		backups = append(backups, &server.ServerBackup{ID: int64(index + 1), CreateAt: now.Add(-time.Duration(index) * 6 * time.Hour)})
+	}
+
+	if keep, remove := Retain(backups, &server.BackupConfig{}, now); len(keep) != len(backups) || len(remove) != 0 {
+		t.Errorf("empty policy removed backups")
+		return
+	}
+
+	keep, remove := Retain(backups, &server.BackupConfig{KeepLast: 3, KeepDaily: 7, KeepWeekly: 4}, now)
+	if len(keep)+len(remove) != len(backups) {
+		t.Errorf("backups lost in retention")
+		return
+	}
+	var ids []int64
+	for _, backup := range keep {
+		ids = append(ids, backup.ID)
+	}
+	// Last 3, newest of each day in 7 days and newest of each week in 4 weeks
+	if expected := []int64{1, 2, 3, 4, 8, 12, 16, 20, 24, 40, 68}; !slices.Equal(ids, expected) {
+		t.Errorf("kept backups %v, expected %v", ids, expected)
+	}
+}
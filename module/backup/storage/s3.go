@@ -0,0 +1,406 @@
+package storage
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/http"
+	"net/url"
+	"path"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var _ Storage = &S3{}
+
+const (
+	MinPartSize     int64 = 5 << 20 // S3 minimum multipart part size, except last part
+	DefaultPartSize int64 = 8 << 20 // Default multipart part size
+
+	emptyHash = "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855" // sha256 of empty payload
+)
+
+// Save backups in S3 compatible object storage (AWS, MinIO, R2, ...).
+//
+// Files bigger than part size are uploaded with multipart upload, only one part is in memory at time
+type S3 struct {
+	Endpoint  string       // Endpoint URL, example "https://s3.us-east-1.amazonaws.com" or "http://localhost:9000"
+	Region    string       // Bucket region, if empty use "us-east-1"
+	Bucket    string       // Bucket name
+	Prefix    string       // Prefix to objects keys
+	AccessKey string       // Access key ID
+	SecretKey string       // Secret access key
+	PathStyle bool         // Use "endpoint/bucket/key" in place of "bucket.endpoint/key", required by MinIO
+	PartSize  int64        // Multipart part size, if < [MinPartSize] use [DefaultPartSize]
+	Client    *http.Client // HTTP client, if nil use [http.DefaultClient]
+}
+
+// S3 error response
+type S3Error struct {
+	Status  int    `xml:"-"`
+	Code    string `xml:"Code"`
+	Message string `xml:"Message"`
+}
+
+func (err S3Error) Error() string {
+	return fmt.Sprintf("s3 error %d %s: %s", err.Status, err.Code, err.Message)
+}
+
+func (err S3Error) Is(target error) bool {
+	return target == fs.ErrNotExist && (err.Status == http.StatusNotFound || err.Code == "NoSuchKey")
+}
+
+type s3InitiateMultipart struct {
+	UploadID string `xml:"UploadId"`
+}
+
+type s3CompletePart struct {
+	PartNumber int    `xml:"PartNumber"`
+	ETag       string `xml:"ETag"`
+}
+
+type s3CompleteMultipart struct {
+	XMLName xml.Name         `xml:"CompleteMultipartUpload"`
+	Parts   []s3CompletePart `xml:"Part"`
+}
+
+// Escape string to AWS canonical form, only unreserved characters are not escaped
+func awsEscape(value string, keepSlash bool) string {
+	var buff strings.Builder
+	for _, char := range []byte(value) {
+		switch {
+		case 'A' <= char && char <= 'Z', 'a' <= char && char <= 'z', '0' <= char && char <= '9',
+			char == '-', char == '_', char == '.', char == '~', keepSlash && char == '/':
+			buff.WriteByte(char)
+		default:
+			fmt.Fprintf(&buff, "%%%02X", char)
+		}
+	}
+	return buff.String()
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	hash := hmac.New(sha256.New, key)
+	hash.Write([]byte(data))
+	return hash.Sum(nil)
+}
+
+func (s3 *S3) region() string {
+	if s3.Region == "" {
+		return "us-east-1"
+	}
+	return s3.Region
+}
+
+func (s3 *S3) client() *http.Client {
+	if s3.Client == nil {
+		return http.DefaultClient
+	}
+	return s3.Client
+}
+
+func (s3 *S3) partSize() int64 {
+	if s3.PartSize < MinPartSize {
+		return DefaultPartSize
+	}
+	return s3.PartSize
+}
+
+// Object URL to name
+func (s3 *S3) objectURL(name string, query url.Values) (*url.URL, error) {
+	endpoint, err := url.Parse(s3.Endpoint)
+	if err != nil {
+		return nil, fmt.Errorf("invalid s3 endpoint: %s", err)
+	}
+
+	key := path.Join(s3.Prefix, cleanName(name))
+	if s3.PathStyle {
+		endpoint.Path = path.Join("/", endpoint.Path, s3.Bucket, key)
+	} else {
+		endpoint.Host = s3.Bucket + "." + endpoint.Host
+		endpoint.Path = path.Join("/", endpoint.Path, key)
+	}
+	endpoint.RawPath = awsEscape(endpoint.Path, true)
+	endpoint.RawQuery = s3.canonicalQuery(query)
+	return endpoint, nil
+}
+
+// Query sorted and escaped, same used in request and signature
+func (s3 *S3) canonicalQuery(query url.Values) string {
+	keys := make([]string, 0, len(query))
+	for key := range query {
+		keys = append(keys, key)
+	}
+	slices.Sort(keys)
+
+	var parts []string
+	for _, key := range keys {
+		values := slices.Clone(query[key])
+		slices.Sort(values)
+		for _, value := range values {
+			parts = append(parts, awsEscape(key, false)+"="+awsEscape(value, false))
+		}
+	}
+	return strings.Join(parts, "&")
+}
+
+// Sign request with AWS Signature Version 4
+func (s3 *S3) sign(req *http.Request, payloadHash string, now time.Time) {
+	amzDate := now.UTC().Format("20060102T150405Z")
+	date := amzDate[:8]
+	req.Header.Set("X-Amz-Date", amzDate)
+	req.Header.Set("X-Amz-Content-Sha256", payloadHash)
+
+	signedHeaders := "host;x-amz-content-sha256;x-amz-date"
+	canonicalRequest := strings.Join([]string{
+		req.Method,
+		req.URL.EscapedPath(),
+		req.URL.RawQuery,
+		"host:" + req.URL.Host + "\nx-amz-content-sha256:" + payloadHash + "\nx-amz-date:" + amzDate + "\n",
+		signedHeaders,
+		payloadHash,
+	}, "\n")
+
+	scope := date + "/" + s3.region() + "/s3/aws4_request"
+	requestHash := sha256.Sum256([]byte(canonicalRequest))
+	stringToSign := "AWS4-HMAC-SHA256\n" + amzDate + "\n" + scope + "\n" + hex.EncodeToString(requestHash[:])
+
+	key := hmacSHA256([]byte("AWS4"+s3.SecretKey), date)
+	key = hmacSHA256(key, s3.region())
+	key = hmacSHA256(key, "s3")
+	key = hmacSHA256(key, "aws4_request")
+	signature := hex.EncodeToString(hmacSHA256(key, stringToSign))
+
+	req.Header.Set("Authorization", fmt.Sprintf("AWS4-HMAC-SHA256 Credential=%s/%s, SignedHeaders=%s, Signature=%s", s3.AccessKey, scope, signedHeaders, signature))
+}
+
+// Make signed request, body is sent with sha256 hash, non 2xx status is returned as [S3Error]
+func (s3 *S3) do(method, name string, query url.Values, header http.Header, body []byte) (*http.Response, error) {
+	objectURL, err := s3.objectURL(name, query)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequest(method, objectURL.String(), bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	for key, values := range header {
+		req.Header[key] = values
+	}
+
+	payloadHash := emptyHash
+	if len(body) > 0 {
+		hash := sha256.Sum256(body)
+		payloadHash = hex.EncodeToString(hash[:])
+	}
+	s3.sign(req, payloadHash, time.Now())
+
+	res, err := s3.client().Do(req)
+	if err != nil {
+		return nil, err
+	} else if res.StatusCode < 200 || res.StatusCode > 299 {
+		defer res.Body.Close()
+		s3Err := S3Error{Status: res.StatusCode}
+		xml.NewDecoder(res.Body).Decode(&s3Err)
+		if s3Err.Code == "" {
+			s3Err.Code = http.StatusText(res.StatusCode)
+		}
+		return nil, s3Err
+	}
+	return res, nil
+}
+
+func (s3 *S3) Put(name string, r io.Reader) error {
+	part := make([]byte, s3.partSize())
+	n, err := io.ReadFull(r, part)
+	if err == io.EOF || err == io.ErrUnexpectedEOF {
+		// Small file, single request
+		res, err := s3.do(http.MethodPut, name, nil, nil, part[:n])
+		if err != nil {
+			return err
+		}
+		return res.Body.Close()
+	} else if err != nil {
+		return err
+	}
+
+	res, err := s3.do(http.MethodPost, name, url.Values{"uploads": {""}}, nil, nil)
+	if err != nil {
+		return fmt.Errorf("cannot create multipart upload: %s", err)
+	}
+	var initiate s3InitiateMultipart
+	err = xml.NewDecoder(res.Body).Decode(&initiate)
+	res.Body.Close()
+	if err != nil {
+		return fmt.Errorf("cannot decode multipart upload: %s", err)
+	}
+
+	if err = s3.uploadParts(name, initiate.UploadID, r, part, n); err != nil {
+		// Abort to remove uploaded parts
+		if res, abortErr := s3.do(http.MethodDelete, name, url.Values{"uploadId": {initiate.UploadID}}, nil, nil); abortErr == nil {
+			res.Body.Close()
+		}
+		return err
+	}
+	return nil
+}
+
+// Upload parts until reader end and complete multipart upload, first part is already read in part[:n]
+func (s3 *S3) uploadParts(name, uploadID string, r io.Reader, part []byte, n int) error {
+	var complete s3CompleteMultipart
+	for partNumber := 1; n > 0; partNumber++ {
+		res, err := s3.do(http.MethodPut, name, url.Values{"partNumber": {strconv.Itoa(partNumber)}, "uploadId": {uploadID}}, nil, part[:n])
+		if err != nil {
+			return fmt.Errorf("cannot upload part %d: %s", partNumber, err)
+		}
+		res.Body.Close()
+		complete.Parts = append(complete.Parts, s3CompletePart{PartNumber: partNumber, ETag: res.Header.Get("ETag")})
+
+		if n, err = io.ReadFull(r, part); err != nil && err != io.EOF && err != io.ErrUnexpectedEOF {
+			return err
+		}
+	}
+
+	body, err := xml.Marshal(complete)
+	if err != nil {
+		return err
+	}
+	res, err := s3.do(http.MethodPost, name, url.Values{"uploadId": {uploadID}}, http.Header{"Content-Type": {"application/xml"}}, body)
+	if err != nil {
+		return fmt.Errorf("cannot complete multipart upload: %s", err)
+	}
+	defer res.Body.Close()
+
+	// S3 can return error in body with 200 status
+	var s3Err S3Error
+	if xml.NewDecoder(res.Body).Decode(&s3Err); s3Err.Code != "" {
+		s3Err.Status = res.StatusCode
+		return s3Err
+	}
+	return nil
+}
+
+func (s3 *S3) Open(name string) (File, error) {
+	res, err := s3.do(http.MethodHead, name, nil, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	res.Body.Close()
+
+	modTime, _ := http.ParseTime(res.Header.Get("Last-Modified"))
+	return &s3File{s3: s3, name: name, size: res.ContentLength, modTime: modTime}, nil
+}
+
+func (s3 *S3) Delete(name string) error {
+	res, err := s3.do(http.MethodDelete, name, nil, nil, nil)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil
+		}
+		return err
+	}
+	return res.Body.Close()
+}
+
+// Object read with Range requests
+type s3File struct {
+	s3      *S3
+	name    string
+	size    int64
+	modTime time.Time
+
+	offset int64
+	body   io.ReadCloser // Current body from offset to end of object
+}
+
+type s3FileInfo struct{ file *s3File }
+
+func (info s3FileInfo) Name() string       { return path.Base(info.file.name) }
+func (info s3FileInfo) Size() int64        { return info.file.size }
+func (info s3FileInfo) Mode() fs.FileMode  { return 0644 }
+func (info s3FileInfo) ModTime() time.Time { return info.file.modTime }
+func (info s3FileInfo) IsDir() bool        { return false }
+func (info s3FileInfo) Sys() any           { return nil }
+
+func (file *s3File) Stat() (fs.FileInfo, error) { return s3FileInfo{file}, nil }
+
+// Get object bytes from start to end, end is inclusive
+func (file *s3File) get(start, end int64) (io.ReadCloser, error) {
+	res, err := file.s3.do(http.MethodGet, file.name, nil, http.Header{"Range": {fmt.Sprintf("bytes=%d-%d", start, end)}}, nil)
+	if err != nil {
+		return nil, err
+	}
+	return res.Body, nil
+}
+
+func (file *s3File) Read(p []byte) (int, error) {
+	if file.offset >= file.size {
+		return 0, io.EOF
+	} else if file.body == nil {
+		body, err := file.get(file.offset, file.size-1)
+		if err != nil {
+			return 0, err
+		}
+		file.body = body
+	}
+
+	n, err := file.body.Read(p)
+	file.offset += int64(n)
+	if err == io.EOF && file.offset < file.size {
+		err = io.ErrUnexpectedEOF
+	}
+	return n, err
+}
+
+func (file *s3File) ReadAt(p []byte, off int64) (int, error) {
+	if off >= file.size {
+		return 0, io.EOF
+	}
+	end := min(off+int64(len(p)), file.size)
+	body, err := file.get(off, end-1)
+	if err != nil {
+		return 0, err
+	}
+	defer body.Close()
+
+	n, err := io.ReadFull(body, p[:end-off])
+	if err == nil && end < off+int64(len(p)) {
+		err = io.EOF
+	}
+	return n, err
+}
+
+func (file *s3File) Seek(offset int64, whence int) (int64, error) {
+	switch whence {
+	case io.SeekCurrent:
+		offset += file.offset
+	case io.SeekEnd:
+		offset += file.size
+	}
+	if offset < 0 {
+		return 0, errors.New("s3: negative position")
+	}
+
+	if offset != file.offset && file.body != nil {
+		file.body.Close()
+		file.body = nil
+	}
+	file.offset = offset
+	return offset, nil
+}
+
+func (file *s3File) Close() error {
+	if file.body != nil {
+		return file.body.Close()
+	}
+	return nil
+}
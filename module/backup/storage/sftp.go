@@ -0,0 +1,94 @@
+package storage
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"path"
+
+	"github.com/pkg/sftp"
+	"golang.org/x/crypto/ssh"
+)
+
+var _ Storage = &SFTP{}
+
+// Save backups in remote server over SFTP
+type SFTP struct {
+	Client *sftp.Client // SFTP client
+	Root   string       // Remote directory to storage files
+
+	conn *ssh.Client
+}
+
+// Create storage from SFTP client
+func NewSFTP(client *sftp.Client, root string) *SFTP {
+	return &SFTP{Client: client, Root: root}
+}
+
+// Connect to SSH server and open SFTP session
+func DialSFTP(addr string, config *ssh.ClientConfig, root string) (*SFTP, error) {
+	conn, err := ssh.Dial("tcp", addr, config)
+	if err != nil {
+		return nil, fmt.Errorf("cannot connect to ssh server: %s", err)
+	}
+
+	client, err := sftp.NewClient(conn)
+	if err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("cannot open sftp session: %s", err)
+	}
+	return &SFTP{Client: client, Root: root, conn: conn}, nil
+}
+
+// Close SFTP session and SSH connection if opened with [DialSFTP]
+func (remote *SFTP) Close() error {
+	err := remote.Client.Close()
+	if remote.conn != nil {
+		remote.conn.Close()
+	}
+	return err
+}
+
+func (remote *SFTP) path(name string) string {
+	return path.Join(remote.Root, cleanName(name))
+}
+
+func (remote *SFTP) Put(name string, r io.Reader) error {
+	filePath := remote.path(name)
+	if err := remote.Client.MkdirAll(path.Dir(filePath)); err != nil {
+		return err
+	}
+
+	tmpPath := filePath + ".tmp"
+	file, err := remote.Client.Create(tmpPath)
+	if err != nil {
+		return err
+	}
+
+	// ReadFrom send packets concurrently without load all reader in memory
+	if _, err = file.ReadFrom(r); err == nil {
+		err = file.Close()
+	} else {
+		file.Close()
+	}
+	if err == nil {
+		remote.Client.Remove(filePath)
+		err = remote.Client.Rename(tmpPath, filePath)
+	}
+	if err != nil {
+		remote.Client.Remove(tmpPath)
+	}
+	return err
+}
+
+func (remote *SFTP) Open(name string) (File, error) {
+	return remote.Client.Open(remote.path(name))
+}
+
+func (remote *SFTP) Delete(name string) error {
+	if err := remote.Client.Remove(remote.path(name)); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+	return nil
+}
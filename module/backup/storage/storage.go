@@ -0,0 +1,79 @@
+// Storage backends to backups archives
+package storage
+
+import (
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+)
+
+var _ Storage = &Local{}
+
+// Archive opened from storage, [os.File] implements it
+type File interface {
+	io.Reader
+	io.ReaderAt
+	io.Seeker
+	io.Closer
+	Stat() (fs.FileInfo, error)
+}
+
+// Backend to save backups archives, names are slash separated paths like "1/<uuid>.zip"
+type Storage interface {
+	Put(name string, r io.Reader) error // Write all data from reader, file is only visible after reader return io.EOF, on error nothing is saved
+	Open(name string) (File, error)     // Open file to read
+	Delete(name string) error           // Remove file, not exists files are ignored
+}
+
+// Clean name to relative path, remove ".." from names
+func cleanName(name string) string {
+	return path.Clean("/" + name)[1:]
+}
+
+// Save backups in local directory
+type Local struct {
+	Root string // Directory to storage files
+}
+
+// Create new local storage in root
+func NewLocal(root string) *Local {
+	return &Local{Root: root}
+}
+
+func (local *Local) path(name string) string {
+	return filepath.Join(local.Root, filepath.FromSlash(cleanName(name)))
+}
+
+func (local *Local) Put(name string, r io.Reader) error {
+	filePath := local.path(name)
+	if err := os.MkdirAll(filepath.Dir(filePath), 0755); err != nil {
+		return err
+	}
+
+	file, err := os.CreateTemp(filepath.Dir(filePath), filepath.Base(filePath)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(file.Name())
+	defer file.Close()
+
+	if _, err = io.Copy(file, r); err != nil {
+		return err
+	} else if err = file.Close(); err != nil {
+		return err
+	}
+	return os.Rename(file.Name(), filePath)
+}
+
+func (local *Local) Open(name string) (File, error) {
+	return os.Open(local.path(name))
+}
+
+func (local *Local) Delete(name string) error {
+	if err := os.Remove(local.path(name)); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
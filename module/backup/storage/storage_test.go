@@ -0,0 +1,203 @@
+package storage
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/pkg/sftp"
+)
+
+// Put, read and delete file from storage
+func testStorage(store Storage, size int) error {
+	data := make([]byte, size)
+	rand.Read(data)
+
+	if err := store.Put("1/backup.zip", bytes.NewReader(data)); err != nil {
+		return fmt.Errorf("cannot put file: %s", err)
+	}
+
+	file, err := store.Open("1/backup.zip")
+	if err != nil {
+		return fmt.Errorf("cannot open file: %s", err)
+	}
+	defer file.Close()
+
+	if info, err := file.Stat(); err != nil || info.Size() != int64(size) {
+		return fmt.Errorf("invalid file size: %v", err)
+	}
+	readed, err := io.ReadAll(file)
+	if err != nil {
+		return fmt.Errorf("cannot read file: %s", err)
+	} else if !bytes.Equal(readed, data) {
+		return fmt.Errorf("file content not equal")
+	}
+
+	part := make([]byte, 100)
+	if _, err = file.ReadAt(part, int64(size/2)); err != nil || !bytes.Equal(part, data[size/2:size/2+100]) {
+		return fmt.Errorf("invalid ReadAt: %v", err)
+	}
+	if _, err = file.Seek(-100, io.SeekEnd); err != nil {
+		return err
+	} else if _, err = io.ReadFull(file, part); err != nil || !bytes.Equal(part, data[size-100:]) {
+		return fmt.Errorf("invalid read after seek: %v", err)
+	}
+
+	// Failed reader must not create file
+	if err = store.Put("1/failed.zip", io.MultiReader(bytes.NewReader(data), iotest{})); err == nil {
+		return fmt.Errorf("put with failed reader return nil error")
+	} else if _, err = store.Open("1/failed.zip"); !errors.Is(err, fs.ErrNotExist) {
+		return fmt.Errorf("failed put created file: %v", err)
+	}
+
+	if err = store.Delete("1/backup.zip"); err != nil {
+		return fmt.Errorf("cannot delete file: %s", err)
+	} else if err = store.Delete("1/backup.zip"); err != nil {
+		return fmt.Errorf("delete not exists file return error: %s", err)
+	} else if _, err = store.Open("1/backup.zip"); !errors.Is(err, fs.ErrNotExist) {
+		return fmt.Errorf("file not deleted: %v", err)
+	}
+	return nil
+}
+
+type iotest struct{}
+
+func (iotest) Read([]byte) (int, error) { return 0, errors.New("reader failed") }
+
+func TestLocal(t *testing.T) {
+	if err := testStorage(NewLocal(t.TempDir()), 1<<20); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestSFTP(t *testing.T) {
+	serverConn, clientConn := net.Pipe()
+	server := sftp.NewRequestServer(serverConn, sftp.InMemHandler())
+	go server.Serve()
+	defer server.Close()
+
+	client, err := sftp.NewClientPipe(clientConn, clientConn)
+	if err != nil {
+		t.Errorf("cannot create sftp client: %s", err)
+		return
+	}
+	remote := NewSFTP(client, "/backups")
+	defer remote.Close()
+
+	if err := testStorage(remote, 1<<20); err != nil {
+		t.Error(err)
+	}
+}
+
+// In memory S3 server, check requests signature
+type fakeS3 struct {
+	s3 *S3
+
+	mu      sync.Mutex
+	objects map[string][]byte
+	uploads map[string]map[int][]byte
+	parts   int
+}
+
+func (fake *fakeS3) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	// Sign again and compare
+	amzDate, _ := time.Parse("20060102T150405Z", r.Header.Get("X-Amz-Date"))
+	check := r.Clone(r.Context())
+	check.URL.Host = r.Host
+	fake.s3.sign(check, r.Header.Get("X-Amz-Content-Sha256"), amzDate)
+	if auth := r.Header.Get("Authorization"); auth == "" || auth != check.Header.Get("Authorization") {
+		w.WriteHeader(http.StatusForbidden)
+		fmt.Fprint(w, "<Error><Code>SignatureDoesNotMatch</Code><Message>invalid signature</Message></Error>")
+		return
+	}
+
+	fake.mu.Lock()
+	defer fake.mu.Unlock()
+	key, query := strings.TrimPrefix(r.URL.Path, "/bucket/"), r.URL.Query()
+	body, _ := io.ReadAll(r.Body)
+	switch {
+	case r.Method == http.MethodPost && query.Has("uploads"):
+		uploadID := strconv.Itoa(len(fake.uploads) + 1)
+		fake.uploads[uploadID] = map[int][]byte{}
+		fmt.Fprintf(w, "<InitiateMultipartUploadResult><UploadId>%s</UploadId></InitiateMultipartUploadResult>", uploadID)
+	case r.Method == http.MethodPut && query.Has("uploadId"):
+		partNumber, _ := strconv.Atoi(query.Get("partNumber"))
+		fake.uploads[query.Get("uploadId")][partNumber] = body
+		fake.parts++
+		w.Header().Set("ETag", fmt.Sprintf("\"part%d\"", partNumber))
+	case r.Method == http.MethodPost && query.Has("uploadId"):
+		var complete s3CompleteMultipart
+		xml.Unmarshal(body, &complete)
+		var data []byte
+		for _, part := range complete.Parts {
+			data = append(data, fake.uploads[query.Get("uploadId")][part.PartNumber]...)
+		}
+		fake.objects[key] = data
+		delete(fake.uploads, query.Get("uploadId"))
+		fmt.Fprint(w, "<CompleteMultipartUploadResult></CompleteMultipartUploadResult>")
+	case r.Method == http.MethodDelete && query.Has("uploadId"):
+		delete(fake.uploads, query.Get("uploadId"))
+		w.WriteHeader(http.StatusNoContent)
+	case r.Method == http.MethodPut:
+		fake.objects[key] = body
+	case r.Method == http.MethodDelete:
+		delete(fake.objects, key)
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		data, ok := fake.objects[key]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			if r.Method != http.MethodHead {
+				fmt.Fprint(w, "<Error><Code>NoSuchKey</Code><Message>not found</Message></Error>")
+			}
+			return
+		}
+		http.ServeContent(w, r, key, time.Now(), bytes.NewReader(data))
+	}
+}
+
+func TestS3(t *testing.T) {
+	s3 := &S3{Region: "us-east-1", Bucket: "bucket", AccessKey: "access", SecretKey: "secret", PathStyle: true, PartSize: MinPartSize}
+	checker := *s3
+	fake := &fakeS3{s3: &checker, objects: map[string][]byte{}, uploads: map[string]map[int][]byte{}}
+	server := httptest.NewServer(fake)
+	defer server.Close()
+	s3.Endpoint = server.URL
+
+	if err := testStorage(s3, int(MinPartSize*2+1024)); err != nil {
+		t.Error(err)
+		return
+	} else if fake.parts < 3 {
+		t.Errorf("multipart upload not used, parts: %d", fake.parts)
+		return
+	} else if len(fake.uploads) != 0 {
+		t.Errorf("failed multipart upload not aborted")
+		return
+	}
+
+	// Small files and keys with spaces
+	if err := s3.Put("1/Bedrock level.zip", strings.NewReader("small")); err != nil {
+		t.Errorf("cannot put small file: %s", err)
+		return
+	} else if !slices.Equal(fake.objects["1/Bedrock level.zip"], []byte("small")) {
+		t.Errorf("small file not saved")
+	}
+
+	s3.SecretKey = "invalid"
+	if err := s3.Put("1/invalid.zip", strings.NewReader("small")); err == nil {
+		t.Errorf("invalid signature accepted")
+	}
+}
@@ -0,0 +1,226 @@
+// Bedrock dedicated server files: allowlist.json and permissions.json
+package bedrock
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+
+	"sirherobrine23.com.br/go-bds/bds/module/logparser"
+	"sirherobrine23.com.br/go-bds/bds/module/runner"
+)
+
+const (
+	AllowlistFile   = "allowlist.json"
+	PermissionsFile = "permissions.json"
+
+	ReloadAllowlist   = "allowlist reload"  // Command to reload allowlist.json
+	ReloadPermissions = "permission reload" // Command to reload permissions.json
+)
+
+var (
+	ErrInvalidPermission error = errors.New("invalid permission, use visitor, member or operator")
+	ErrEntryNotExists    error = errors.New("entry not exists")
+)
+
+// Player in allowlist.json
+type AllowlistEntry struct {
+	IgnoresPlayerLimit bool   `json:"ignoresPlayerLimit"` // Player can join if server full
+	Name               string `json:"name"`               // Player gamertag
+	XUID               string `json:"xuid,omitempty"`     // Xbox user ID, filled by server on first join
+}
+
+// allowlist.json file
+type Allowlist []*AllowlistEntry
+
+// Player permission level
+type Permission string
+
+const (
+	Visitor  Permission = "visitor"
+	Member   Permission = "member"
+	Operator Permission = "operator"
+)
+
+// Player in permissions.json
+type PermissionEntry struct {
+	Permission Permission `json:"permission"` // Permission level
+	XUID       string     `json:"xuid"`       // Xbox user ID
+}
+
+// permissions.json file
+type Permissions []*PermissionEntry
+
+var filesMu sync.Mutex // Lock read and write to files
+
+// Read allowlist.json, call fn and save returned list
+func UpdateAllowlist(dir string, fn func(list Allowlist) (Allowlist, error)) (Allowlist, error) {
+	filesMu.Lock()
+	defer filesMu.Unlock()
+	list, err := ReadAllowlist(dir)
+	if err != nil {
+		return nil, err
+	} else if list, err = fn(list); err != nil {
+		return nil, err
+	}
+	return list, list.Save(dir)
+}
+
+// Read permissions.json, call fn and save returned list
+func UpdatePermissions(dir string, fn func(list Permissions) (Permissions, error)) (Permissions, error) {
+	filesMu.Lock()
+	defer filesMu.Unlock()
+	list, err := ReadPermissions(dir)
+	if err != nil {
+		return nil, err
+	} else if list, err = fn(list); err != nil {
+		return nil, err
+	}
+	return list, list.Save(dir)
+}
+
+func readJSON(name string, target any) error {
+	data, err := os.ReadFile(name)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return err
+	} else if len(strings.TrimSpace(string(data))) == 0 {
+		return nil
+	}
+	if err = json.Unmarshal(data, target); err != nil {
+		return fmt.Errorf("cannot parse %s: %s", filepath.Base(name), err)
+	}
+	return nil
+}
+
+func writeJSON(name string, body any) error {
+	data, err := json.MarshalIndent(body, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	tmp := name + ".tmp"
+	if err = os.WriteFile(tmp, append(data, '\n'), 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, name)
+}
+
+// Read allowlist.json from server directory, return empty list if not exists
+func ReadAllowlist(dir string) (Allowlist, error) {
+	list := Allowlist{}
+	if err := readJSON(filepath.Join(dir, AllowlistFile), &list); err != nil {
+		return nil, err
+	}
+	return list, nil
+}
+
+// Write allowlist.json to server directory
+func (list Allowlist) Save(dir string) error {
+	if list == nil {
+		list = Allowlist{}
+	}
+	return writeJSON(filepath.Join(dir, AllowlistFile), list)
+}
+
+// Find player by name or XUID
+func (list Allowlist) Find(nameOrXUID string) *AllowlistEntry {
+	for _, entry := range list {
+		if strings.EqualFold(entry.Name, nameOrXUID) || (entry.XUID != "" && entry.XUID == nameOrXUID) {
+			return entry
+		}
+	}
+	return nil
+}
+
+// Add or update player
+func (list Allowlist) Add(entry *AllowlistEntry) Allowlist {
+	if current := list.Find(entry.Name); current != nil {
+		current.IgnoresPlayerLimit = entry.IgnoresPlayerLimit
+		if entry.XUID != "" {
+			current.XUID = entry.XUID
+		}
+		return list
+	}
+	return append(list, entry)
+}
+
+// Remove player by name or XUID
+func (list Allowlist) Remove(nameOrXUID string) (Allowlist, error) {
+	index := slices.IndexFunc(list, func(entry *AllowlistEntry) bool {
+		return strings.EqualFold(entry.Name, nameOrXUID) || (entry.XUID != "" && entry.XUID == nameOrXUID)
+	})
+	if index == -1 {
+		return list, ErrEntryNotExists
+	}
+	return slices.Delete(list, index, index+1), nil
+}
+
+// Read permissions.json from server directory, return empty list if not exists
+func ReadPermissions(dir string) (Permissions, error) {
+	list := Permissions{}
+	if err := readJSON(filepath.Join(dir, PermissionsFile), &list); err != nil {
+		return nil, err
+	}
+	return list, nil
+}
+
+// Write permissions.json to server directory
+func (list Permissions) Save(dir string) error {
+	if list == nil {
+		list = Permissions{}
+	}
+	return writeJSON(filepath.Join(dir, PermissionsFile), list)
+}
+
+// Check if is valid permission
+func (perm Permission) Valid() bool {
+	return perm == Visitor || perm == Member || perm == Operator
+}
+
+// Set permission to XUID
+func (list Permissions) Set(xuid string, perm Permission) (Permissions, error) {
+	if !perm.Valid() {
+		return list, ErrInvalidPermission
+	}
+	for _, entry := range list {
+		if entry.XUID == xuid {
+			entry.Permission = perm
+			return list, nil
+		}
+	}
+	return append(list, &PermissionEntry{XUID: xuid, Permission: perm}), nil
+}
+
+// Remove XUID from permissions
+func (list Permissions) Remove(xuid string) (Permissions, error) {
+	index := slices.IndexFunc(list, func(entry *PermissionEntry) bool { return entry.XUID == xuid })
+	if index == -1 {
+		return list, ErrEntryNotExists
+	}
+	return slices.Delete(list, index, index+1), nil
+}
+
+// Fill XUID in allowlist.json when player join, use with [players.Tracker.OnEvent]
+func CaptureXUID(proc *runner.Process, event *logparser.Event) {
+	if event.Type != logparser.PlayerJoined || event.XUID == "" || !strings.EqualFold(proc.Server.Software, "bedrock") {
+		return
+	}
+
+	if _, err := UpdateAllowlist(proc.Dir, func(list Allowlist) (Allowlist, error) {
+		if entry := list.Find(event.Player); entry != nil && entry.XUID == "" {
+			entry.XUID = event.XUID
+			return list, nil
+		}
+		return nil, ErrEntryNotExists // Skip save
+	}); err == nil {
+		proc.SendCommand(ReloadAllowlist) // Server keep allowlist in memory
+	}
+}
@@ -0,0 +1,55 @@
+package bedrock
+
+import (
+	"testing"
+
+	"sirherobrine23.com.br/go-bds/bds/module/logparser"
+	"sirherobrine23.com.br/go-bds/bds/module/runner"
+	"sirherobrine23.com.br/go-bds/bds/module/server"
+)
+
+func TestAllowlist(t *testing.T) {
+	dir := t.TempDir()
+	if _, err := UpdateAllowlist(dir, func(list Allowlist) (Allowlist, error) {
+		return list.Add(&AllowlistEntry{Name: "Steve"}).Add(&AllowlistEntry{Name: "Alex"}), nil
+	}); err != nil {
+		t.Errorf("cannot update allowlist: %s", err)
+		return
+	}
+
+	proc := &runner.Process{Server: &server.Server{Software: "bedrock"}, Dir: dir}
+	CaptureXUID(proc, &logparser.Event{Type: logparser.PlayerJoined, Player: "Steve", XUID: "2535412345678901"})
+
+	list, err := ReadAllowlist(dir)
+	if err != nil {
+		t.Errorf("cannot read allowlist: %s", err)
+		return
+	} else if entry := list.Find("2535412345678901"); entry == nil || entry.Name != "Steve" {
+		t.Errorf("xuid not captured: %+v", list)
+		return
+	}
+
+	if list, err = list.Remove("alex"); err != nil || len(list) != 1 {
+		t.Errorf("cannot remove player: %v", err)
+	}
+}
+
+func TestPermissions(t *testing.T) {
+	list, err := Permissions{}.Set("2535412345678901", Operator)
+	if err != nil {
+		t.Errorf("cannot set permission: %s", err)
+		return
+	} else if _, err = list.Set("2535412345678901", "admin"); err != ErrInvalidPermission {
+		t.Errorf("expected invalid permission, got %v", err)
+		return
+	}
+
+	dir := t.TempDir()
+	if err = list.Save(dir); err != nil {
+		t.Errorf("cannot save permissions: %s", err)
+		return
+	}
+	if list, err = ReadPermissions(dir); err != nil || len(list) != 1 || list[0].Permission != Operator {
+		t.Errorf("invalid permissions read: %v %v", list, err)
+	}
+}
@@ -0,0 +1,199 @@
+// Parse cron expressions and find next run time
+package cron
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var macros = map[string]string{
+	"@yearly":   "0 0 1 1 *",
+	"@annually": "0 0 1 1 *",
+	"@monthly":  "0 0 1 * *",
+	"@weekly":   "0 0 * * 0",
+	"@daily":    "0 0 * * *",
+	"@midnight": "0 0 * * *",
+	"@hourly":   "0 * * * *",
+}
+
+var (
+	monthNames = []string{"jan", "feb", "mar", "apr", "may", "jun", "jul", "aug", "sep", "oct", "nov", "dec"}
+	dayNames   = []string{"sun", "mon", "tue", "wed", "thu", "fri", "sat"}
+)
+
+// Field limits and names, names index start in min
+type field struct {
+	name     string
+	min, max int
+	names    []string
+}
+
+var fields = []field{
+	{"minute", 0, 59, nil},
+	{"hour", 0, 23, nil},
+	{"day of month", 1, 31, nil},
+	{"month", 1, 12, monthNames},
+	{"day of week", 0, 7, dayNames},
+}
+
+// Cron schedule, bits set to each allowed value
+type Schedule struct {
+	Expr string `json:"expr"` // Original expression
+
+	minute, hour, dom, month, dow uint64
+	domStar, dowStar              bool
+}
+
+// Parse standard 5 fields expression "minute hour day-of-month month day-of-week" or macros like "@daily".
+//
+// Fields accept "*", lists "1,2", ranges "1-5", steps "*/15" or "1-30/2" and names to month and week days
+func Parse(expr string) (*Schedule, error) {
+	spec := strings.TrimSpace(expr)
+	if macro, ok := macros[strings.ToLower(spec)]; ok {
+		spec = macro
+	}
+
+	parts := strings.Fields(spec)
+	if len(parts) != len(fields) {
+		return nil, fmt.Errorf("invalid cron expression %q: expected 5 fields, got %d", expr, len(parts))
+	}
+
+	var values [5]uint64
+	for index, part := range parts {
+		bits, err := parseField(part, fields[index])
+		if err != nil {
+			return nil, fmt.Errorf("invalid cron expression %q: %s", expr, err)
+		}
+		values[index] = bits
+	}
+
+	// Sunday can be 0 or 7
+	if values[4]&(1<<7) != 0 {
+		values[4] |= 1
+	}
+
+	return &Schedule{
+		Expr:    expr,
+		minute:  values[0],
+		hour:    values[1],
+		dom:     values[2],
+		month:   values[3],
+		dow:     values[4],
+		domStar: strings.HasPrefix(parts[2], "*"),
+		dowStar: strings.HasPrefix(parts[4], "*"),
+	}, nil
+}
+
+func parseValue(value string, f field) (int, error) {
+	for index, name := range f.names {
+		if strings.EqualFold(value, name) {
+			return index + f.min, nil
+		}
+	}
+	num, err := strconv.Atoi(value)
+	if err != nil {
+		return 0, fmt.Errorf("invalid %s value %q", f.name, value)
+	} else if num < f.min || num > f.max {
+		return 0, fmt.Errorf("%s value %d out of range %d-%d", f.name, num, f.min, f.max)
+	}
+	return num, nil
+}
+
+func parseField(value string, f field) (uint64, error) {
+	var bits uint64
+	for _, item := range strings.Split(value, ",") {
+		rangeValue, stepValue, hasStep := strings.Cut(item, "/")
+		step := 1
+		if hasStep {
+			var err error
+			if step, err = strconv.Atoi(stepValue); err != nil || step <= 0 {
+				return 0, fmt.Errorf("invalid %s step %q", f.name, stepValue)
+			}
+		}
+
+		start, end := f.min, f.max
+		if rangeValue != "*" {
+			startValue, endValue, isRange := strings.Cut(rangeValue, "-")
+			var err error
+			if start, err = parseValue(startValue, f); err != nil {
+				return 0, err
+			}
+			end = start
+			if isRange {
+				if end, err = parseValue(endValue, f); err != nil {
+					return 0, err
+				} else if end < start {
+					return 0, fmt.Errorf("invalid %s range %q", f.name, rangeValue)
+				}
+			} else if hasStep {
+				end = f.max // "5/10" is "5-max/10"
+			}
+		}
+
+		for num := start; num <= end; num += step {
+			bits |= 1 << num
+		}
+	}
+	return bits, nil
+}
+
+// Check day match, if both day of month and day of week are restricted any can match like vixie cron
+func (sch *Schedule) dayMatch(t time.Time) bool {
+	domMatch := sch.dom&(1<<t.Day()) != 0
+	dowMatch := sch.dow&(1<<t.Weekday()) != 0
+	if sch.domStar || sch.dowStar {
+		return domMatch && dowMatch
+	}
+	return domMatch || dowMatch
+}
+
+// Next time after t matching schedule, in t location. Return zero time if not found in 5 years
+func (sch *Schedule) Next(t time.Time) time.Time {
+	t = t.Truncate(time.Minute).Add(time.Minute)
+	limit := t.AddDate(5, 0, 0)
+	for t.Before(limit) {
+		if sch.month&(1<<t.Month()) == 0 {
+			t = time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, t.Location())
+			continue
+		} else if !sch.dayMatch(t) {
+			t = time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, t.Location())
+			continue
+		} else if sch.hour&(1<<t.Hour()) == 0 {
+			t = time.Date(t.Year(), t.Month(), t.Day(), t.Hour()+1, 0, 0, 0, t.Location())
+			continue
+		} else if sch.minute&(1<<t.Minute()) == 0 {
+			t = t.Add(time.Minute)
+			continue
+		}
+		return t
+	}
+	return time.Time{}
+}
+
+// Last time at or before t matching schedule, zero time if not found in 5 years
+func (sch *Schedule) Prev(t time.Time) time.Time {
+	t = t.Truncate(time.Minute)
+	limit := t.AddDate(-5, 0, 0)
+	for t.After(limit) {
+		if sch.month&(1<<t.Month()) == 0 {
+			t = time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, t.Location()).Add(-time.Minute)
+			continue
+		} else if !sch.dayMatch(t) {
+			t = time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location()).Add(-time.Minute)
+			continue
+		} else if sch.hour&(1<<t.Hour()) == 0 {
+			t = time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), 0, 0, 0, t.Location()).Add(-time.Minute)
+			continue
+		} else if sch.minute&(1<<t.Minute()) == 0 {
+			t = t.Add(-time.Minute)
+			continue
+		}
+		return t
+	}
+	return time.Time{}
+}
+
+// Return expression
+func (sch *Schedule) String() string { return sch.Expr }
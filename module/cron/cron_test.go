@@ -0,0 +1,42 @@
+package cron
+
+import (
+	"testing"
+	"time"
+)
+
+func TestNext(t *testing.T) {
+	base := time.Date(2024, time.June, 12, 10, 30, 15, 0, time.UTC) // Wednesday
+	for _, test := range []struct {
+		Expr string
+		Next time.Time
+	}{
+		{"* * * * *", time.Date(2024, time.June, 12, 10, 31, 0, 0, time.UTC)},
+		{"*/15 * * * *", time.Date(2024, time.June, 12, 10, 45, 0, 0, time.UTC)},
+		{"0 3 * * *", time.Date(2024, time.June, 13, 3, 0, 0, 0, time.UTC)},
+		{"@daily", time.Date(2024, time.June, 13, 0, 0, 0, 0, time.UTC)},
+		{"0 0 * * sun", time.Date(2024, time.June, 16, 0, 0, 0, 0, time.UTC)},
+		{"0 0 * * 7", time.Date(2024, time.June, 16, 0, 0, 0, 0, time.UTC)},
+		{"0 12 1 jan-mar *", time.Date(2025, time.January, 1, 12, 0, 0, 0, time.UTC)},
+		{"0 0 13 * fri", time.Date(2024, time.June, 13, 0, 0, 0, 0, time.UTC)},
+		{"30 10 12 6 *", time.Date(2025, time.June, 12, 10, 30, 0, 0, time.UTC)},
+		{"0 0 29 2 *", time.Date(2028, time.February, 29, 0, 0, 0, 0, time.UTC)},
+	} {
+		sch, err := Parse(test.Expr)
+		if err != nil {
+			t.Errorf("cannot parse %q: %s", test.Expr, err)
+			continue
+		}
+		if next := sch.Next(base); !next.Equal(test.Next) {
+			t.Errorf("%q: next %s, expected %s", test.Expr, next, test.Next)
+		} else if prev := sch.Prev(test.Next); !prev.Equal(test.Next) {
+			t.Errorf("%q: prev %s, expected %s", test.Expr, prev, test.Next)
+		}
+	}
+
+	for _, expr := range []string{"", "* * * *", "60 * * * *", "* * 0 * *", "*/0 * * * *", "5-1 * * * *", "* * * foo *"} {
+		if _, err := Parse(expr); err == nil {
+			t.Errorf("invalid expression %q parsed", expr)
+		}
+	}
+}
@@ -20,6 +20,10 @@ var SQL embed.FS
 var (
 	ErrServerNotExists error = errors.New("server not exists")
 	ErrUserNotExists   error = errors.New("user not exists")
+	ErrPlayerNotExists error = errors.New("player not exists")
+	ErrBackupNotExists error = errors.New("backup not exists")
+	ErrChunkNotExists  error = errors.New("backup chunk not exists")
+	ErrTaskNotExists   error = errors.New("task not exists")
 
 	DefaultCookieTime = time.Hour * 24 * 7 * 30 * 15
 )
@@ -48,18 +52,62 @@ type User interface {
 	DeleteToken(token *users.Token) error    // Delete token
 
 	UpdateToken(token *users.Token, newPerms ...users.TokenPermission) error // Update permissions to token
+
+	Notifications(userID int64) ([]*users.Notification, error) // Get user notifications, newest first
+	CreateNotification(notification *users.Notification) error // Add notification to user
+	DeleteNotification(notification *users.Notification) error // Remove user notification
 }
 
 // Server maneger
 type Server interface {
 	Server(ID int64) (*server.Server, error)                       // Get server by ID
 	UserServers(user *users.User) ([]*server.Server, error)        // get all server to user
+	Servers() ([]*server.Server, error)                            // Get all servers in instance
 	ServerFriends(serverID int64) ([]*server.ServerFriends, error) // Get server friends by server ID
 	ServerBackups(serverID int64) ([]*server.ServerBackup, error)  // Get server backups by server ID
+	ServerBackup(ID int64) (*server.ServerBackup, error)           // Get backup by ID
+	StorageBackups(storage string) ([]*server.ServerBackup, error) // Get backups of all servers saved in storage
 
 	CreateServer(user *users.User, Server *server.Server) (*server.Server, error) // Create new server
 	UpdateServer(Server *server.Server) error                                     // Update server
+	DeleteServer(Server *server.Server) error                                     // Remove server
+
+	CreateBackup(backup *server.ServerBackup) (*server.ServerBackup, error) // Insert new backup
+	DeleteBackup(backup *server.ServerBackup) error                         // Remove backup
+
+	BackupConfig(serverID int64) (*server.BackupConfig, error)         // Get server backup config, return empty config if not set
+	SetBackupConfig(config *server.BackupConfig) error                 // Create or update server backup config
+	BackupJobs(serverID int64, limit int) ([]*server.BackupJob, error) // Get backup tasks runs, newest first
+	AddBackupJob(job *server.BackupJob) error                          // Record backup task run
+
+	BackupChunks(storage string) ([]*server.BackupChunk, error)           // Get all chunks in storage
+	BackupChunk(storage, hash string) (*server.BackupChunk, error)        // Get chunk by hash
+	RefBackupChunks(chunks ...*server.BackupChunk) error                  // Add one reference to chunks, create chunks not exists
+	UnrefBackupChunks(storage string, hashes ...string) ([]string, error) // Remove one reference to chunks, return chunks without references removed from database
 
 	AddNewFriend(Server *server.Server, perm server.ServerPermissions, friends ...users.User) error // Add new users to server friends list
 	RemoveFriend(Server *server.Server, friends ...users.User) error                                // Remove friends from server
+
+	Tasks(serverID int64) ([]*server.Task, error)                // Get server scheduled tasks
+	EnabledTasks() ([]*server.Task, error)                       // Get enabled tasks of all servers
+	Task(ID int64) (*server.Task, error)                         // Get task by ID
+	CreateTask(task *server.Task) (*server.Task, error)          // Insert new task
+	UpdateTask(task *server.Task) error                          // Update task, last run is not changed
+	SetTaskLastRun(task *server.Task, lastRun time.Time) error   // Update task last run
+	DeleteTask(task *server.Task) error                          // Remove task and history
+	TaskRuns(taskID int64, limit int) ([]*server.TaskRun, error) // Get task history, newest first
+	AddTaskRun(run *server.TaskRun) error                        // Record task run
+
+	UpdatePolicy(serverID int64) (*server.UpdatePolicy, error) // Get server update policy, default to server version if not set
+	UpdatePolicies() ([]*server.UpdatePolicy, error)           // Get not pinned update policies of all servers
+	SetUpdatePolicy(policy *server.UpdatePolicy) error         // Create or replace server update policy
+
+	LogRetention(serverID int64) (*server.LogRetention, error) // Get server logs retention, return empty policy if not set
+	SetLogRetention(policy *server.LogRetention) error         // Create or update server logs retention
+
+	Players(serverID int64) ([]*server.Player, error)                               // Get all players seen in server
+	Player(serverID int64, username string) (*server.Player, error)                 // Get player by username
+	UpdatePlayer(player *server.Player) error                                       // Create or update player
+	PlayerHistory(serverID int64, username string) ([]*server.PlayerHistory, error) // Get player history, newest first
+	AddPlayerHistory(history *server.PlayerHistory) error                           // Add new entry to player history
 }
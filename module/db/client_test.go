@@ -1,6 +1,8 @@
 package db
 
 import (
+	"database/sql"
+	"path/filepath"
 	"testing"
 
 	"sirherobrine23.com.br/go-bds/bds/module/server"
@@ -29,3 +31,100 @@ func TestDatabase(t *testing.T) {
 
 	t.Logf("Server ID: %d", server.ID)
 }
+
+func TestPlayers(t *testing.T) {
+	client, err := NewSqliteConnection(":memory:")
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	*passwordToEncrypt = "testBackend"
+	user, err := client.CreateNewUser(&users.User{Username: "players"}, &users.Password{Password: "test1234"})
+	if err != nil {
+		t.Errorf("cannot make new user in database: %s", err)
+		return
+	}
+
+	mcServer, err := client.CreateServer(user, &server.Server{Software: "bedrock", Version: "latest", Owner: user.UserID})
+	if err != nil {
+		t.Errorf("cannot make new server in database: %s", err)
+		return
+	}
+
+	if err = client.UpdatePlayer(&server.Player{ServerID: mcServer.ID, Username: "Steve", XUID: "2535412345678901"}); err != nil {
+		t.Errorf("cannot insert player: %s", err)
+		return
+	} else if err = client.UpdatePlayer(&server.Player{ServerID: mcServer.ID, Username: "Steve", Operator: true}); err != nil {
+		t.Errorf("cannot update player: %s", err)
+		return
+	}
+
+	player, err := client.Player(mcServer.ID, "Steve")
+	if err != nil {
+		t.Errorf("cannot get player: %s", err)
+		return
+	} else if player.XUID != "2535412345678901" || !player.Operator {
+		t.Errorf("player not updated: %+v", player)
+		return
+	}
+
+	if err = client.AddPlayerHistory(&server.PlayerHistory{ServerID: mcServer.ID, Username: "Steve", Action: server.PlayerOp}); err != nil {
+		t.Errorf("cannot add history: %s", err)
+		return
+	}
+	if history, err := client.PlayerHistory(mcServer.ID, "Steve"); err != nil || len(history) != 1 {
+		t.Errorf("invalid history: %v %v", history, err)
+	}
+}
+
+// Tables created by old versions
+const oldTables = `CREATE TABLE "server" (
+  id INTEGER PRIMARY KEY AUTOINCREMENT,
+  "owner" INTEGER REFERENCES user (id) ON DELETE CASCADE,
+  "name" TEXT NOT NULL,
+  software VARCHAR(128) NOT NULL,
+  "version" TEXT NOT NULL,
+  create_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+  update_at DATETIME DEFAULT CURRENT_TIMESTAMP
+);
+INSERT INTO "server" ("owner", "name", software, "version") VALUES (1, 'old', 'bedrock', '1.21.50');
+CREATE TABLE "backups" (
+  id INTEGER PRIMARY KEY AUTOINCREMENT,
+  server_id INTEGER REFERENCES server (id) ON DELETE CASCADE,
+  uuid TEXT NOT NULL,
+  software VARCHAR(128) NOT NULL,
+  "version" TEXT NOT NULL,
+  create_at DATETIME DEFAULT CURRENT_TIMESTAMP
+);`
+
+func TestMigrate(t *testing.T) {
+	name := filepath.Join(t.TempDir(), "old.db")
+	old, err := sql.Open("sqlite3", name)
+	if err != nil {
+		t.Error(err)
+		return
+	} else if _, err = old.Exec(oldTables); err != nil {
+		t.Errorf("cannot create old tables: %s", err)
+		return
+	}
+	old.Close()
+
+	// Open two times to check migration is idempotent
+	for range 2 {
+		client, err := NewSqliteConnection(name)
+		if err != nil {
+			t.Errorf("cannot migrate database: %s", err)
+			return
+		}
+		defer client.(*Sqlite).Connection.Close()
+		if _, err = client.CreateBackup(&server.ServerBackup{ServerID: 1, UUID: "uuid", Software: "bedrock", Version: "1.21.50", Storage: "s3", Encrypted: true, Incremental: true}); err != nil {
+			t.Errorf("cannot insert backup in migrated table: %s", err)
+			return
+		}
+		if servers, err := client.Servers(); err != nil || len(servers) != 1 {
+			t.Errorf("server columns not migrated: %v", err)
+			return
+		}
+	}
+}
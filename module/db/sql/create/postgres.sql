@@ -34,6 +34,10 @@ CREATE TABLE IF NOT EXISTS "server" (
   "name" TEXT NOT NULL,
   software VARCHAR(128) NOT NULL,
   "version" TEXT NOT NULL,
+  port INTEGER NOT NULL DEFAULT 0,
+  port_v6 INTEGER NOT NULL DEFAULT 0,
+  rcon_port INTEGER NOT NULL DEFAULT 0,
+  rcon_password TEXT NOT NULL DEFAULT '',
   create_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
   update_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
 );
@@ -50,6 +54,96 @@ CREATE TABLE IF NOT EXISTS "backups" (
   uuid TEXT NOT NULL,
   software VARCHAR(128) NOT NULL,
   "version" TEXT NOT NULL,
+  "storage" VARCHAR(128) NOT NULL DEFAULT '',
+  encrypted BOOLEAN NOT NULL DEFAULT FALSE,
+  incremental BOOLEAN NOT NULL DEFAULT FALSE,
+  safety BOOLEAN NOT NULL DEFAULT FALSE,
+  create_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+CREATE TABLE IF NOT EXISTS "backups_config" (
+  server_id BIGINT UNIQUE REFERENCES server (id) ON DELETE CASCADE,
+  "storage" VARCHAR(128) NOT NULL DEFAULT '',
+  incremental BOOLEAN NOT NULL DEFAULT FALSE,
+  keep_last INTEGER NOT NULL DEFAULT 0,
+  keep_daily INTEGER NOT NULL DEFAULT 0,
+  keep_weekly INTEGER NOT NULL DEFAULT 0
+);
+CREATE TABLE IF NOT EXISTS "backups_jobs" (
+  id BIGSERIAL PRIMARY KEY,
+  server_id BIGINT REFERENCES server (id) ON DELETE CASCADE,
+  backup_id BIGINT,
+  status VARCHAR(32) NOT NULL,
+  error TEXT NOT NULL DEFAULT '',
+  removed INTEGER NOT NULL DEFAULT 0,
+  start_at TIMESTAMP NOT NULL,
+  end_at TIMESTAMP NOT NULL
+);
+CREATE TABLE IF NOT EXISTS "backups_chunks" (
+  "storage" VARCHAR(128) NOT NULL,
+  hash VARCHAR(64) NOT NULL,
+  size BIGINT NOT NULL,
+  refs BIGINT NOT NULL DEFAULT 0,
+  UNIQUE ("storage", hash)
+);
+CREATE TABLE IF NOT EXISTS "tasks" (
+  id BIGSERIAL PRIMARY KEY,
+  server_id BIGINT REFERENCES server (id) ON DELETE CASCADE,
+  name TEXT NOT NULL,
+  task_type VARCHAR(32) NOT NULL,
+  schedule TEXT NOT NULL,
+  command TEXT NOT NULL DEFAULT '',
+  countdown INTEGER NOT NULL DEFAULT 0,
+  missed VARCHAR(32) NOT NULL DEFAULT 'skip',
+  enabled BOOLEAN NOT NULL DEFAULT TRUE,
+  last_run TIMESTAMP,
+  create_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+CREATE TABLE IF NOT EXISTS "tasks_runs" (
+  id BIGSERIAL PRIMARY KEY,
+  task_id BIGINT REFERENCES tasks (id) ON DELETE CASCADE,
+  server_id BIGINT REFERENCES server (id) ON DELETE CASCADE,
+  status VARCHAR(32) NOT NULL,
+  error TEXT NOT NULL DEFAULT '',
+  start_at TIMESTAMP NOT NULL,
+  end_at TIMESTAMP NOT NULL
+);
+CREATE TABLE IF NOT EXISTS "update_policy" (
+  server_id BIGINT UNIQUE REFERENCES server (id) ON DELETE CASCADE,
+  channel VARCHAR(32) NOT NULL,
+  auto_upgrade BOOLEAN NOT NULL DEFAULT FALSE,
+  window_start VARCHAR(5) NOT NULL DEFAULT '',
+  window_end VARCHAR(5) NOT NULL DEFAULT '',
+  max_players INTEGER NOT NULL DEFAULT 0,
+  available TEXT NOT NULL DEFAULT '',
+  failed TEXT NOT NULL DEFAULT '',
+  last_error TEXT NOT NULL DEFAULT '',
+  checked_at TIMESTAMP
+);
+CREATE TABLE IF NOT EXISTS "logs_retention" (
+  server_id BIGINT UNIQUE REFERENCES server (id) ON DELETE CASCADE,
+  max_age BIGINT NOT NULL DEFAULT 0,
+  max_runs INTEGER NOT NULL DEFAULT 0
+);
+CREATE TABLE IF NOT EXISTS "players" (
+  id BIGSERIAL PRIMARY KEY,
+  server_id BIGINT REFERENCES server (id) ON DELETE CASCADE,
+  username TEXT NOT NULL,
+  xuid TEXT NOT NULL DEFAULT '',
+  uuid TEXT NOT NULL DEFAULT '',
+  banned BOOLEAN NOT NULL DEFAULT FALSE,
+  operator BOOLEAN NOT NULL DEFAULT FALSE,
+  whitelisted BOOLEAN NOT NULL DEFAULT FALSE,
+  first_seen TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+  last_seen TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+  UNIQUE(server_id, username)
+);
+CREATE TABLE IF NOT EXISTS "players_history" (
+  id BIGSERIAL PRIMARY KEY,
+  server_id BIGINT REFERENCES server (id) ON DELETE CASCADE,
+  username TEXT NOT NULL,
+  "action" VARCHAR(32) NOT NULL,
+  reason TEXT NOT NULL DEFAULT '',
+  "user_id" BIGINT NOT NULL DEFAULT 0,
   create_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
 );
 CREATE TABLE IF NOT EXISTS "runner" (
@@ -57,4 +151,11 @@ CREATE TABLE IF NOT EXISTS "runner" (
   is_global BOOLEAN NOT NULL,
   is_local BOOLEAN NOT NULL,
   "user_id" BIGINT REFERENCES public.user(id) ON DELETE CASCADE
+);
+CREATE TABLE IF NOT EXISTS "notifications" (
+  id BIGSERIAL PRIMARY KEY,
+  "user_id" BIGINT REFERENCES public.user(id) ON DELETE CASCADE,
+  server_id BIGINT REFERENCES server (id) ON DELETE CASCADE,
+  message TEXT NOT NULL,
+  create_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
 );
\ No newline at end of file
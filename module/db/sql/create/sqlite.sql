@@ -33,6 +33,10 @@ CREATE TABLE IF NOT EXISTS "server" (
   "name" TEXT NOT NULL,
   software VARCHAR(128) NOT NULL,
   "version" TEXT NOT NULL,
+  port INTEGER NOT NULL DEFAULT 0,
+  port_v6 INTEGER NOT NULL DEFAULT 0,
+  rcon_port INTEGER NOT NULL DEFAULT 0,
+  rcon_password TEXT NOT NULL DEFAULT '',
   create_at DATETIME DEFAULT CURRENT_TIMESTAMP,
   update_at DATETIME DEFAULT CURRENT_TIMESTAMP
 );
@@ -49,6 +53,96 @@ CREATE TABLE IF NOT EXISTS "backups" (
   uuid TEXT NOT NULL,
   software VARCHAR(128) NOT NULL,
   "version" TEXT NOT NULL,
+  "storage" VARCHAR(128) NOT NULL DEFAULT '',
+  encrypted BOOLEAN NOT NULL DEFAULT FALSE,
+  incremental BOOLEAN NOT NULL DEFAULT FALSE,
+  safety BOOLEAN NOT NULL DEFAULT FALSE,
+  create_at DATETIME DEFAULT CURRENT_TIMESTAMP
+);
+CREATE TABLE IF NOT EXISTS "backups_config" (
+  server_id INTEGER UNIQUE REFERENCES server (id) ON DELETE CASCADE,
+  "storage" VARCHAR(128) NOT NULL DEFAULT '',
+  incremental BOOLEAN NOT NULL DEFAULT FALSE,
+  keep_last INTEGER NOT NULL DEFAULT 0,
+  keep_daily INTEGER NOT NULL DEFAULT 0,
+  keep_weekly INTEGER NOT NULL DEFAULT 0
+);
+CREATE TABLE IF NOT EXISTS "backups_jobs" (
+  id INTEGER PRIMARY KEY AUTOINCREMENT,
+  server_id INTEGER REFERENCES server (id) ON DELETE CASCADE,
+  backup_id INTEGER,
+  status VARCHAR(32) NOT NULL,
+  error TEXT NOT NULL DEFAULT '',
+  removed INTEGER NOT NULL DEFAULT 0,
+  start_at DATETIME NOT NULL,
+  end_at DATETIME NOT NULL
+);
+CREATE TABLE IF NOT EXISTS "backups_chunks" (
+  "storage" VARCHAR(128) NOT NULL,
+  hash VARCHAR(64) NOT NULL,
+  size INTEGER NOT NULL,
+  refs INTEGER NOT NULL DEFAULT 0,
+  UNIQUE ("storage", hash)
+);
+CREATE TABLE IF NOT EXISTS "tasks" (
+  id INTEGER PRIMARY KEY AUTOINCREMENT,
+  server_id INTEGER REFERENCES server (id) ON DELETE CASCADE,
+  name TEXT NOT NULL,
+  task_type VARCHAR(32) NOT NULL,
+  schedule TEXT NOT NULL,
+  command TEXT NOT NULL DEFAULT '',
+  countdown INTEGER NOT NULL DEFAULT 0,
+  missed VARCHAR(32) NOT NULL DEFAULT 'skip',
+  enabled BOOLEAN NOT NULL DEFAULT TRUE,
+  last_run DATETIME,
+  create_at DATETIME DEFAULT CURRENT_TIMESTAMP
+);
+CREATE TABLE IF NOT EXISTS "tasks_runs" (
+  id INTEGER PRIMARY KEY AUTOINCREMENT,
+  task_id INTEGER REFERENCES tasks (id) ON DELETE CASCADE,
+  server_id INTEGER REFERENCES server (id) ON DELETE CASCADE,
+  status VARCHAR(32) NOT NULL,
+  error TEXT NOT NULL DEFAULT '',
+  start_at DATETIME NOT NULL,
+  end_at DATETIME NOT NULL
+);
+CREATE TABLE IF NOT EXISTS "update_policy" (
+  server_id INTEGER UNIQUE REFERENCES server (id) ON DELETE CASCADE,
+  channel VARCHAR(32) NOT NULL,
+  auto_upgrade BOOLEAN NOT NULL DEFAULT FALSE,
+  window_start VARCHAR(5) NOT NULL DEFAULT '',
+  window_end VARCHAR(5) NOT NULL DEFAULT '',
+  max_players INTEGER NOT NULL DEFAULT 0,
+  available TEXT NOT NULL DEFAULT '',
+  failed TEXT NOT NULL DEFAULT '',
+  last_error TEXT NOT NULL DEFAULT '',
+  checked_at DATETIME
+);
+CREATE TABLE IF NOT EXISTS "logs_retention" (
+  server_id INTEGER UNIQUE REFERENCES server (id) ON DELETE CASCADE,
+  max_age INTEGER NOT NULL DEFAULT 0,
+  max_runs INTEGER NOT NULL DEFAULT 0
+);
+CREATE TABLE IF NOT EXISTS "players" (
+  id INTEGER PRIMARY KEY AUTOINCREMENT,
+  server_id INTEGER REFERENCES server (id) ON DELETE CASCADE,
+  username TEXT NOT NULL,
+  xuid TEXT NOT NULL DEFAULT '',
+  uuid TEXT NOT NULL DEFAULT '',
+  banned BOOLEAN NOT NULL DEFAULT FALSE,
+  operator BOOLEAN NOT NULL DEFAULT FALSE,
+  whitelisted BOOLEAN NOT NULL DEFAULT FALSE,
+  first_seen DATETIME DEFAULT CURRENT_TIMESTAMP,
+  last_seen DATETIME DEFAULT CURRENT_TIMESTAMP,
+  UNIQUE(server_id, username)
+);
+CREATE TABLE IF NOT EXISTS "players_history" (
+  id INTEGER PRIMARY KEY AUTOINCREMENT,
+  server_id INTEGER REFERENCES server (id) ON DELETE CASCADE,
+  username TEXT NOT NULL,
+  "action" VARCHAR(32) NOT NULL,
+  reason TEXT NOT NULL DEFAULT '',
+  "user_id" INTEGER NOT NULL DEFAULT 0,
   create_at DATETIME DEFAULT CURRENT_TIMESTAMP
 );
 CREATE TABLE IF NOT EXISTS "runner" (
@@ -56,4 +150,11 @@ CREATE TABLE IF NOT EXISTS "runner" (
   is_global BOOLEAN NOT NULL,
   is_local BOOLEAN NOT NULL,
   "user_id" INTEGER REFERENCES user (id) ON DELETE CASCADE
+);
+CREATE TABLE IF NOT EXISTS "notifications" (
+  id INTEGER PRIMARY KEY AUTOINCREMENT,
+  "user_id" INTEGER REFERENCES user (id) ON DELETE CASCADE,
+  server_id INTEGER REFERENCES server (id) ON DELETE CASCADE,
+  message TEXT NOT NULL,
+  create_at DATETIME DEFAULT CURRENT_TIMESTAMP
 );
\ No newline at end of file
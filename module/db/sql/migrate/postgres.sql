@@ -0,0 +1,9 @@
+-- Columns added after tables creation, applied to old databases
+ALTER TABLE "backups" ADD COLUMN IF NOT EXISTS "storage" VARCHAR(128) NOT NULL DEFAULT '';
+ALTER TABLE "backups" ADD COLUMN IF NOT EXISTS encrypted BOOLEAN NOT NULL DEFAULT FALSE;
+ALTER TABLE "backups" ADD COLUMN IF NOT EXISTS incremental BOOLEAN NOT NULL DEFAULT FALSE;
+ALTER TABLE "backups" ADD COLUMN IF NOT EXISTS safety BOOLEAN NOT NULL DEFAULT FALSE;
+ALTER TABLE "server" ADD COLUMN IF NOT EXISTS port INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE "server" ADD COLUMN IF NOT EXISTS port_v6 INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE "server" ADD COLUMN IF NOT EXISTS rcon_port INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE "server" ADD COLUMN IF NOT EXISTS rcon_password TEXT NOT NULL DEFAULT '';
@@ -0,0 +1,9 @@
+-- Columns added after tables creation, applied to old databases. Duplicated columns errors are ignored
+ALTER TABLE "backups" ADD COLUMN "storage" VARCHAR(128) NOT NULL DEFAULT '';
+ALTER TABLE "backups" ADD COLUMN encrypted BOOLEAN NOT NULL DEFAULT FALSE;
+ALTER TABLE "backups" ADD COLUMN incremental BOOLEAN NOT NULL DEFAULT FALSE;
+ALTER TABLE "backups" ADD COLUMN safety BOOLEAN NOT NULL DEFAULT FALSE;
+ALTER TABLE "server" ADD COLUMN port INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE "server" ADD COLUMN port_v6 INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE "server" ADD COLUMN rcon_port INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE "server" ADD COLUMN rcon_password TEXT NOT NULL DEFAULT '';
@@ -0,0 +1,3 @@
+SELECT storage, hash, size, refs
+FROM backups_chunks
+WHERE storage = $1 AND hash = $2
\ No newline at end of file
@@ -0,0 +1,2 @@
+DELETE FROM backups_chunks
+WHERE storage = $1 AND hash = $2 AND refs <= 0;
\ No newline at end of file
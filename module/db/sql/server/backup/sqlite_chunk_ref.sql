@@ -0,0 +1,3 @@
+INSERT INTO backups_chunks(storage, hash, size, refs)
+VALUES ($1, $2, $3, 1)
+ON CONFLICT(storage, hash) DO UPDATE SET refs = backups_chunks.refs + 1;
\ No newline at end of file
@@ -0,0 +1,4 @@
+UPDATE backups_chunks
+SET refs = refs - 1
+WHERE storage = $1 AND hash = $2
+RETURNING refs;
\ No newline at end of file
@@ -0,0 +1,3 @@
+SELECT server_id, storage, incremental, keep_last, keep_daily, keep_weekly
+FROM backups_config
+WHERE server_id = $1
\ No newline at end of file
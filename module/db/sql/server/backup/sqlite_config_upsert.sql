@@ -0,0 +1,4 @@
+INSERT INTO backups_config(server_id, storage, incremental, keep_last, keep_daily, keep_weekly)
+VALUES ($1, $2, $3, $4, $5, $6)
+ON CONFLICT(server_id) DO UPDATE SET storage = excluded.storage, incremental = excluded.incremental,
+  keep_last = excluded.keep_last, keep_daily = excluded.keep_daily, keep_weekly = excluded.keep_weekly;
\ No newline at end of file
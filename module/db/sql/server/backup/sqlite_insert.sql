@@ -0,0 +1,2 @@
+INSERT INTO backups(server_id, uuid, software, version, storage, encrypted, incremental, safety)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8);
\ No newline at end of file
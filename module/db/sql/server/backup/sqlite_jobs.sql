@@ -0,0 +1,5 @@
+SELECT id, server_id, backup_id, status, error, removed, start_at, end_at
+FROM backups_jobs
+WHERE server_id = $1
+ORDER BY start_at DESC, id DESC
+LIMIT $2
\ No newline at end of file
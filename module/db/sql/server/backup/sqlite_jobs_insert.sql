@@ -0,0 +1,2 @@
+INSERT INTO backups_jobs(server_id, backup_id, status, error, removed, start_at, end_at)
+VALUES ($1, $2, $3, $4, $5, $6, $7);
\ No newline at end of file
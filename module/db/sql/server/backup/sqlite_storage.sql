@@ -0,0 +1,3 @@
+SELECT id, server_id, uuid, software, version, storage, encrypted, incremental, safety, create_at
+FROM backups
+WHERE storage = $1
\ No newline at end of file
@@ -1,3 +0,0 @@
-SELECT id, server_id, uuid, software, version, create_at
-FROM backups
-WHERE server_id = $1
\ No newline at end of file
@@ -0,0 +1,3 @@
+SELECT server_id, max_age, max_runs
+FROM logs_retention
+WHERE server_id = $1
\ No newline at end of file
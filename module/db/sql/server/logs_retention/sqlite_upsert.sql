@@ -0,0 +1,3 @@
+INSERT INTO logs_retention(server_id, max_age, max_runs)
+VALUES ($1, $2, $3)
+ON CONFLICT(server_id) DO UPDATE SET max_age = excluded.max_age, max_runs = excluded.max_runs;
\ No newline at end of file
@@ -0,0 +1,4 @@
+SELECT id, server_id, username, xuid, uuid, banned, operator, whitelisted, first_seen, last_seen
+FROM players
+WHERE server_id = $1
+ORDER BY last_seen DESC
\ No newline at end of file
@@ -0,0 +1,4 @@
+SELECT id, server_id, username, action, reason, user_id, create_at
+FROM players_history
+WHERE server_id = $1 AND username = $2
+ORDER BY create_at DESC, id DESC
\ No newline at end of file
@@ -0,0 +1,2 @@
+INSERT INTO players_history(server_id, username, action, reason, user_id)
+VALUES ($1, $2, $3, $4, $5);
\ No newline at end of file
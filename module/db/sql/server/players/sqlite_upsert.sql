@@ -0,0 +1,10 @@
+-- Keep first_seen and known ids if new value is empty
+INSERT INTO players(server_id, username, xuid, uuid, banned, operator, whitelisted, last_seen)
+VALUES ($1, $2, $3, $4, $5, $6, $7, current_timestamp)
+ON CONFLICT(server_id, username) DO UPDATE SET
+  xuid = CASE WHEN excluded.xuid = '' THEN players.xuid ELSE excluded.xuid END,
+  uuid = CASE WHEN excluded.uuid = '' THEN players.uuid ELSE excluded.uuid END,
+  banned = excluded.banned,
+  operator = excluded.operator,
+  whitelisted = excluded.whitelisted,
+  last_seen = current_timestamp;
\ No newline at end of file
@@ -0,0 +1,3 @@
+SELECT id, server_id, username, xuid, uuid, banned, operator, whitelisted, first_seen, last_seen
+FROM players
+WHERE server_id = $1 AND username = $2
\ No newline at end of file
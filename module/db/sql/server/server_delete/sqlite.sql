@@ -0,0 +1,2 @@
+DELETE FROM server
+WHERE id = $1;
\ No newline at end of file
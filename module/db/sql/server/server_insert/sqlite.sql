@@ -1,2 +1,2 @@
-INSERT INTO server(owner, name, software, version)
-VALUES ($1, $2, $3, $4);
\ No newline at end of file
+INSERT INTO server(owner, name, software, version, port, port_v6, rcon_port, rcon_password)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8);
\ No newline at end of file
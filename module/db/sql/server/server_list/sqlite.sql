@@ -3,6 +3,10 @@ SELECT id,
   owner,
   software,
   version,
+  port,
+  port_v6,
+  rcon_port,
+  rcon_password,
   create_at,
   update_at
 FROM server
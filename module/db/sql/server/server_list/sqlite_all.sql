@@ -0,0 +1,3 @@
+SELECT id, name, owner, software, version, port, port_v6, rcon_port, rcon_password, create_at, update_at
+FROM server
+ORDER BY id;
\ No newline at end of file
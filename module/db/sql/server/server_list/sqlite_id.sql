@@ -1,3 +1,3 @@
-SELECT id, name, owner, software, version, create_at, update_at
+SELECT id, name, owner, software, version, port, port_v6, rcon_port, rcon_password, create_at, update_at
 FROM server
 WHERE id = $1
\ No newline at end of file
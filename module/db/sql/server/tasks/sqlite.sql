@@ -0,0 +1,4 @@
+SELECT id, server_id, name, task_type, schedule, command, countdown, missed, enabled, last_run, create_at
+FROM tasks
+WHERE server_id = $1
+ORDER BY id
\ No newline at end of file
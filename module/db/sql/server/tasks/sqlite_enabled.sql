@@ -0,0 +1,3 @@
+SELECT id, server_id, name, task_type, schedule, command, countdown, missed, enabled, last_run, create_at
+FROM tasks
+WHERE enabled = TRUE
\ No newline at end of file
@@ -0,0 +1,2 @@
+INSERT INTO tasks(server_id, name, task_type, schedule, command, countdown, missed, enabled)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8);
\ No newline at end of file
@@ -0,0 +1,3 @@
+UPDATE tasks
+SET last_run = $1
+WHERE id = $2;
\ No newline at end of file
@@ -0,0 +1,5 @@
+SELECT id, task_id, server_id, status, error, start_at, end_at
+FROM tasks_runs
+WHERE task_id = $1
+ORDER BY start_at DESC, id DESC
+LIMIT $2
\ No newline at end of file
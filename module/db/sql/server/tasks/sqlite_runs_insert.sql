@@ -0,0 +1,2 @@
+INSERT INTO tasks_runs(task_id, server_id, status, error, start_at, end_at)
+VALUES ($1, $2, $3, $4, $5, $6);
\ No newline at end of file
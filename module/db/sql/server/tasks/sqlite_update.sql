@@ -0,0 +1,3 @@
+UPDATE tasks
+SET name = $1, task_type = $2, schedule = $3, command = $4, countdown = $5, missed = $6, enabled = $7
+WHERE id = $8;
\ No newline at end of file
@@ -0,0 +1,13 @@
+SELECT server.id,
+  COALESCE(update_policy.channel, CASE WHEN server.version = 'latest' THEN 'latest-release' ELSE 'pinned' END) AS channel,
+  COALESCE(update_policy.auto_upgrade, FALSE),
+  COALESCE(update_policy.window_start, ''),
+  COALESCE(update_policy.window_end, ''),
+  COALESCE(update_policy.max_players, 0),
+  COALESCE(update_policy.available, ''),
+  COALESCE(update_policy.failed, ''),
+  COALESCE(update_policy.last_error, ''),
+  update_policy.checked_at
+FROM server
+  LEFT JOIN update_policy ON update_policy.server_id = server.id
+WHERE COALESCE(update_policy.channel, CASE WHEN server.version = 'latest' THEN 'latest-release' ELSE 'pinned' END) != 'pinned'
\ No newline at end of file
@@ -0,0 +1,5 @@
+INSERT INTO update_policy(server_id, channel, auto_upgrade, window_start, window_end, max_players, available, failed, last_error, checked_at)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+ON CONFLICT(server_id) DO UPDATE SET channel = excluded.channel, auto_upgrade = excluded.auto_upgrade,
+  window_start = excluded.window_start, window_end = excluded.window_end, max_players = excluded.max_players,
+  available = excluded.available, failed = excluded.failed, last_error = excluded.last_error, checked_at = excluded.checked_at;
\ No newline at end of file
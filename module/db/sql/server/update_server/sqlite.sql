@@ -1,3 +1,3 @@
 UPDATE server
-SET update_at = current_timestamp, name = $2, software = $3, version = $4
-WHERE id = $1;
\ No newline at end of file
+SET update_at = current_timestamp, name = $1, software = $2, version = $3, port = $4, port_v6 = $5, rcon_port = $6, rcon_password = $7
+WHERE id = $8;
\ No newline at end of file
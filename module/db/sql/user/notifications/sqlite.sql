@@ -0,0 +1,4 @@
+SELECT id, user_id, server_id, message, create_at
+FROM notifications
+WHERE user_id = $1
+ORDER BY create_at DESC, id DESC
\ No newline at end of file
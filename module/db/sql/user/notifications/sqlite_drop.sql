@@ -0,0 +1,2 @@
+DELETE FROM notifications
+WHERE id = $1 AND user_id = $2;
\ No newline at end of file
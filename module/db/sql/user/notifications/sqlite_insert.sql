@@ -0,0 +1,2 @@
+INSERT INTO notifications(user_id, server_id, message)
+VALUES ($1, $2, $3);
\ No newline at end of file
@@ -7,6 +7,7 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	"strings"
 	"time"
 
 	"sirherobrine23.com.br/go-bds/bds/module/server"
@@ -17,18 +18,56 @@ import (
 
 var (
 	SqliteCreateTables, _        = SQL.ReadFile("sql/create/sqlite.sql")
+	SqliteMigrateTables, _       = SQL.ReadFile("sql/migrate/sqlite.sql")
 	SqliteInsertUserPassword, _  = SQL.ReadFile("sql/server/user_insert/sqlite.sql")
 	SqliteInsertServer, _        = SQL.ReadFile("sql/server/server_insert/sqlite.sql")
 	SqliteUserServers, _         = SQL.ReadFile("sql/server/server_list/sqlite.sql")
 	SqliteServer, _              = SQL.ReadFile("sql/server/server_list/sqlite_id.sql")
+	SqliteServers, _             = SQL.ReadFile("sql/server/server_list/sqlite_all.sql")
 	SqliteUpdateServer, _        = SQL.ReadFile("sql/server/update_server/sqlite.sql")
+	SqliteDeleteServer, _        = SQL.ReadFile("sql/server/server_delete/sqlite.sql")
 	SqliteServerFriends, _       = SQL.ReadFile("sql/server/server_friends/sqlite.sql")
 	SqliteServerFriendsAdd, _    = SQL.ReadFile("sql/server/server_friends/sqlite_insert.sql")
 	SqliteServerFriendsRemove, _ = SQL.ReadFile("sql/server/server_friends/sqlite_drop.sql")
 	SqliteServerBackups, _       = SQL.ReadFile("sql/server/backup/sqlite.sql")
+	SqliteServerBackup, _        = SQL.ReadFile("sql/server/backup/sqlite_id.sql")
+	SqliteStorageBackups, _      = SQL.ReadFile("sql/server/backup/sqlite_storage.sql")
+	SqliteServerBackupInsert, _  = SQL.ReadFile("sql/server/backup/sqlite_insert.sql")
+	SqliteServerBackupDelete, _  = SQL.ReadFile("sql/server/backup/sqlite_drop.sql")
+	SqliteBackupConfig, _        = SQL.ReadFile("sql/server/backup/sqlite_config.sql")
+	SqliteBackupConfigSet, _     = SQL.ReadFile("sql/server/backup/sqlite_config_upsert.sql")
+	SqliteBackupJobs, _          = SQL.ReadFile("sql/server/backup/sqlite_jobs.sql")
+	SqliteBackupJobInsert, _     = SQL.ReadFile("sql/server/backup/sqlite_jobs_insert.sql")
+	SqliteBackupChunks, _        = SQL.ReadFile("sql/server/backup/sqlite_chunks.sql")
+	SqliteBackupChunk, _         = SQL.ReadFile("sql/server/backup/sqlite_chunk.sql")
+	SqliteBackupChunkRef, _      = SQL.ReadFile("sql/server/backup/sqlite_chunk_ref.sql")
+	SqliteBackupChunkUnref, _    = SQL.ReadFile("sql/server/backup/sqlite_chunk_unref.sql")
+	SqliteBackupChunkDelete, _   = SQL.ReadFile("sql/server/backup/sqlite_chunk_drop.sql")
+	SqliteTasks, _               = SQL.ReadFile("sql/server/tasks/sqlite.sql")
+	SqliteTasksEnabled, _        = SQL.ReadFile("sql/server/tasks/sqlite_enabled.sql")
+	SqliteTask, _                = SQL.ReadFile("sql/server/tasks/sqlite_id.sql")
+	SqliteTaskInsert, _          = SQL.ReadFile("sql/server/tasks/sqlite_insert.sql")
+	SqliteTaskUpdate, _          = SQL.ReadFile("sql/server/tasks/sqlite_update.sql")
+	SqliteTaskLastRun, _         = SQL.ReadFile("sql/server/tasks/sqlite_last_run.sql")
+	SqliteTaskDelete, _          = SQL.ReadFile("sql/server/tasks/sqlite_drop.sql")
+	SqliteTaskRuns, _            = SQL.ReadFile("sql/server/tasks/sqlite_runs.sql")
+	SqliteTaskRunInsert, _       = SQL.ReadFile("sql/server/tasks/sqlite_runs_insert.sql")
+	SqliteUpdatePolicy, _        = SQL.ReadFile("sql/server/update_policy/sqlite.sql")
+	SqliteUpdatePolicies, _      = SQL.ReadFile("sql/server/update_policy/sqlite_list.sql")
+	SqliteUpdatePolicySet, _     = SQL.ReadFile("sql/server/update_policy/sqlite_upsert.sql")
+	SqliteLogRetention, _        = SQL.ReadFile("sql/server/logs_retention/sqlite.sql")
+	SqliteLogRetentionSet, _     = SQL.ReadFile("sql/server/logs_retention/sqlite_upsert.sql")
+	SqlitePlayers, _             = SQL.ReadFile("sql/server/players/sqlite.sql")
+	SqlitePlayer, _              = SQL.ReadFile("sql/server/players/sqlite_username.sql")
+	SqlitePlayerUpdate, _        = SQL.ReadFile("sql/server/players/sqlite_upsert.sql")
+	SqlitePlayerHistory, _       = SQL.ReadFile("sql/server/players/sqlite_history.sql")
+	SqlitePlayerHistoryAdd, _    = SQL.ReadFile("sql/server/players/sqlite_history_insert.sql")
 
 	SqliteUserInsert, _         = SQL.ReadFile("sql/user/create/sqlite.sql")
 	SqliteUserInsertPassword, _ = SQL.ReadFile("sql/user/create/sqlite_password.sql")
+	SqliteNotifications, _      = SQL.ReadFile("sql/user/notifications/sqlite.sql")
+	SqliteNotificationInsert, _ = SQL.ReadFile("sql/user/notifications/sqlite_insert.sql")
+	SqliteNotificationDelete, _ = SQL.ReadFile("sql/user/notifications/sqlite_drop.sql")
 
 	passwordToEncrypt *string = new(string)
 
@@ -48,11 +87,31 @@ func NewSqliteConnection(connection string) (Database, error) {
 	// Create table is not exists
 	if _, err = db.Exec(string(SqliteCreateTables)); err != nil {
 		return nil, fmt.Errorf("cannot create tables: %s", err)
+	} else if err = sqliteMigrate(db); err != nil {
+		return nil, fmt.Errorf("cannot migrate tables: %s", err)
 	}
 
 	return &Sqlite{db}, nil
 }
 
+// Add columns to tables created by old versions, sqlite not have "ADD COLUMN IF NOT EXISTS"
+func sqliteMigrate(db *sql.DB) error {
+	for statement := range strings.SplitSeq(string(SqliteMigrateTables), ";") {
+		var lines []string
+		for line := range strings.SplitSeq(statement, "\n") {
+			if line = strings.TrimSpace(line); line != "" && !strings.HasPrefix(line, "--") {
+				lines = append(lines, line)
+			}
+		}
+		if len(lines) == 0 {
+			continue
+		} else if _, err := db.Exec(strings.Join(lines, " ")); err != nil && !strings.Contains(err.Error(), "duplicate column name") {
+			return err
+		}
+	}
+	return nil
+}
+
 func (slite *Sqlite) CreateNewUser(user *users.User, password *users.Password) (*users.User, error) {
 	if err := password.HashPassword(*passwordToEncrypt); err != nil {
 		return nil, err
@@ -181,6 +240,35 @@ func (slite *Sqlite) DeleteToken(token *users.Token) error {
 	return err
 }
 
+func (slite *Sqlite) Notifications(userID int64) ([]*users.Notification, error) {
+	rows, err := slite.Connection.Query(string(SqliteNotifications), userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	notifications := []*users.Notification{}
+	for rows.Next() {
+		notification := new(users.Notification)
+		// id, user_id, server_id, message, create_at
+		if err := rows.Scan(&notification.ID, &notification.UserID, &notification.ServerID, &notification.Message, &notification.CreateAt); err != nil {
+			return nil, err
+		}
+		notifications = append(notifications, notification)
+	}
+	return notifications, rows.Err()
+}
+
+func (slite *Sqlite) CreateNotification(notification *users.Notification) error {
+	_, err := slite.Connection.Exec(string(SqliteNotificationInsert), notification.UserID, notification.ServerID, notification.Message)
+	return err
+}
+
+func (slite *Sqlite) DeleteNotification(notification *users.Notification) error {
+	_, err := slite.Connection.Exec(string(SqliteNotificationDelete), notification.ID, notification.UserID)
+	return err
+}
+
 func (slite *Sqlite) CreateCookie(user *users.User) (*users.Cookie, *http.Cookie, error) {
 	newRandData := make([]byte, 128)
 	if _, err := rand.Read(newRandData); err != nil {
@@ -261,12 +349,16 @@ func (slite *Sqlite) CreateServer(user *users.User, Server *server.Server) (*ser
 	}
 
 	// Insert server to database
-	// owner, name, software, version
+	// owner, name, software, version, port, port_v6, rcon_port, rcon_password
 	result, err := slite.Connection.Exec(string(SqliteInsertServer),
 		Server.Owner,
 		Server.Name,
 		Server.Software,
 		Server.Version,
+		Server.Port,
+		Server.PortV6,
+		Server.RCONPort,
+		Server.RCONPassword,
 	)
 
 	if err != nil {
@@ -294,8 +386,28 @@ func (slite *Sqlite) UserServers(user *users.User) ([]*server.Server, error) {
 	var serversList []*server.Server
 	for rows.Next() {
 		server := new(server.Server)
-		// id, name, owner, software, version, create_at, update_at
-		if err := rows.Scan(&server.ID, &server.Name, &server.Owner, &server.Software, &server.Version, &server.CreateAt, &server.UpdateAt); err != nil {
+		// id, name, owner, software, version, port, port_v6, rcon_port, rcon_password, create_at, update_at
+		if err := rows.Scan(&server.ID, &server.Name, &server.Owner, &server.Software, &server.Version, &server.Port, &server.PortV6, &server.RCONPort, &server.RCONPassword, &server.CreateAt, &server.UpdateAt); err != nil {
+			return nil, err
+		}
+		serversList = append(serversList, server)
+	}
+
+	return serversList, rows.Err()
+}
+
+func (slite *Sqlite) Servers() ([]*server.Server, error) {
+	rows, err := slite.Connection.Query(string(SqliteServers))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var serversList []*server.Server
+	for rows.Next() {
+		server := new(server.Server)
+		// id, name, owner, software, version, port, port_v6, rcon_port, rcon_password, create_at, update_at
+		if err := rows.Scan(&server.ID, &server.Name, &server.Owner, &server.Software, &server.Version, &server.Port, &server.PortV6, &server.RCONPort, &server.RCONPassword, &server.CreateAt, &server.UpdateAt); err != nil {
 			return nil, err
 		}
 		serversList = append(serversList, server)
@@ -305,17 +417,17 @@ func (slite *Sqlite) UserServers(user *users.User) ([]*server.Server, error) {
 }
 
 func (slite *Sqlite) Server(ID int64) (*server.Server, error) {
-	row := slite.Connection.QueryRow(string(SqliteUserServers), ID)
+	row := slite.Connection.QueryRow(string(SqliteServer), ID)
 	if err := row.Err(); err != nil {
-		if err == sql.ErrNoRows {
-			err = ErrServerNotExists
-		}
 		return nil, err
 	}
 
 	server := new(server.Server)
-	// id, name, owner, software, version, create_at, update_at
-	if err := row.Scan(&server.ID, &server.Name, &server.Owner, &server.Software, &server.Version, &server.CreateAt, &server.UpdateAt); err != nil {
+	// id, name, owner, software, version, port, port_v6, rcon_port, rcon_password, create_at, update_at
+	if err := row.Scan(&server.ID, &server.Name, &server.Owner, &server.Software, &server.Version, &server.Port, &server.PortV6, &server.RCONPort, &server.RCONPassword, &server.CreateAt, &server.UpdateAt); err != nil {
+		if err == sql.ErrNoRows {
+			err = ErrServerNotExists
+		}
 		return nil, err
 	}
 
@@ -323,13 +435,18 @@ func (slite *Sqlite) Server(ID int64) (*server.Server, error) {
 }
 
 func (slite *Sqlite) UpdateServer(server *server.Server) error {
-	_, err := slite.Connection.Exec(string(SqliteUpdateServer), server.ID, server.Name, server.Software, server.Version)
+	_, err := slite.Connection.Exec(string(SqliteUpdateServer), server.Name, server.Software, server.Version, server.Port, server.PortV6, server.RCONPort, server.RCONPassword, server.ID)
 	if err == sql.ErrNoRows {
 		err = ErrServerNotExists
 	}
 	return err
 }
 
+func (slite *Sqlite) DeleteServer(server *server.Server) error {
+	_, err := slite.Connection.Exec(string(SqliteDeleteServer), server.ID)
+	return err
+}
+
 func (slite *Sqlite) ServerFriends(serverID int64) ([]*server.ServerFriends, error) {
 	// id, server_id, user_id, permissions
 	rows, err := slite.Connection.Query(string(SqliteServerFriends), serverID)
@@ -380,11 +497,13 @@ func (slite *Sqlite) ServerBackups(serverID int64) ([]*server.ServerBackup, erro
 		return nil, err
 	}
 
-	var backupsList []*server.ServerBackup
+	defer rows.Close()
+
+	backupsList := []*server.ServerBackup{}
 	for rows.Next() {
 		backup := new(server.ServerBackup)
-		// id, server_id, uuid, software, version, create_at
-		if err := rows.Scan(&backup.ID, &backup.ServerID, &backup.UUID, &backup.Software, &backup.Version, &backup.CreateAt); err != nil {
+		// id, server_id, uuid, software, version, storage, encrypted, incremental, safety, create_at
+		if err := rows.Scan(&backup.ID, &backup.ServerID, &backup.UUID, &backup.Software, &backup.Version, &backup.Storage, &backup.Encrypted, &backup.Incremental, &backup.Safety, &backup.CreateAt); err != nil {
 			return nil, err
 		}
 		backupsList = append(backupsList, backup)
@@ -392,3 +511,415 @@ func (slite *Sqlite) ServerBackups(serverID int64) ([]*server.ServerBackup, erro
 
 	return backupsList, rows.Err()
 }
+
+func (slite *Sqlite) StorageBackups(storage string) ([]*server.ServerBackup, error) {
+	rows, err := slite.Connection.Query(string(SqliteStorageBackups), storage)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	backupsList := []*server.ServerBackup{}
+	for rows.Next() {
+		backup := new(server.ServerBackup)
+		// id, server_id, uuid, software, version, storage, encrypted, incremental, safety, create_at
+		if err := rows.Scan(&backup.ID, &backup.ServerID, &backup.UUID, &backup.Software, &backup.Version, &backup.Storage, &backup.Encrypted, &backup.Incremental, &backup.Safety, &backup.CreateAt); err != nil {
+			return nil, err
+		}
+		backupsList = append(backupsList, backup)
+	}
+	return backupsList, rows.Err()
+}
+
+func (slite *Sqlite) LogRetention(serverID int64) (*server.LogRetention, error) {
+	policy := &server.LogRetention{ServerID: serverID}
+	// server_id, max_age, max_runs
+	err := slite.Connection.QueryRow(string(SqliteLogRetention), serverID).Scan(&policy.ServerID, &policy.MaxAge, &policy.MaxRuns)
+	if err != nil && err != sql.ErrNoRows {
+		return nil, err
+	}
+	return policy, nil
+}
+
+func (slite *Sqlite) SetLogRetention(policy *server.LogRetention) error {
+	_, err := slite.Connection.Exec(string(SqliteLogRetentionSet), policy.ServerID, policy.MaxAge, policy.MaxRuns)
+	return err
+}
+
+type rowScanner interface{ Scan(dest ...any) error }
+
+func scanPlayer(row rowScanner) (*server.Player, error) {
+	player := new(server.Player)
+	// id, server_id, username, xuid, uuid, banned, operator, whitelisted, first_seen, last_seen
+	err := row.Scan(&player.ID, &player.ServerID, &player.Username, &player.XUID, &player.UUID,
+		&player.Banned, &player.Operator, &player.Whitelisted, &player.FirstSeen, &player.LastSeen)
+	return player, err
+}
+
+func (slite *Sqlite) Players(serverID int64) ([]*server.Player, error) {
+	rows, err := slite.Connection.Query(string(SqlitePlayers), serverID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	playersList := []*server.Player{}
+	for rows.Next() {
+		player, err := scanPlayer(rows)
+		if err != nil {
+			return nil, err
+		}
+		playersList = append(playersList, player)
+	}
+	return playersList, rows.Err()
+}
+
+func (slite *Sqlite) Player(serverID int64, username string) (*server.Player, error) {
+	player, err := scanPlayer(slite.Connection.QueryRow(string(SqlitePlayer), serverID, username))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			err = ErrPlayerNotExists
+		}
+		return nil, err
+	}
+	return player, nil
+}
+
+func (slite *Sqlite) UpdatePlayer(player *server.Player) error {
+	// server_id, username, xuid, uuid, banned, operator, whitelisted
+	_, err := slite.Connection.Exec(string(SqlitePlayerUpdate), player.ServerID, player.Username, player.XUID, player.UUID,
+		player.Banned, player.Operator, player.Whitelisted)
+	return err
+}
+
+func (slite *Sqlite) PlayerHistory(serverID int64, username string) ([]*server.PlayerHistory, error) {
+	rows, err := slite.Connection.Query(string(SqlitePlayerHistory), serverID, username)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	historyList := []*server.PlayerHistory{}
+	for rows.Next() {
+		history := new(server.PlayerHistory)
+		// id, server_id, username, action, reason, user_id, create_at
+		if err := rows.Scan(&history.ID, &history.ServerID, &history.Username, &history.Action, &history.Reason, &history.UserID, &history.CreateAt); err != nil {
+			return nil, err
+		}
+		historyList = append(historyList, history)
+	}
+	return historyList, rows.Err()
+}
+
+func (slite *Sqlite) AddPlayerHistory(history *server.PlayerHistory) error {
+	_, err := slite.Connection.Exec(string(SqlitePlayerHistoryAdd), history.ServerID, history.Username, history.Action, history.Reason, history.UserID)
+	return err
+}
+
+func (slite *Sqlite) ServerBackup(ID int64) (*server.ServerBackup, error) {
+	backup := new(server.ServerBackup)
+	// id, server_id, uuid, software, version, storage, encrypted, incremental, safety, create_at
+	err := slite.Connection.QueryRow(string(SqliteServerBackup), ID).Scan(&backup.ID, &backup.ServerID, &backup.UUID, &backup.Software, &backup.Version, &backup.Storage, &backup.Encrypted, &backup.Incremental, &backup.Safety, &backup.CreateAt)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			err = ErrBackupNotExists
+		}
+		return nil, err
+	}
+	return backup, nil
+}
+
+func (slite *Sqlite) CreateBackup(backup *server.ServerBackup) (*server.ServerBackup, error) {
+	// server_id, uuid, software, version, storage, encrypted, incremental, safety
+	result, err := slite.Connection.Exec(string(SqliteServerBackupInsert), backup.ServerID, backup.UUID, backup.Software, backup.Version, backup.Storage, backup.Encrypted, backup.Incremental, backup.Safety)
+	if err != nil {
+		return nil, err
+	}
+
+	backupID, err := result.LastInsertId()
+	if err != nil {
+		return nil, fmt.Errorf("cannot get new backup ID: %s", err)
+	}
+	return slite.ServerBackup(backupID)
+}
+
+func (slite *Sqlite) DeleteBackup(backup *server.ServerBackup) error {
+	_, err := slite.Connection.Exec(string(SqliteServerBackupDelete), backup.ID)
+	return err
+}
+
+func scanBackupConfig(row rowScanner, config *server.BackupConfig) error {
+	// server_id, storage, incremental, keep_last, keep_daily, keep_weekly
+	return row.Scan(&config.ServerID, &config.Storage, &config.Incremental, &config.KeepLast, &config.KeepDaily, &config.KeepWeekly)
+}
+
+func (slite *Sqlite) BackupConfig(serverID int64) (*server.BackupConfig, error) {
+	config := &server.BackupConfig{ServerID: serverID}
+	err := scanBackupConfig(slite.Connection.QueryRow(string(SqliteBackupConfig), serverID), config)
+	if err != nil && err != sql.ErrNoRows {
+		return nil, err
+	}
+	return config, nil
+}
+
+func (slite *Sqlite) SetBackupConfig(config *server.BackupConfig) error {
+	_, err := slite.Connection.Exec(string(SqliteBackupConfigSet), config.ServerID, config.Storage, config.Incremental, config.KeepLast, config.KeepDaily, config.KeepWeekly)
+	return err
+}
+
+func (slite *Sqlite) BackupJobs(serverID int64, limit int) ([]*server.BackupJob, error) {
+	rows, err := slite.Connection.Query(string(SqliteBackupJobs), serverID, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	jobs := []*server.BackupJob{}
+	for rows.Next() {
+		job := new(server.BackupJob)
+		var backupID sql.NullInt64
+		// id, server_id, backup_id, status, error, removed, start_at, end_at
+		if err := rows.Scan(&job.ID, &job.ServerID, &backupID, &job.Status, &job.Error, &job.Removed, &job.StartAt, &job.EndAt); err != nil {
+			return nil, err
+		}
+		job.BackupID = backupID.Int64
+		jobs = append(jobs, job)
+	}
+	return jobs, rows.Err()
+}
+
+func (slite *Sqlite) AddBackupJob(job *server.BackupJob) error {
+	backupID := sql.NullInt64{Int64: job.BackupID, Valid: job.BackupID != 0}
+	// server_id, backup_id, status, error, removed, start_at, end_at
+	_, err := slite.Connection.Exec(string(SqliteBackupJobInsert), job.ServerID, backupID, job.Status, job.Error, job.Removed, job.StartAt, job.EndAt)
+	return err
+}
+
+func (slite *Sqlite) BackupChunks(storage string) ([]*server.BackupChunk, error) {
+	rows, err := slite.Connection.Query(string(SqliteBackupChunks), storage)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	chunksList := []*server.BackupChunk{}
+	for rows.Next() {
+		chunk := new(server.BackupChunk)
+		// storage, hash, size, refs
+		if err := rows.Scan(&chunk.Storage, &chunk.Hash, &chunk.Size, &chunk.Refs); err != nil {
+			return nil, err
+		}
+		chunksList = append(chunksList, chunk)
+	}
+	return chunksList, rows.Err()
+}
+
+func (slite *Sqlite) BackupChunk(storage, hash string) (*server.BackupChunk, error) {
+	chunk := new(server.BackupChunk)
+	// storage, hash, size, refs
+	err := slite.Connection.QueryRow(string(SqliteBackupChunk), storage, hash).Scan(&chunk.Storage, &chunk.Hash, &chunk.Size, &chunk.Refs)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			err = ErrChunkNotExists
+		}
+		return nil, err
+	}
+	return chunk, nil
+}
+
+func (slite *Sqlite) RefBackupChunks(chunks ...*server.BackupChunk) error {
+	tx, err := slite.Connection.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	for _, chunk := range chunks {
+		// storage, hash, size
+		if _, err = tx.Exec(string(SqliteBackupChunkRef), chunk.Storage, chunk.Hash, chunk.Size); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+func (slite *Sqlite) UnrefBackupChunks(storage string, hashes ...string) ([]string, error) {
+	tx, err := slite.Connection.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	unused := []string{}
+	for _, hash := range hashes {
+		var refs int64
+		if err = tx.QueryRow(string(SqliteBackupChunkUnref), storage, hash).Scan(&refs); err != nil {
+			if err == sql.ErrNoRows {
+				continue
+			}
+			return nil, err
+		} else if refs <= 0 {
+			if _, err = tx.Exec(string(SqliteBackupChunkDelete), storage, hash); err != nil {
+				return nil, err
+			}
+			unused = append(unused, hash)
+		}
+	}
+	return unused, tx.Commit()
+}
+
+func scanTask(row rowScanner) (*server.Task, error) {
+	task := new(server.Task)
+	var lastRun sql.NullTime
+	// id, server_id, name, task_type, schedule, command, countdown, missed, enabled, last_run, create_at
+	err := row.Scan(&task.ID, &task.ServerID, &task.Name, &task.Type, &task.Schedule, &task.Command,
+		&task.Countdown, &task.Missed, &task.Enabled, &lastRun, &task.CreateAt)
+	task.LastRun = lastRun.Time
+	return task, err
+}
+
+func (slite *Sqlite) queryTasks(query string, args ...any) ([]*server.Task, error) {
+	rows, err := slite.Connection.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	tasks := []*server.Task{}
+	for rows.Next() {
+		task, err := scanTask(rows)
+		if err != nil {
+			return nil, err
+		}
+		tasks = append(tasks, task)
+	}
+	return tasks, rows.Err()
+}
+
+func (slite *Sqlite) Tasks(serverID int64) ([]*server.Task, error) {
+	return slite.queryTasks(string(SqliteTasks), serverID)
+}
+
+func (slite *Sqlite) EnabledTasks() ([]*server.Task, error) {
+	return slite.queryTasks(string(SqliteTasksEnabled))
+}
+
+func (slite *Sqlite) Task(ID int64) (*server.Task, error) {
+	task, err := scanTask(slite.Connection.QueryRow(string(SqliteTask), ID))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			err = ErrTaskNotExists
+		}
+		return nil, err
+	}
+	return task, nil
+}
+
+func (slite *Sqlite) CreateTask(task *server.Task) (*server.Task, error) {
+	// server_id, name, task_type, schedule, command, countdown, missed, enabled
+	result, err := slite.Connection.Exec(string(SqliteTaskInsert), task.ServerID, task.Name, task.Type, task.Schedule,
+		task.Command, task.Countdown, task.Missed, task.Enabled)
+	if err != nil {
+		return nil, err
+	}
+
+	taskID, err := result.LastInsertId()
+	if err != nil {
+		return nil, fmt.Errorf("cannot get new task ID: %s", err)
+	}
+	return slite.Task(taskID)
+}
+
+func (slite *Sqlite) UpdateTask(task *server.Task) error {
+	// name, task_type, schedule, command, countdown, missed, enabled, id
+	_, err := slite.Connection.Exec(string(SqliteTaskUpdate), task.Name, task.Type, task.Schedule,
+		task.Command, task.Countdown, task.Missed, task.Enabled, task.ID)
+	return err
+}
+
+func (slite *Sqlite) SetTaskLastRun(task *server.Task, lastRun time.Time) error {
+	if _, err := slite.Connection.Exec(string(SqliteTaskLastRun), lastRun, task.ID); err != nil {
+		return err
+	}
+	task.LastRun = lastRun
+	return nil
+}
+
+func (slite *Sqlite) DeleteTask(task *server.Task) error {
+	_, err := slite.Connection.Exec(string(SqliteTaskDelete), task.ID)
+	return err
+}
+
+func (slite *Sqlite) TaskRuns(taskID int64, limit int) ([]*server.TaskRun, error) {
+	rows, err := slite.Connection.Query(string(SqliteTaskRuns), taskID, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	runs := []*server.TaskRun{}
+	for rows.Next() {
+		run := new(server.TaskRun)
+		// id, task_id, server_id, status, error, start_at, end_at
+		if err := rows.Scan(&run.ID, &run.TaskID, &run.ServerID, &run.Status, &run.Error, &run.StartAt, &run.EndAt); err != nil {
+			return nil, err
+		}
+		runs = append(runs, run)
+	}
+	return runs, rows.Err()
+}
+
+func (slite *Sqlite) AddTaskRun(run *server.TaskRun) error {
+	// task_id, server_id, status, error, start_at, end_at
+	_, err := slite.Connection.Exec(string(SqliteTaskRunInsert), run.TaskID, run.ServerID, run.Status, run.Error, run.StartAt, run.EndAt)
+	return err
+}
+
+func scanUpdatePolicy(row rowScanner) (*server.UpdatePolicy, error) {
+	policy := new(server.UpdatePolicy)
+	var checkedAt sql.NullTime
+	// server_id, channel, auto_upgrade, window_start, window_end, max_players, available, failed, last_error, checked_at
+	err := row.Scan(&policy.ServerID, &policy.Channel, &policy.AutoUpgrade, &policy.WindowStart, &policy.WindowEnd,
+		&policy.MaxPlayers, &policy.Available, &policy.Failed, &policy.LastError, &checkedAt)
+	policy.CheckedAt = checkedAt.Time
+	return policy, err
+}
+
+func (slite *Sqlite) UpdatePolicy(serverID int64) (*server.UpdatePolicy, error) {
+	policy, err := scanUpdatePolicy(slite.Connection.QueryRow(string(SqliteUpdatePolicy), serverID))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			err = ErrServerNotExists
+		}
+		return nil, err
+	}
+	return policy, nil
+}
+
+func (slite *Sqlite) UpdatePolicies() ([]*server.UpdatePolicy, error) {
+	rows, err := slite.Connection.Query(string(SqliteUpdatePolicies))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	policies := []*server.UpdatePolicy{}
+	for rows.Next() {
+		policy, err := scanUpdatePolicy(rows)
+		if err != nil {
+			return nil, err
+		}
+		policies = append(policies, policy)
+	}
+	return policies, rows.Err()
+}
+
+func (slite *Sqlite) SetUpdatePolicy(policy *server.UpdatePolicy) error {
+	var checkedAt sql.NullTime
+	if !policy.CheckedAt.IsZero() {
+		checkedAt = sql.NullTime{Time: policy.CheckedAt, Valid: true}
+	}
+	_, err := slite.Connection.Exec(string(SqliteUpdatePolicySet), policy.ServerID, policy.Channel, policy.AutoUpgrade,
+		policy.WindowStart, policy.WindowEnd, policy.MaxPlayers, policy.Available, policy.Failed, policy.LastError, checkedAt)
+	return err
+}
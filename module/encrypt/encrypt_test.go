@@ -1,6 +1,11 @@
 package encrypt
 
-import "testing"
+import (
+	"bytes"
+	"crypto/rand"
+	"io"
+	"testing"
+)
 
 var (
 	DefaultKey       = "golang_test"
@@ -32,3 +37,80 @@ func TestPassword(t *testing.T) {
 		t.Errorf("password required return invalided:\n\t%s\n\t%s", PasswordToEncode, pass)
 	}
 }
+
+func TestStream(t *testing.T) {
+	for _, size := range []int{0, 1, DefaultChunkSize, DefaultChunkSize + 1, DefaultChunkSize*3 + 100} {
+		data := make([]byte, size)
+		rand.Read(data)
+
+		var encrypted bytes.Buffer
+		w, err := NewWriter(&encrypted, DefaultKey)
+		if err != nil {
+			t.Errorf("cannot create writer: %s", err)
+			return
+		} else if _, err = w.Write(data); err != nil {
+			t.Errorf("cannot write data: %s", err)
+			return
+		} else if err = w.Close(); err != nil {
+			t.Errorf("cannot close writer: %s", err)
+			return
+		}
+
+		r, err := NewReader(bytes.NewReader(encrypted.Bytes()), DefaultKey)
+		if err != nil {
+			t.Errorf("cannot create reader: %s", err)
+			return
+		} else if decrypted, err := io.ReadAll(r); err != nil || !bytes.Equal(decrypted, data) {
+			t.Errorf("decrypted stream with %d bytes is different: %v", size, err)
+			return
+		}
+
+		file, err := OpenFile(bytes.NewReader(encrypted.Bytes()), int64(encrypted.Len()), DefaultKey)
+		if err != nil {
+			t.Errorf("cannot open file: %s", err)
+			return
+		} else if file.Size() != int64(size) {
+			t.Errorf("invalid plaintext size %d, expected %d", file.Size(), size)
+			return
+		}
+		if size > 10 {
+			part := make([]byte, 10)
+			if _, err = file.ReadAt(part, int64(size-10)); err != nil || !bytes.Equal(part, data[size-10:]) {
+				t.Errorf("invalid ReadAt: %v", err)
+				return
+			}
+		}
+
+		// Wrong key and truncated stream
+		if r, err = NewReader(bytes.NewReader(encrypted.Bytes()), DefaultKey+"google"); err == nil {
+			if _, err = io.ReadAll(r); err != ErrStreamAuth {
+				t.Errorf("stream decrypted with wrong key: %v", err)
+				return
+			}
+		}
+		if encrypted.Len() > streamHeader+DefaultChunkSize+16 {
+			r, _ = NewReader(bytes.NewReader(encrypted.Bytes()[:streamHeader+DefaultChunkSize+16]), DefaultKey)
+			if _, err = io.ReadAll(r); err != ErrStreamAuth {
+				t.Errorf("truncated stream not detected: %v", err)
+				return
+			}
+		}
+	}
+}
+
+func TestStreamKeySalt(t *testing.T) {
+	var encrypted bytes.Buffer
+	w, _ := NewWriter(&encrypted, DefaultKey)
+	w.Write([]byte(PasswordToEncode))
+	w.Close()
+
+	// Stretched key from header salt, like in new process
+	stretchedKeys.Clear()
+	keySalts.Clear()
+	if r, err := NewReader(bytes.NewReader(encrypted.Bytes()), DefaultKey); err != nil {
+		t.Errorf("cannot create reader: %s", err)
+		return
+	} else if decrypted, err := io.ReadAll(r); err != nil || string(decrypted) != PasswordToEncode {
+		t.Errorf("cannot decrypt with key salt from header: %v", err)
+	}
+}
@@ -0,0 +1,380 @@
+package encrypt
+
+import (
+	"bufio"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hkdf"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+
+	"golang.org/x/crypto/scrypt"
+)
+
+// Stream format:
+//
+//	header: magic "BDSE" | version (1 byte) | chunk size (uint32) | key salt (16 bytes) | stream salt (32 bytes)
+//	chunks: AES-256-GCM(plaintext chunk), nonce is chunk counter and last chunk flag, header as additional data
+//
+// Global key is stretched with scrypt and key salt, stretched key is cached so many streams can share same key salt.
+// Stream key is derived with HKDF-SHA256 from stretched key and random stream salt, so every stream have a new key.
+// Last chunk is flagged in nonce, truncated or reordered streams fail to authenticate.
+const (
+	streamMagic      = "BDSE"
+	streamVersion    = 1
+	streamKeySaltLen = 16
+	streamSaltLen    = 32
+	streamPrefix     = len(streamMagic) + 1 // Magic and version
+	streamKeySalt    = streamPrefix + 4     // Key salt offset, after chunk size
+	streamHeader     = streamKeySalt + streamKeySaltLen + streamSaltLen
+	streamInfo       = "go-bds stream v1"
+
+	DefaultChunkSize = 64 << 10 // Plaintext bytes per chunk
+)
+
+var (
+	ErrInvalidStream = errors.New("invalid encrypted stream header")
+	ErrStreamAuth    = errors.New("encrypted stream authentication failed, invalid key or corrupted data")
+
+	stretchedKeys sync.Map // global key and key salt to scrypt key
+	keySalts      sync.Map // global key to key salt used in new streams
+)
+
+// Stretch global key with scrypt, result is cached to key and salt
+func stretchKey(globalKey string, salt []byte) ([]byte, error) {
+	cacheKey := globalKey + "\x00" + string(salt)
+	if key, ok := stretchedKeys.Load(cacheKey); ok {
+		return key.([]byte), nil
+	}
+	key, err := scrypt.Key([]byte(globalKey), salt, scryptN, scryptR, scryptP, 32)
+	if err != nil {
+		return nil, fmt.Errorf("failed to derive key using scrypt: %w", err)
+	}
+	stretchedKeys.Store(cacheKey, key)
+	return key, nil
+}
+
+// Key salt to new streams, generated once to each global key
+func keySalt(globalKey string) ([]byte, error) {
+	if salt, ok := keySalts.Load(globalKey); ok {
+		return salt.([]byte), nil
+	}
+	salt := make([]byte, streamKeySaltLen)
+	if _, err := io.ReadFull(rand.Reader, salt); err != nil {
+		return nil, fmt.Errorf("failed to generate salt: %w", err)
+	}
+	actual, _ := keySalts.LoadOrStore(globalKey, salt)
+	return actual.([]byte), nil
+}
+
+// Derive AES-GCM from global key and stream header
+func streamCipher(globalKey string, header []byte) (cipher.AEAD, error) {
+	if len(globalKey) == 0 {
+		return nil, ErrGlobalKeyNotSet
+	}
+	master, err := stretchKey(globalKey, header[streamKeySalt:streamKeySalt+streamKeySaltLen])
+	if err != nil {
+		return nil, err
+	}
+	key, err := hkdf.Key(sha256.New, master, header[len(header)-streamSaltLen:], streamInfo, 32)
+	if err != nil {
+		return nil, fmt.Errorf("failed to derive stream key: %w", err)
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create AES cipher: %w", err)
+	}
+	return cipher.NewGCM(block)
+}
+
+func streamNonce(nonce []byte, counter uint64, last bool) []byte {
+	clear(nonce)
+	binary.BigEndian.PutUint64(nonce, counter)
+	if last {
+		nonce[len(nonce)-1] = 1
+	}
+	return nonce
+}
+
+// Parse stream header, return chunk size
+func parseHeader(header []byte) (int, error) {
+	if len(header) != streamHeader || string(header[:len(streamMagic)]) != streamMagic || header[len(streamMagic)] != streamVersion {
+		return 0, ErrInvalidStream
+	}
+	chunkSize := binary.BigEndian.Uint32(header[streamPrefix:])
+	if chunkSize == 0 || chunkSize > 16<<20 {
+		return 0, ErrInvalidStream
+	}
+	return int(chunkSize), nil
+}
+
+// Encrypt data written to stream, Close must be called to write last chunk
+type Writer struct {
+	w       io.Writer
+	aead    cipher.AEAD
+	header  []byte
+	nonce   []byte
+	counter uint64
+	buff    []byte // Plaintext waiting to be sealed
+	sealed  []byte
+	closed  bool
+}
+
+// Create new encrypt stream writer with [DefaultChunkSize] and write header to w
+func NewWriter(w io.Writer, globalKey string) (*Writer, error) {
+	salt, err := keySalt(globalKey)
+	if err != nil {
+		return nil, err
+	}
+	header := make([]byte, streamHeader)
+	copy(header, streamMagic)
+	header[len(streamMagic)] = streamVersion
+	binary.BigEndian.PutUint32(header[streamPrefix:], DefaultChunkSize)
+	copy(header[streamKeySalt:], salt)
+	if _, err := io.ReadFull(rand.Reader, header[len(header)-streamSaltLen:]); err != nil {
+		return nil, fmt.Errorf("failed to generate salt: %w", err)
+	}
+
+	aead, err := streamCipher(globalKey, header)
+	if err != nil {
+		return nil, err
+	} else if _, err = w.Write(header); err != nil {
+		return nil, err
+	}
+
+	return &Writer{
+		w:      w,
+		aead:   aead,
+		header: header,
+		nonce:  make([]byte, aead.NonceSize()),
+		buff:   make([]byte, 0, DefaultChunkSize),
+	}, nil
+}
+
+func (sw *Writer) seal(last bool) error {
+	sw.sealed = sw.aead.Seal(sw.sealed[:0], streamNonce(sw.nonce, sw.counter, last), sw.buff, sw.header)
+	sw.counter++
+	sw.buff = sw.buff[:0]
+	_, err := sw.w.Write(sw.sealed)
+	return err
+}
+
+func (sw *Writer) Write(p []byte) (int, error) {
+	if sw.closed {
+		return 0, io.ErrClosedPipe
+	}
+
+	written := 0
+	for len(p) > 0 {
+		// Only seal full chunk when have more data, last chunk is sealed in Close
+		if len(sw.buff) == cap(sw.buff) {
+			if err := sw.seal(false); err != nil {
+				return written, err
+			}
+		}
+		n := copy(sw.buff[len(sw.buff):cap(sw.buff)], p)
+		sw.buff = sw.buff[:len(sw.buff)+n]
+		p, written = p[n:], written+n
+	}
+	return written, nil
+}
+
+// Write last chunk, not close underlying writer
+func (sw *Writer) Close() error {
+	if sw.closed {
+		return nil
+	}
+	sw.closed = true
+	return sw.seal(true)
+}
+
+// Decrypt stream created by [Writer]
+type Reader struct {
+	r       *bufio.Reader
+	aead    cipher.AEAD
+	header  []byte
+	nonce   []byte
+	counter uint64
+	chunk   []byte // Sealed chunk
+	plain   []byte // Decrypted data not readed
+	done    bool
+}
+
+// Read header and create decrypt stream reader
+func NewReader(r io.Reader, globalKey string) (*Reader, error) {
+	header := make([]byte, streamHeader)
+	if _, err := io.ReadFull(r, header); err != nil {
+		if err == io.EOF || err == io.ErrUnexpectedEOF {
+			err = ErrInvalidStream
+		}
+		return nil, err
+	}
+	chunkSize, err := parseHeader(header)
+	if err != nil {
+		return nil, err
+	}
+	aead, err := streamCipher(globalKey, header)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Reader{
+		r:      bufio.NewReader(r),
+		aead:   aead,
+		header: header,
+		nonce:  make([]byte, aead.NonceSize()),
+		chunk:  make([]byte, chunkSize+aead.Overhead()),
+	}, nil
+}
+
+func (sr *Reader) Read(p []byte) (int, error) {
+	for len(sr.plain) == 0 {
+		if sr.done {
+			return 0, io.EOF
+		}
+
+		n, err := io.ReadFull(sr.r, sr.chunk)
+		if err == io.EOF || err == io.ErrUnexpectedEOF {
+			sr.done = true
+		} else if err != nil {
+			return 0, err
+		} else if _, err = sr.r.Peek(1); err == io.EOF {
+			sr.done = true
+		}
+
+		if sr.plain, err = sr.aead.Open(sr.chunk[:0], streamNonce(sr.nonce, sr.counter, sr.done), sr.chunk[:n], sr.header); err != nil {
+			return 0, ErrStreamAuth
+		}
+		sr.counter++
+	}
+
+	n := copy(p, sr.plain)
+	sr.plain = sr.plain[n:]
+	return n, nil
+}
+
+// Random access to encrypted stream, decrypt only chunks needed to read
+type File struct {
+	r         io.ReaderAt
+	aead      cipher.AEAD
+	header    []byte
+	chunkSize int64
+	chunks    int64 // Chunks in stream
+	size      int64 // Plaintext size
+	offset    int64 // Read offset
+
+	cached int64 // Index of chunk in plain, -1 if none
+	chunk  []byte
+	plain  []byte
+}
+
+// Open encrypted stream with size bytes for random access
+func OpenFile(r io.ReaderAt, size int64, globalKey string) (*File, error) {
+	header := make([]byte, streamHeader)
+	if _, err := r.ReadAt(header, 0); err != nil {
+		if err == io.EOF {
+			err = ErrInvalidStream
+		}
+		return nil, err
+	}
+	chunkSize, err := parseHeader(header)
+	if err != nil {
+		return nil, err
+	}
+	aead, err := streamCipher(globalKey, header)
+	if err != nil {
+		return nil, err
+	}
+
+	sealedSize := int64(chunkSize + aead.Overhead())
+	body := size - int64(streamHeader)
+	chunks := (body + sealedSize - 1) / sealedSize
+	if chunks == 0 || body%sealedSize != 0 && body%sealedSize < int64(aead.Overhead()) {
+		return nil, ErrInvalidStream
+	}
+
+	return &File{
+		r:         r,
+		aead:      aead,
+		header:    header,
+		chunkSize: int64(chunkSize),
+		chunks:    chunks,
+		size:      body - chunks*int64(aead.Overhead()),
+		cached:    -1,
+		chunk:     make([]byte, sealedSize),
+	}, nil
+}
+
+// Plaintext size
+func (file *File) Size() int64 { return file.size }
+
+// Decrypt chunk to file.plain
+func (file *File) load(index int64) error {
+	if file.cached == index {
+		return nil
+	}
+
+	offset := int64(streamHeader) + index*int64(len(file.chunk))
+	n, err := file.r.ReadAt(file.chunk, offset)
+	if err != nil && !(err == io.EOF && index == file.chunks-1) {
+		return err
+	}
+
+	nonce := streamNonce(make([]byte, file.aead.NonceSize()), uint64(index), index == file.chunks-1)
+	if file.plain, err = file.aead.Open(file.plain[:0], nonce, file.chunk[:n], file.header); err != nil {
+		file.cached = -1
+		return ErrStreamAuth
+	}
+	file.cached = index
+	return nil
+}
+
+func (file *File) ReadAt(p []byte, off int64) (int, error) {
+	if off < 0 {
+		return 0, errors.New("encrypt: negative offset")
+	}
+
+	written := 0
+	for written < len(p) {
+		if off >= file.size {
+			return written, io.EOF
+		}
+		if err := file.load(off / file.chunkSize); err != nil {
+			return written, err
+		}
+		n := copy(p[written:], file.plain[off%file.chunkSize:])
+		written, off = written+n, off+int64(n)
+	}
+	return written, nil
+}
+
+func (file *File) Read(p []byte) (int, error) {
+	if file.offset >= file.size {
+		return 0, io.EOF
+	}
+	n, err := file.ReadAt(p, file.offset)
+	file.offset += int64(n)
+	if err == io.EOF && n > 0 {
+		err = nil
+	}
+	return n, err
+}
+
+func (file *File) Seek(offset int64, whence int) (int64, error) {
+	switch whence {
+	case io.SeekCurrent:
+		offset += file.offset
+	case io.SeekEnd:
+		offset += file.size
+	}
+	if offset < 0 {
+		return 0, errors.New("encrypt: negative position")
+	}
+	file.offset = offset
+	return offset, nil
+}
@@ -0,0 +1,417 @@
+// Read and write files inside servers data directory
+package files
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"sirherobrine23.com.br/go-bds/bds/module/runner"
+)
+
+var (
+	ErrInvalidPath error = errors.New("invalid path, use relative path inside server directory")
+	ErrQuota       error = errors.New("server disk quota exceeded")
+	ErrFileSize    error = errors.New("file too large")
+	ErrIsDir       error = errors.New("path is directory")
+	ErrNotDir      error = errors.New("path is not directory")
+	ErrNotEmpty    error = errors.New("directory not empty")
+	ErrExists      error = errors.New("path already exists")
+)
+
+// File or directory in server directory
+type Entry struct {
+	Name    string      `json:"name"`
+	Path    string      `json:"path"` // Path relative to server directory
+	Size    int64       `json:"size"`
+	Mode    fs.FileMode `json:"mode"`
+	Dir     bool        `json:"dir"`
+	Symlink bool        `json:"symlink"`
+	ModTime time.Time   `json:"mod_time"`
+}
+
+func newEntry(name string, info fs.FileInfo) *Entry {
+	return &Entry{
+		Name:    info.Name(),
+		Path:    name,
+		Size:    info.Size(),
+		Mode:    info.Mode(),
+		Dir:     info.IsDir(),
+		Symlink: info.Mode()&fs.ModeSymlink != 0,
+		ModTime: info.ModTime(),
+	}
+}
+
+// Servers files maneger
+type Manager struct {
+	Runner      *runner.Manager // Servers runner, used to find servers directory
+	Quota       int64           // Max bytes to each server directory, 0 to unlimited
+	MaxFileSize int64           // Max bytes to single file write or upload, 0 to unlimited
+	UploadDir   string          // Directory to resumable uploads parts
+	MaxUploads  int             // Max uploads in progress to each server, 0 to unlimited
+
+	uploadsMu sync.Mutex // Serialize new uploads to count pending uploads
+}
+
+// Create new files maneger with quota to servers
+func NewManager(manager *runner.Manager, quota int64) *Manager {
+	return &Manager{
+		Runner:     manager,
+		Quota:      quota,
+		UploadDir:  filepath.Join(manager.Root, ".uploads"),
+		MaxUploads: DefaultMaxUploads,
+	}
+}
+
+// Open server directory, all paths are confined to server directory
+func (mg *Manager) Open(serverID int64) (*FS, error) {
+	dir := mg.Runner.Dir(serverID)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, fmt.Errorf("cannot make server directory: %s", err)
+	}
+	root, err := os.OpenRoot(dir)
+	if err != nil {
+		return nil, err
+	}
+	return &FS{root: root, mg: mg}, nil
+}
+
+// Error returned by [os.Root] to paths outside root, os not export this error so is taken once from root
+var errEscape = sync.OnceValue(func() error {
+	root, err := os.OpenRoot(os.TempDir())
+	if err != nil {
+		return nil
+	}
+	defer root.Close()
+	var pathErr *fs.PathError
+	if _, err = root.Lstat(".."); errors.As(err, &pathErr) {
+		return pathErr.Err
+	}
+	return nil
+})
+
+// Check if error is from symlink pointing outside server directory
+func IsEscape(err error) bool {
+	escape := errEscape()
+	return escape != nil && errors.Is(err, escape)
+}
+
+// Clean path from client, return "." to server directory
+func Clean(name string) (string, error) {
+	if strings.ContainsAny(name, "\\\x00") {
+		return "", ErrInvalidPath
+	}
+	name = path.Clean("/" + name)[1:]
+	if name == "" {
+		return ".", nil
+	}
+	return name, nil
+}
+
+// Server directory, symlinks and ".." cannot escape from it
+type FS struct {
+	root *os.Root
+	mg   *Manager
+}
+
+// Close server directory
+func (fsys *FS) Close() error { return fsys.root.Close() }
+
+// Stat file without follow last symlink
+func (fsys *FS) Stat(name string) (*Entry, error) {
+	name, err := Clean(name)
+	if err != nil {
+		return nil, err
+	}
+	info, err := fsys.root.Lstat(name)
+	if err != nil {
+		return nil, err
+	}
+	return newEntry(name, info), nil
+}
+
+// Report if both names are same file, symlinks are followed
+func (fsys *FS) SameFile(a, b string) bool {
+	a, errA := Clean(a)
+	b, errB := Clean(b)
+	if errA != nil || errB != nil {
+		return false
+	}
+	infoA, errA := fsys.root.Stat(a)
+	infoB, errB := fsys.root.Stat(b)
+	return errA == nil && errB == nil && os.SameFile(infoA, infoB)
+}
+
+// List directory, directories first
+func (fsys *FS) List(name string) ([]*Entry, error) {
+	name, err := Clean(name)
+	if err != nil {
+		return nil, err
+	}
+	if info, err := fsys.root.Stat(name); err != nil {
+		return nil, err
+	} else if !info.IsDir() {
+		return nil, ErrNotDir
+	}
+	entries, err := fs.ReadDir(fsys.root.FS(), name)
+	if err != nil {
+		return nil, err
+	}
+
+	list := []*Entry{}
+	for _, entry := range entries {
+		info, err := entry.Info()
+		if err != nil {
+			continue // Removed in middle
+		}
+		list = append(list, newEntry(path.Join(name, entry.Name()), info))
+	}
+	slices.SortFunc(list, func(a, b *Entry) int {
+		if a.Dir != b.Dir {
+			if a.Dir {
+				return -1
+			}
+			return 1
+		}
+		return strings.Compare(a.Name, b.Name)
+	})
+	return list, nil
+}
+
+// Open regular file to read
+func (fsys *FS) Open(name string) (*os.File, error) {
+	name, err := Clean(name)
+	if err != nil {
+		return nil, err
+	}
+	file, err := fsys.root.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	if info, err := file.Stat(); err != nil {
+		file.Close()
+		return nil, err
+	} else if info.IsDir() {
+		file.Close()
+		return nil, ErrIsDir
+	}
+	return file, nil
+}
+
+// Bytes used by server directory, symlinks are not followed
+func (fsys *FS) Usage() (int64, error) {
+	var size int64
+	err := fs.WalkDir(fsys.root.FS(), ".", func(name string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			if errors.Is(err, fs.ErrNotExist) {
+				return nil
+			}
+			return err
+		} else if entry.Type().IsRegular() {
+			if info, err := entry.Info(); err == nil {
+				size += info.Size()
+			}
+		}
+		return nil
+	})
+	return size, err
+}
+
+// Bytes can be written to name, -1 to unlimited
+func (fsys *FS) available(name string) (int64, error) {
+	limit := int64(-1)
+	if fsys.mg.Quota > 0 {
+		used, err := fsys.Usage()
+		if err != nil {
+			return 0, fmt.Errorf("cannot get server disk usage: %s", err)
+		}
+		limit = max(fsys.mg.Quota-used, 0)
+		if info, err := fsys.root.Lstat(name); err == nil && info.Mode().IsRegular() {
+			limit += info.Size() // File is replaced
+		}
+	}
+	if fsys.mg.MaxFileSize > 0 && (limit == -1 || fsys.mg.MaxFileSize < limit) {
+		limit = fsys.mg.MaxFileSize
+	}
+	return limit, nil
+}
+
+// Check if size can be written to name
+func (fsys *FS) Reserve(name string, size int64) error {
+	name, err := Clean(name)
+	if err != nil {
+		return err
+	}
+	return fsys.reserve(name, size, 0)
+}
+
+// Check if size can be written to name with pending bytes not yet in server directory
+func (fsys *FS) reserve(name string, size, pending int64) error {
+	if fsys.mg.MaxFileSize > 0 && size > fsys.mg.MaxFileSize {
+		return ErrFileSize
+	} else if fsys.mg.Quota <= 0 {
+		return nil
+	}
+	used, err := fsys.Usage()
+	if err != nil {
+		return fmt.Errorf("cannot get server disk usage: %s", err)
+	}
+	limit := fsys.mg.Quota - used - pending
+	if info, err := fsys.root.Lstat(name); err == nil && info.Mode().IsRegular() {
+		limit += info.Size() // File is replaced
+	}
+	if size > limit {
+		return ErrQuota
+	}
+	return nil
+}
+
+// Write file from r, file is replaced only after all body is written.
+// Parent directories are created if not exists.
+func (fsys *FS) Write(name string, r io.Reader) (int64, error) {
+	name, err := Clean(name)
+	if err != nil {
+		return 0, err
+	} else if name == "." {
+		return 0, ErrIsDir
+	} else if info, err := fsys.root.Stat(name); err == nil && info.IsDir() {
+		return 0, ErrIsDir
+	}
+
+	limit, err := fsys.available(name)
+	if err != nil {
+		return 0, err
+	}
+	if err := fsys.MkdirAll(path.Dir(name)); err != nil {
+		return 0, err
+	}
+
+	var random [6]byte
+	rand.Read(random[:])
+	temp := path.Join(path.Dir(name), "."+path.Base(name)+".tmp-"+hex.EncodeToString(random[:]))
+	file, err := fsys.root.OpenFile(temp, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0644)
+	if err != nil {
+		return 0, err
+	}
+	defer fsys.root.Remove(temp) // Not exists after rename
+
+	if limit != -1 {
+		r = io.LimitReader(r, limit+1)
+	}
+	n, err := io.Copy(file, r)
+	if err != nil {
+		file.Close()
+		return n, err
+	} else if limit != -1 && n > limit {
+		file.Close()
+		if fsys.mg.MaxFileSize > 0 && n > fsys.mg.MaxFileSize {
+			return n, ErrFileSize
+		}
+		return n, ErrQuota
+	} else if err = file.Close(); err != nil {
+		return n, err
+	}
+	return n, fsys.root.Rename(temp, name)
+}
+
+// Make directory and parents
+func (fsys *FS) MkdirAll(name string) error {
+	name, err := Clean(name)
+	if err != nil {
+		return err
+	} else if name == "." {
+		return nil
+	}
+
+	current := "."
+	for part := range strings.SplitSeq(name, "/") {
+		current = path.Join(current, part)
+		if err := fsys.root.Mkdir(current, 0755); err != nil && !errors.Is(err, fs.ErrExist) {
+			return err
+		} else if info, err := fsys.root.Stat(current); err != nil {
+			return err
+		} else if !info.IsDir() {
+			return ErrNotDir
+		}
+	}
+	return nil
+}
+
+// Rename file or directory, target must not exist
+func (fsys *FS) Rename(from, to string) error {
+	from, err := Clean(from)
+	if err != nil {
+		return err
+	}
+	if to, err = Clean(to); err != nil {
+		return err
+	} else if from == "." || to == "." || from == to {
+		return ErrInvalidPath
+	} else if strings.HasPrefix(to, from+"/") {
+		return ErrInvalidPath // Move directory to itself
+	}
+
+	if _, err := fsys.root.Lstat(from); err != nil {
+		return err
+	} else if _, err := fsys.root.Lstat(to); err == nil {
+		return ErrExists
+	}
+	if err := fsys.MkdirAll(path.Dir(to)); err != nil {
+		return err
+	}
+	return fsys.root.Rename(from, to)
+}
+
+// Remove file, symlink or directory, directories with files only with recursive
+func (fsys *FS) Remove(name string, recursive bool) error {
+	name, err := Clean(name)
+	if err != nil {
+		return err
+	} else if name == "." {
+		return ErrInvalidPath
+	}
+
+	info, err := fsys.root.Lstat(name)
+	if err != nil {
+		return err
+	} else if info.IsDir() && recursive {
+		return fsys.removeAll(name)
+	}
+	if err = fsys.root.Remove(name); err != nil && info.IsDir() {
+		if entries, _ := fs.ReadDir(fsys.root.FS(), name); len(entries) > 0 {
+			return ErrNotEmpty
+		}
+	}
+	return err
+}
+
+// Remove directory tree, symlinks are removed and not followed
+func (fsys *FS) removeAll(name string) error {
+	entries, err := fs.ReadDir(fsys.root.FS(), name)
+	if err != nil {
+		return err
+	}
+	for _, entry := range entries {
+		child := path.Join(name, entry.Name())
+		if entry.IsDir() {
+			if err := fsys.removeAll(child); err != nil {
+				return err
+			}
+			continue
+		}
+		if err := fsys.root.Remove(child); err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return err
+		}
+	}
+	return fsys.root.Remove(name)
+}
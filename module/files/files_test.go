@@ -0,0 +1,146 @@
+package files
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"sirherobrine23.com.br/go-bds/bds/module/runner"
+)
+
+func TestFS(t *testing.T) {
+	root := t.TempDir()
+	manager := NewManager(runner.NewManager(filepath.Join(root, "servers"), nil), 64)
+	fsys, err := manager.Open(1)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	defer fsys.Close()
+
+	// Outside files and links to it
+	os.WriteFile(filepath.Join(root, "secret"), []byte("secret"), 0644)
+	dir := manager.Runner.Dir(1)
+	os.Symlink(filepath.Join(root, "secret"), filepath.Join(dir, "secret"))
+	os.Symlink(root, filepath.Join(dir, "outside"))
+
+	if _, err := fsys.Write("../../escape", strings.NewReader("escape")); err != nil {
+		t.Errorf("cannot write cleaned path: %s", err)
+		return
+	} else if _, err := os.Stat(filepath.Join(dir, "escape")); err != nil {
+		t.Errorf("path not confined: %s", err)
+		return
+	}
+	if _, err := fsys.Open("secret"); !IsEscape(err) {
+		t.Errorf("symlink escape opened: %v", err)
+		return
+	} else if _, err := fsys.Write("outside/new", strings.NewReader("new")); err == nil {
+		t.Errorf("write in symlink escape")
+		return
+	} else if err := fsys.Rename("escape", "outside/escape"); err == nil {
+		t.Errorf("rename to symlink escape")
+		return
+	} else if err := fsys.Remove("outside", true); err != nil {
+		t.Errorf("cannot remove symlink: %s", err)
+		return
+	} else if _, err := os.Stat(filepath.Join(root, "secret")); err != nil {
+		t.Errorf("symlink target removed")
+		return
+	}
+
+	if _, err := fsys.Write("world/level.dat", strings.NewReader("level")); err != nil {
+		t.Errorf("cannot write file: %s", err)
+		return
+	} else if err := fsys.Rename("world", "worlds/world"); err != nil {
+		t.Errorf("cannot rename: %s", err)
+		return
+	} else if _, err := fsys.List("worlds/world/level.dat"); err != ErrNotDir {
+		t.Errorf("file listed: %v", err)
+		return
+	} else if list, err := fsys.List("worlds/world"); err != nil || len(list) != 1 || list[0].Path != "worlds/world/level.dat" {
+		t.Errorf("invalid list: %v %v", list, err)
+		return
+	}
+
+	// Quota is 64 bytes
+	if _, err := fsys.Write("big", bytes.NewReader(make([]byte, 100))); err != ErrQuota {
+		t.Errorf("quota not checked: %v", err)
+		return
+	} else if _, err := fsys.Stat("big"); err == nil {
+		t.Errorf("file written over quota")
+		return
+	} else if err := fsys.Remove("worlds", false); err != ErrNotEmpty {
+		t.Errorf("not empty directory removed: %v", err)
+		return
+	} else if err := fsys.Remove("worlds", true); err != nil {
+		t.Errorf("cannot remove directory: %s", err)
+	}
+}
+
+func TestUpload(t *testing.T) {
+	manager := NewManager(runner.NewManager(t.TempDir(), nil), 64)
+	if _, err := manager.CreateUpload(1, "big", 65); err != ErrQuota {
+		t.Errorf("quota not checked: %v", err)
+		return
+	}
+
+	upload, err := manager.CreateUpload(1, "plugins/plugin.jar", 10)
+	if err != nil {
+		t.Errorf("cannot create upload: %s", err)
+		return
+	} else if _, err = manager.Upload(2, upload.ID); err != ErrUploadNotExists {
+		t.Errorf("upload from other server: %v", err)
+		return
+	}
+
+	// Connection closed in middle
+	if upload, err = manager.WriteUpload(1, upload.ID, 0, io.MultiReader(strings.NewReader("01234"), &failReader{})); err == nil || upload.Offset != 5 {
+		t.Errorf("invalid offset after fail: %+v %v", upload, err)
+		return
+	} else if _, err = manager.WriteUpload(1, upload.ID, 0, strings.NewReader("0123456789")); err != ErrUploadOffset {
+		t.Errorf("invalid offset accepted: %v", err)
+		return
+	} else if upload, err = manager.WriteUpload(1, upload.ID, 5, strings.NewReader("56789")); err != nil || !upload.Done {
+		t.Errorf("upload not done: %+v %v", upload, err)
+		return
+	}
+
+	data, _ := os.ReadFile(filepath.Join(manager.Runner.Dir(1), "plugins", "plugin.jar"))
+	if string(data) != "0123456789" {
+		t.Errorf("invalid upload file: %q", data)
+		return
+	} else if _, err = manager.Upload(1, upload.ID); err != ErrUploadNotExists {
+		t.Errorf("upload not removed: %v", err)
+		return
+	}
+
+	// Uploads in progress count to quota
+	if upload, err = manager.CreateUpload(1, "world.zip", 40); err != nil {
+		t.Errorf("cannot create upload: %s", err)
+		return
+	} else if _, err = manager.CreateUpload(1, "world2.zip", 40); err != ErrQuota {
+		t.Errorf("pending upload not counted to quota: %v", err)
+		return
+	} else if err = manager.CancelUpload(1, upload.ID); err != nil {
+		t.Errorf("cannot cancel upload: %s", err)
+		return
+	}
+
+	manager.MaxUploads = 2
+	for range manager.MaxUploads {
+		if _, err = manager.CreateUpload(1, "empty", 0); err != nil {
+			t.Errorf("cannot create upload: %s", err)
+			return
+		}
+	}
+	if _, err = manager.CreateUpload(1, "empty", 0); err != ErrTooManyUploads {
+		t.Errorf("uploads limit not checked: %v", err)
+	}
+}
+
+type failReader struct{}
+
+func (failReader) Read([]byte) (int, error) { return 0, io.ErrUnexpectedEOF }
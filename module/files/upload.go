@@ -0,0 +1,247 @@
+package files
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var (
+	ErrUploadNotExists error = errors.New("upload not exists")
+	ErrUploadOffset    error = errors.New("upload offset not match")
+	ErrUploadSize      error = errors.New("upload body bigger than declared size")
+	ErrTooManyUploads  error = errors.New("too many uploads in progress")
+
+	UploadTTL         = time.Hour * 24 // Incomplete uploads older than this are removed by [Manager.CleanUploads]
+	DefaultMaxUploads = 4              // Uploads in progress to each server
+)
+
+// Resumable upload, parts are appended until Offset is Size
+type Upload struct {
+	ID       string    `json:"id"`
+	ServerID int64     `json:"server_id"`
+	Path     string    `json:"path"`   // Target path in server directory
+	Size     int64     `json:"size"`   // Final file size
+	Offset   int64     `json:"offset"` // Bytes received
+	Done     bool      `json:"done"`   // File moved to server directory
+	CreateAt time.Time `json:"create_at"`
+}
+
+var uploadsLock sync.Map // Upload ID -> *sync.Mutex
+
+func lockUpload(id string) func() {
+	mu, _ := uploadsLock.LoadOrStore(id, &sync.Mutex{})
+	mu.(*sync.Mutex).Lock()
+	return mu.(*sync.Mutex).Unlock
+}
+
+func (mg *Manager) uploadPath(id string) (string, error) {
+	if _, err := uuid.Parse(id); err != nil {
+		return "", ErrUploadNotExists
+	}
+	return filepath.Join(mg.UploadDir, id), nil
+}
+
+// Count uploads in progress to server and sum of declared sizes
+func (mg *Manager) pendingUploads(serverID int64) (int, int64, error) {
+	entries, err := os.ReadDir(mg.UploadDir)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return 0, 0, nil
+		}
+		return 0, 0, err
+	}
+	count, size := 0, int64(0)
+	for _, entry := range entries {
+		id, ok := strings.CutSuffix(entry.Name(), ".json")
+		if !ok {
+			continue
+		}
+		upload, err := mg.Upload(serverID, id)
+		if err == ErrUploadNotExists {
+			continue
+		} else if err != nil {
+			return 0, 0, err
+		}
+		count++
+		size += upload.Size
+	}
+	return count, size, nil
+}
+
+// Start new upload to server, quota is checked with declared size plus declared size of uploads in progress
+func (mg *Manager) CreateUpload(serverID int64, name string, size int64) (*Upload, error) {
+	name, err := Clean(name)
+	if err != nil {
+		return nil, err
+	} else if name == "." || size < 0 {
+		return nil, ErrInvalidPath
+	}
+
+	fsys, err := mg.Open(serverID)
+	if err != nil {
+		return nil, err
+	}
+	defer fsys.Close()
+	if info, err := fsys.root.Stat(name); err == nil && info.IsDir() {
+		return nil, ErrIsDir
+	}
+
+	mg.uploadsMu.Lock()
+	defer mg.uploadsMu.Unlock()
+	count, pending, err := mg.pendingUploads(serverID)
+	if err != nil {
+		return nil, fmt.Errorf("cannot list uploads: %s", err)
+	} else if mg.MaxUploads > 0 && count >= mg.MaxUploads {
+		return nil, ErrTooManyUploads
+	} else if err = fsys.reserve(name, size, pending); err != nil {
+		return nil, err
+	}
+
+	upload := &Upload{ID: uuid.NewString(), ServerID: serverID, Path: name, Size: size, CreateAt: time.Now()}
+	file, _ := mg.uploadPath(upload.ID)
+	if err := os.MkdirAll(mg.UploadDir, 0700); err != nil {
+		return nil, fmt.Errorf("cannot make uploads directory: %s", err)
+	}
+	data, _ := json.Marshal(upload)
+	if err := os.WriteFile(file+".json", data, 0600); err != nil {
+		return nil, err
+	} else if err := os.WriteFile(file, nil, 0600); err != nil {
+		os.Remove(file + ".json")
+		return nil, err
+	}
+	return upload, nil
+}
+
+// Get upload status
+func (mg *Manager) Upload(serverID int64, id string) (*Upload, error) {
+	file, err := mg.uploadPath(id)
+	if err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(file + ".json")
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil, ErrUploadNotExists
+		}
+		return nil, err
+	}
+
+	var upload Upload
+	if err := json.Unmarshal(data, &upload); err != nil {
+		return nil, err
+	} else if upload.ServerID != serverID {
+		return nil, ErrUploadNotExists
+	}
+	info, err := os.Stat(file)
+	if err != nil {
+		return nil, ErrUploadNotExists
+	}
+	upload.Offset = info.Size()
+	return &upload, nil
+}
+
+// Append part at offset, offset must be current upload offset so client can resume after fail.
+// When all bytes are received file is moved to server directory.
+func (mg *Manager) WriteUpload(serverID int64, id string, offset int64, r io.Reader) (*Upload, error) {
+	defer lockUpload(id)()
+	upload, err := mg.Upload(serverID, id)
+	if err != nil {
+		return nil, err
+	} else if offset != upload.Offset {
+		return upload, ErrUploadOffset
+	}
+
+	file, _ := mg.uploadPath(id)
+	part, err := os.OpenFile(file, os.O_WRONLY|os.O_APPEND, 0600)
+	if err != nil {
+		return nil, err
+	}
+	n, err := io.Copy(part, io.LimitReader(r, upload.Size-upload.Offset+1))
+	if n > upload.Size-upload.Offset {
+		part.Truncate(upload.Size)
+		part.Close()
+		return upload, ErrUploadSize
+	}
+	upload.Offset += n
+	if closeErr := part.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil || upload.Offset < upload.Size {
+		return upload, err // Client resume from saved offset
+	}
+
+	if err = mg.finishUpload(upload, file); err != nil {
+		return upload, err
+	}
+	upload.Done = true
+	return upload, nil
+}
+
+// Move upload to server directory
+func (mg *Manager) finishUpload(upload *Upload, file string) error {
+	fsys, err := mg.Open(upload.ServerID)
+	if err != nil {
+		return err
+	}
+	defer fsys.Close()
+
+	part, err := os.Open(file)
+	if err != nil {
+		return err
+	}
+	defer part.Close()
+	if _, err = fsys.Write(upload.Path, part); err != nil {
+		return err
+	}
+	os.Remove(file + ".json")
+	os.Remove(file)
+	uploadsLock.Delete(upload.ID)
+	return nil
+}
+
+// Cancel upload and remove received parts
+func (mg *Manager) CancelUpload(serverID int64, id string) error {
+	defer lockUpload(id)()
+	if _, err := mg.Upload(serverID, id); err != nil {
+		return err
+	}
+	file, _ := mg.uploadPath(id)
+	os.Remove(file + ".json")
+	uploadsLock.Delete(id)
+	return os.Remove(file)
+}
+
+// Remove uploads not finished in [UploadTTL]
+func (mg *Manager) CleanUploads(now time.Time) error {
+	entries, err := os.ReadDir(mg.UploadDir)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil
+		}
+		return err
+	}
+	for _, entry := range entries {
+		id, ok := strings.CutSuffix(entry.Name(), ".json")
+		if !ok {
+			continue
+		}
+		// Last part write
+		file := filepath.Join(mg.UploadDir, id)
+		if info, err := os.Stat(file); err == nil && now.Sub(info.ModTime()) <= UploadTTL {
+			continue
+		}
+		os.Remove(file + ".json")
+		os.Remove(file)
+	}
+	return nil
+}
@@ -0,0 +1,147 @@
+// Parse Bedrock and Java server console lines to structured events
+package logparser
+
+import (
+	"regexp"
+	"strings"
+	"sync"
+)
+
+// Event type
+type EventType string
+
+const (
+	ServerStarting EventType = "server_starting" // Server process loading
+	ServerStarted  EventType = "server_started"  // Server accept players
+	ServerStopping EventType = "server_stopping" // Server stopping
+	VersionBanner  EventType = "version"         // Server version printed
+	PlayerJoined   EventType = "player_joined"   // Player connected to server
+	PlayerLeft     EventType = "player_left"     // Player disconnected
+	Chat           EventType = "chat"            // Player chat message
+	Error          EventType = "error"           // Error or fatal line
+)
+
+// Event parsed from log line
+type Event struct {
+	Type    EventType `json:"type"`              // Event type
+	Level   string    `json:"level"`             // Line level, "INFO", "WARN", "ERROR"
+	Player  string    `json:"player,omitempty"`  // Player username
+	XUID    string    `json:"xuid,omitempty"`    // Bedrock player XUID
+	UUID    string    `json:"uuid,omitempty"`    // Java player UUID
+	Version string    `json:"version,omitempty"` // Server version
+	Message string    `json:"message,omitempty"` // Chat message, error text or reason
+	Line    string    `json:"line"`              // Original line
+}
+
+var (
+	// "[2024-06-12 10:00:00:123 INFO] message"
+	bedrockPrefix = regexp.MustCompile(`^\[\d{4}-\d{2}-\d{2} \d{2}:\d{2}:\d{2}(?::\d+)? ([A-Z]+)\] ?(.*)$`)
+	// "[12:00:00] [Server thread/INFO]: message" or Paper "[12:00:00 INFO]: message"
+	javaPrefix = regexp.MustCompile(`^\[\d{2}:\d{2}:\d{2}(?: ([A-Z]+))?\](?: \[[^\]]*?/([A-Z]+)\])?: ?(.*)$`)
+
+	bedrockVersion      = regexp.MustCompile(`^Version:? (\d+(?:\.\d+)+)`)
+	bedrockConnected    = regexp.MustCompile(`^Player connected: (.+?), xuid: (\d*)`)
+	bedrockDisconnected = regexp.MustCompile(`^Player disconnected: (.+?), xuid: (\d*)`)
+
+	javaVersion    = regexp.MustCompile(`^Starting minecraft server version (\S+)`)
+	javaPlayerUUID = regexp.MustCompile(`^UUID of player (\S+) is ([0-9a-fA-F-]{32,36})`)
+	javaJoined     = regexp.MustCompile(`^(\S+) joined the game`)
+	javaLeft       = regexp.MustCompile(`^(\S+) left the game`)
+	javaChat       = regexp.MustCompile(`^(?:\[Not Secure\] )?<([^>]+)> (.*)$`)
+	javaDone       = regexp.MustCompile(`^Done \([0-9.,]+s\)!`)
+)
+
+// Stateful parser to one server, keep Java UUIDs until player join
+type Parser struct {
+	Bedrock bool // Parse Bedrock format
+
+	mu    sync.Mutex
+	uuids map[string]string
+}
+
+// Create parser to server software, "bedrock" parse Bedrock lines and any other Java lines
+func New(software string) *Parser {
+	return &Parser{Bedrock: strings.EqualFold(software, "bedrock"), uuids: map[string]string{}}
+}
+
+// Parse line, return false if line not have event
+func (parser *Parser) Parse(line string) (*Event, bool) {
+	line = strings.TrimRight(line, "\r\n")
+	if parser.Bedrock {
+		return parser.parseBedrock(line)
+	}
+	return parser.parseJava(line)
+}
+
+func (parser *Parser) parseBedrock(line string) (*Event, bool) {
+	match := bedrockPrefix.FindStringSubmatch(line)
+	if match == nil {
+		return nil, false
+	}
+	event := &Event{Level: match[1], Line: line}
+	msg := match[2]
+
+	switch {
+	case event.Level == "ERROR" || event.Level == "FATAL":
+		event.Type, event.Message = Error, msg
+	case msg == "Starting Server":
+		event.Type = ServerStarting
+	case msg == "Server started.":
+		event.Type = ServerStarted
+	case msg == "Stopping server..." || msg == "Server stop requested.":
+		event.Type = ServerStopping
+	default:
+		if m := bedrockVersion.FindStringSubmatch(msg); m != nil {
+			event.Type, event.Version = VersionBanner, m[1]
+		} else if m := bedrockConnected.FindStringSubmatch(msg); m != nil {
+			event.Type, event.Player, event.XUID = PlayerJoined, m[1], m[2]
+		} else if m := bedrockDisconnected.FindStringSubmatch(msg); m != nil {
+			event.Type, event.Player, event.XUID = PlayerLeft, m[1], m[2]
+		} else {
+			return nil, false
+		}
+	}
+	return event, true
+}
+
+func (parser *Parser) parseJava(line string) (*Event, bool) {
+	match := javaPrefix.FindStringSubmatch(line)
+	if match == nil {
+		return nil, false
+	}
+	event := &Event{Level: match[1], Line: line}
+	if event.Level == "" {
+		event.Level = match[2]
+	}
+	msg := match[3]
+
+	parser.mu.Lock()
+	defer parser.mu.Unlock()
+	switch {
+	case event.Level == "ERROR" || event.Level == "FATAL" || event.Level == "SEVERE":
+		event.Type, event.Message = Error, msg
+	case msg == "Stopping server" || msg == "Stopping the server":
+		event.Type = ServerStopping
+	case strings.HasPrefix(msg, "Starting Minecraft server on"):
+		event.Type = ServerStarting
+	case javaDone.MatchString(msg):
+		event.Type = ServerStarted
+	default:
+		if m := javaVersion.FindStringSubmatch(msg); m != nil {
+			event.Type, event.Version = VersionBanner, m[1]
+		} else if m := javaPlayerUUID.FindStringSubmatch(msg); m != nil {
+			parser.uuids[m[1]] = m[2] // Wait join line
+			return nil, false
+		} else if m := javaJoined.FindStringSubmatch(msg); m != nil {
+			event.Type, event.Player, event.UUID = PlayerJoined, m[1], parser.uuids[m[1]]
+		} else if m := javaLeft.FindStringSubmatch(msg); m != nil {
+			event.Type, event.Player, event.UUID = PlayerLeft, m[1], parser.uuids[m[1]]
+			delete(parser.uuids, m[1])
+		} else if m := javaChat.FindStringSubmatch(msg); m != nil {
+			event.Type, event.Player, event.Message = Chat, m[1], m[2]
+		} else {
+			return nil, false
+		}
+	}
+	return event, true
+}
@@ -0,0 +1,88 @@
+package logparser
+
+import (
+	"bufio"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestParser(t *testing.T) {
+	for _, test := range []struct {
+		Software string
+		Fixture  string
+		Events   []Event
+	}{
+		{
+			Software: "bedrock",
+			Fixture:  "bedrock.log",
+			Events: []Event{
+				{Type: ServerStarting, Level: "INFO"},
+				{Type: VersionBanner, Level: "INFO", Version: "1.21.2.02"},
+				{Type: Error, Level: "ERROR", Message: "Error opening whitelist file: whitelist.json"},
+				{Type: ServerStarted, Level: "INFO"},
+				{Type: PlayerJoined, Level: "INFO", Player: "Steve", XUID: "2535412345678901"},
+				{Type: PlayerJoined, Level: "INFO", Player: "Alex Two", XUID: "2535498765432109"},
+				{Type: PlayerLeft, Level: "INFO", Player: "Steve", XUID: "2535412345678901"},
+				{Type: ServerStopping, Level: "INFO"},
+				{Type: ServerStopping, Level: "INFO"},
+			},
+		},
+		{
+			Software: "java",
+			Fixture:  "java.log",
+			Events: []Event{
+				{Type: VersionBanner, Level: "INFO", Version: "1.21.1"},
+				{Type: ServerStarting, Level: "INFO"},
+				{Type: ServerStarted, Level: "INFO"},
+				{Type: PlayerJoined, Level: "INFO", Player: "Steve", UUID: "069a79f4-44e9-4726-a5be-fca90e38aaf5"},
+				{Type: Chat, Level: "INFO", Player: "Steve", Message: "hello world"},
+				{Type: Error, Level: "ERROR", Message: "Encountered an unexpected exception"},
+				{Type: PlayerLeft, Level: "INFO", Player: "Steve", UUID: "069a79f4-44e9-4726-a5be-fca90e38aaf5"},
+				{Type: ServerStopping, Level: "INFO"},
+				{Type: ServerStopping, Level: "INFO"},
+			},
+		},
+		{
+			Software: "paper",
+			Fixture:  "paper.log",
+			Events: []Event{
+				{Type: VersionBanner, Level: "INFO", Version: "1.20.4"},
+				{Type: ServerStarting, Level: "INFO"},
+				{Type: ServerStarted, Level: "INFO"},
+				{Type: PlayerJoined, Level: "INFO", Player: "Alex", UUID: "61699b2e-d327-4a01-9f1e-0ea8c3f06bc6"},
+				{Type: Chat, Level: "INFO", Player: "Alex", Message: "anyone here?"},
+				{Type: Error, Level: "SEVERE", Message: "Could not load 'plugins/broken.jar' in folder 'plugins'"},
+				{Type: PlayerLeft, Level: "INFO", Player: "Alex", UUID: "61699b2e-d327-4a01-9f1e-0ea8c3f06bc6"},
+			},
+		},
+	} {
+		t.Run(test.Software, func(t *testing.T) {
+			file, err := os.Open(filepath.Join("testdata", test.Fixture))
+			if err != nil {
+				t.Errorf("cannot open fixture: %s", err)
+				return
+			}
+			defer file.Close()
+
+			var events []Event
+			parser, scan := New(test.Software), bufio.NewScanner(file)
+			for scan.Scan() {
+				if event, ok := parser.Parse(scan.Text()); ok {
+					event.Line = "" // Ignore original line in compare
+					events = append(events, *event)
+				}
+			}
+
+			if len(events) != len(test.Events) {
+				t.Errorf("expected %d events, got %d: %+v", len(test.Events), len(events), events)
+				return
+			}
+			for index, event := range events {
+				if event != test.Events[index] {
+					t.Errorf("event %d:\n\texpected %+v\n\tgot      %+v", index, test.Events[index], event)
+				}
+			}
+		})
+	}
+}
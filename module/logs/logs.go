@@ -0,0 +1,483 @@
+// Persistent server logs storage, one directory to each server run
+package logs
+
+import (
+	"bufio"
+	"compress/gzip"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"sirherobrine23.com.br/go-bds/bds/module/db"
+	"sirherobrine23.com.br/go-bds/bds/module/runner"
+	"sirherobrine23.com.br/go-bds/bds/module/server"
+)
+
+var (
+	ErrRunNotExists error = errors.New("log run not exists")
+
+	DefaultMaxSegment int64 = 10 * 1024 * 1024 // Rotate log file after this size
+)
+
+// Log levels
+const (
+	LevelInfo  = "info"
+	LevelWarn  = "warn"
+	LevelError = "error"
+	LevelDebug = "debug"
+)
+
+// Log line storaged
+type Entry struct {
+	Time   time.Time     `json:"time"`   // Line time
+	Stream runner.Stream `json:"stream"` // stdout, stderr or stdin
+	Level  string        `json:"level"`  // Line level
+	Text   string        `json:"line"`   // Line text
+}
+
+// Server run logs
+type Run struct {
+	ID       string    `json:"id"`        // Run ID, start time in unix milliseconds
+	ServerID int64     `json:"server_id"` // Server ID
+	StartAt  time.Time `json:"start_at"`  // Process start
+	EndAt    time.Time `json:"end_at"`    // Last write to run
+	Size     int64     `json:"size"`      // Size of all files in disk
+}
+
+// Logs storage in disk
+type Store struct {
+	Root       string // Root directory to logs
+	MaxSegment int64  // Max file size before rotate, if <= 0 use [DefaultMaxSegment]
+}
+
+// Create new log storage
+func NewStore(root string) *Store {
+	return &Store{Root: root, MaxSegment: DefaultMaxSegment}
+}
+
+func (store *Store) serverDir(serverID int64) string {
+	return filepath.Join(store.Root, strconv.FormatInt(serverID, 10))
+}
+
+// Level names in line prefix, "[2024-01-01 00:00:00:000 INFO]" or "[00:00:00] [Server thread/WARN]"
+var levelTags = []struct {
+	level string
+	tags  []string
+}{
+	{LevelError, []string{"ERROR]", "FATAL]", "SEVERE]"}},
+	{LevelWarn, []string{"WARN]", "WARNING]"}},
+	{LevelDebug, []string{"DEBUG]", "TRACE]"}},
+	{LevelInfo, []string{"INFO]"}},
+}
+
+// Detect log level from server line
+func DetectLevel(stream runner.Stream, text string) string {
+	if len(text) > 64 {
+		text = text[:64]
+	}
+	text = strings.ToUpper(text)
+	for _, level := range levelTags {
+		for _, tag := range level.tags {
+			if strings.Contains(text, tag) {
+				return level.level
+			}
+		}
+	}
+	if stream == runner.Stderr {
+		return LevelError
+	}
+	return LevelInfo
+}
+
+// Return hook to [runner.Manager.OnStart], prune old runs with database policy and record new run
+func (store *Store) OnStart(database db.Database) func(*runner.Process) {
+	return func(proc *runner.Process) {
+		if policy, err := database.LogRetention(proc.Server.ID); err == nil {
+			store.Prune(proc.Server.ID, policy)
+		}
+		store.Attach(proc)
+	}
+}
+
+// Record process output to new run until process exit
+func (store *Store) Attach(proc *runner.Process) error {
+	run, err := store.NewRun(proc.Server.ID, proc.StartAt)
+	if err != nil {
+		return err
+	}
+	// Write lines in background to not block output with disk writes
+	queue := proc.Output.Queue()
+	go func() {
+		defer run.Close()
+		for lines, ok := queue.Next(proc.Done()); ok; lines, ok = queue.Next(proc.Done()) {
+			for _, line := range lines {
+				run.Write(line)
+			}
+		}
+	}()
+	return nil
+}
+
+// Writer to server run
+type RunWriter struct {
+	store   *Store
+	dir     string
+	mu      sync.Mutex
+	file    *os.File
+	buf     *bufio.Writer
+	size    int64
+	segment int
+	err     error
+}
+
+// Create new run to server
+func (store *Store) NewRun(serverID int64, startAt time.Time) (*RunWriter, error) {
+	dir := filepath.Join(store.serverDir(serverID), strconv.FormatInt(startAt.UnixMilli(), 10))
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, fmt.Errorf("cannot make log directory: %s", err)
+	}
+
+	run := &RunWriter{store: store, dir: dir}
+	if err := run.openSegment(); err != nil {
+		return nil, err
+	}
+	return run, nil
+}
+
+func segmentName(n int) string { return fmt.Sprintf("%06d.log", n) }
+
+func (run *RunWriter) openSegment() (err error) {
+	if run.file, err = os.Create(filepath.Join(run.dir, segmentName(run.segment))); err != nil {
+		return fmt.Errorf("cannot create log file: %s", err)
+	}
+	run.buf, run.size = bufio.NewWriter(run.file), 0
+	return nil
+}
+
+// Close current segment and compress in background
+func (run *RunWriter) closeSegment(wait bool) error {
+	if err := run.buf.Flush(); err != nil {
+		return err
+	} else if err := run.file.Close(); err != nil {
+		return err
+	}
+
+	name := run.file.Name()
+	if wait {
+		return compressFile(name)
+	}
+	go compressFile(name)
+	return nil
+}
+
+// Compress file to name.gz and remove original file
+func compressFile(name string) error {
+	src, err := os.Open(name)
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+
+	dst, err := os.Create(name + ".gz.tmp")
+	if err != nil {
+		return err
+	}
+	defer dst.Close()
+
+	gz := gzip.NewWriter(dst)
+	if _, err = io.Copy(gz, src); err != nil {
+		return err
+	} else if err = gz.Close(); err != nil {
+		return err
+	} else if err = dst.Close(); err != nil {
+		return err
+	} else if err = os.Rename(name+".gz.tmp", name+".gz"); err != nil {
+		return err
+	}
+	return os.Remove(name)
+}
+
+// Append line to run, rotate file if necessary
+func (run *RunWriter) Write(line runner.Line) error {
+	run.mu.Lock()
+	defer run.mu.Unlock()
+	if run.err != nil {
+		return run.err
+	}
+
+	data, err := json.Marshal(Entry{
+		Time:   line.Time,
+		Stream: line.Stream,
+		Level:  DetectLevel(line.Stream, line.Text),
+		Text:   line.Text,
+	})
+	if err != nil {
+		return err
+	}
+
+	n, err := run.buf.Write(append(data, '\n'))
+	if run.size += int64(n); err != nil {
+		run.err = err
+		return err
+	}
+
+	maxSize := run.store.MaxSegment
+	if maxSize <= 0 {
+		maxSize = DefaultMaxSegment
+	}
+	if run.size >= maxSize {
+		if run.err = run.closeSegment(false); run.err != nil {
+			return run.err
+		}
+		run.segment++
+		run.err = run.openSegment()
+	}
+	return run.err
+}
+
+// Flush and compress last segment
+func (run *RunWriter) Close() error {
+	run.mu.Lock()
+	defer run.mu.Unlock()
+	if run.err != nil {
+		return run.err
+	}
+	run.err = os.ErrClosed
+	return run.closeSegment(true)
+}
+
+// List server runs, newest first
+func (store *Store) Runs(serverID int64) ([]*Run, error) {
+	entries, err := os.ReadDir(store.serverDir(serverID))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	var runs []*Run
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+		run, err := store.Run(serverID, entry.Name())
+		if err != nil {
+			continue // Ignore invalid directories
+		}
+		runs = append(runs, run)
+	}
+	slices.SortFunc(runs, func(a, b *Run) int { return b.StartAt.Compare(a.StartAt) })
+	return runs, nil
+}
+
+// Get run info
+func (store *Store) Run(serverID int64, runID string) (*Run, error) {
+	startMilli, err := strconv.ParseInt(runID, 10, 64)
+	if err != nil {
+		return nil, ErrRunNotExists
+	}
+
+	files, err := store.segments(serverID, runID)
+	if err != nil {
+		return nil, err
+	}
+
+	run := &Run{ID: runID, ServerID: serverID, StartAt: time.UnixMilli(startMilli)}
+	run.EndAt = run.StartAt
+	for _, name := range files {
+		stat, err := os.Stat(name)
+		if err != nil {
+			continue
+		}
+		run.Size += stat.Size()
+		if stat.ModTime().After(run.EndAt) {
+			run.EndAt = stat.ModTime()
+		}
+	}
+	return run, nil
+}
+
+// Return run files in order
+func (store *Store) segments(serverID int64, runID string) ([]string, error) {
+	dir := filepath.Join(store.serverDir(serverID), filepath.Base(runID))
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, ErrRunNotExists
+		}
+		return nil, err
+	}
+
+	var files []string
+	for _, entry := range entries {
+		name := entry.Name()
+		if !entry.IsDir() && (strings.HasSuffix(name, ".log") || strings.HasSuffix(name, ".log.gz")) {
+			// Skip plain file if compressed already exists
+			if strings.HasSuffix(name, ".log") && slices.ContainsFunc(entries, func(e os.DirEntry) bool { return e.Name() == name+".gz" }) {
+				continue
+			}
+			files = append(files, filepath.Join(dir, name))
+		}
+	}
+	slices.Sort(files) // 000000.log, 000000.log.gz, 000001.log ...
+	return files, nil
+}
+
+// Open run to read log lines in json format, one [Entry] per line
+func (store *Store) Open(serverID int64, runID string) (io.ReadCloser, error) {
+	files, err := store.segments(serverID, runID)
+	if err != nil {
+		return nil, err
+	}
+
+	pr, pw := io.Pipe()
+	go func() {
+		for _, name := range files {
+			if err := copySegment(pw, name); err != nil {
+				pw.CloseWithError(err)
+				return
+			}
+		}
+		pw.Close()
+	}()
+	return pr, nil
+}
+
+func copySegment(w io.Writer, name string) error {
+	file, err := os.Open(name)
+	if os.IsNotExist(err) && strings.HasSuffix(name, ".log") {
+		name += ".gz" // Compressed after list
+		file, err = os.Open(name)
+	}
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	var r io.Reader = file
+	if strings.HasSuffix(name, ".gz") {
+		gz, err := gzip.NewReader(file)
+		if err != nil {
+			return err
+		}
+		defer gz.Close()
+		r = gz
+	}
+	_, err = io.Copy(w, r)
+	return err
+}
+
+// Search options
+type Filter struct {
+	From   time.Time // Lines after this time, ignored if zero
+	To     time.Time // Lines before this time, ignored if zero
+	Levels []string  // Levels to return, all if empty
+	Text   string    // Case-insensitive text to find in line
+	RunID  string    // Only this run, all if empty
+	Limit  int       // Max lines returned, if <= 0 return all
+}
+
+// Entry match filter
+func (filter Filter) Match(entry Entry) bool {
+	if !filter.From.IsZero() && entry.Time.Before(filter.From) {
+		return false
+	} else if !filter.To.IsZero() && entry.Time.After(filter.To) {
+		return false
+	} else if len(filter.Levels) > 0 && !slices.Contains(filter.Levels, entry.Level) {
+		return false
+	} else if filter.Text != "" && !strings.Contains(strings.ToLower(entry.Text), strings.ToLower(filter.Text)) {
+		return false
+	}
+	return true
+}
+
+// Search log lines in server runs, oldest first
+func (store *Store) Search(serverID int64, filter Filter) ([]Entry, error) {
+	runs, err := store.Runs(serverID)
+	if err != nil {
+		return nil, err
+	}
+	slices.Reverse(runs)
+
+	entries := []Entry{}
+	for _, run := range runs {
+		if filter.RunID != "" && run.ID != filter.RunID {
+			continue
+		} else if !filter.From.IsZero() && run.EndAt.Before(filter.From) {
+			continue
+		} else if !filter.To.IsZero() && run.StartAt.After(filter.To) {
+			continue
+		}
+
+		if entries, err = store.searchRun(serverID, run.ID, filter, entries); err != nil {
+			return nil, err
+		} else if filter.Limit > 0 && len(entries) >= filter.Limit {
+			break
+		}
+	}
+	return entries, nil
+}
+
+func (store *Store) searchRun(serverID int64, runID string, filter Filter, entries []Entry) ([]Entry, error) {
+	r, err := store.Open(serverID, runID)
+	if err != nil {
+		return entries, err
+	}
+	defer r.Close()
+
+	scan := bufio.NewScanner(r)
+	scan.Buffer(make([]byte, 0, 64*1024), 2*1024*1024)
+	for scan.Scan() {
+		var entry Entry
+		if err := json.Unmarshal(scan.Bytes(), &entry); err != nil {
+			continue // Ignore partial line in crash
+		} else if filter.Match(entry) {
+			if entries = append(entries, entry); filter.Limit > 0 && len(entries) >= filter.Limit {
+				break
+			}
+		}
+	}
+	return entries, scan.Err()
+}
+
+// Delete run from disk
+func (store *Store) DeleteRun(serverID int64, runID string) error {
+	if _, err := strconv.ParseInt(runID, 10, 64); err != nil {
+		return ErrRunNotExists
+	}
+	return os.RemoveAll(filepath.Join(store.serverDir(serverID), runID))
+}
+
+// Remove runs not match with retention policy, current run is never removed
+func (store *Store) Prune(serverID int64, policy *server.LogRetention) error {
+	if policy == nil {
+		return nil
+	}
+
+	runs, err := store.Runs(serverID)
+	if err != nil {
+		return err
+	}
+
+	for index, run := range runs {
+		if index == 0 {
+			continue // Keep newest run
+		}
+		expired := policy.MaxAge > 0 && time.Since(run.EndAt) > policy.MaxAge
+		if expired || (policy.MaxRuns > 0 && index >= policy.MaxRuns) {
+			if err := store.DeleteRun(serverID, run.ID); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,78 @@
+package logs
+
+import (
+	"testing"
+	"time"
+
+	"sirherobrine23.com.br/go-bds/bds/module/runner"
+	"sirherobrine23.com.br/go-bds/bds/module/server"
+)
+
+func TestStore(t *testing.T) {
+	store := &Store{Root: t.TempDir(), MaxSegment: 256}
+	startAt := time.Now().Add(-time.Hour)
+
+	run, err := store.NewRun(1, startAt)
+	if err != nil {
+		t.Errorf("cannot create run: %s", err)
+		return
+	}
+	for i := range 20 {
+		line := runner.Line{Time: startAt.Add(time.Duration(i) * time.Minute), Stream: runner.Stdout, Text: "[2024-01-01 00:00:00:000 INFO] Server line"}
+		if i%5 == 0 {
+			line.Text = "[2024-01-01 00:00:00:000 ERROR] Something failed"
+		}
+		if err := run.Write(line); err != nil {
+			t.Errorf("cannot write line: %s", err)
+			return
+		}
+	}
+	if err := run.Close(); err != nil {
+		t.Errorf("cannot close run: %s", err)
+		return
+	}
+
+	entries, err := store.Search(1, Filter{Levels: []string{LevelError}, Text: "FAILED"})
+	if err != nil {
+		t.Errorf("cannot search: %s", err)
+		return
+	} else if len(entries) != 4 {
+		t.Errorf("expected 4 error lines, got %d", len(entries))
+		return
+	}
+
+	entries, err = store.Search(1, Filter{From: startAt.Add(10 * time.Minute), To: startAt.Add(14 * time.Minute)})
+	if err != nil {
+		t.Errorf("cannot search: %s", err)
+		return
+	} else if len(entries) != 5 {
+		t.Errorf("expected 5 lines in range, got %d", len(entries))
+		return
+	}
+
+	if _, err = store.NewRun(1, time.Now()); err != nil {
+		t.Errorf("cannot create run: %s", err)
+		return
+	}
+	if err := store.Prune(1, &server.LogRetention{MaxRuns: 1}); err != nil {
+		t.Errorf("cannot prune: %s", err)
+		return
+	}
+	if runs, _ := store.Runs(1); len(runs) != 1 {
+		t.Errorf("expected 1 run after prune, got %d", len(runs))
+	}
+}
+
+func TestDetectLevel(t *testing.T) {
+	for line, level := range map[string]string{
+		"[2024-01-01 12:00:00:000 INFO] Server started.":         LevelInfo,
+		"[2024-01-01 12:00:00:000 WARN] Experimental gameplay":   LevelWarn,
+		"[12:00:00] [Server thread/ERROR]: Encountered an error": LevelError,
+		"[12:00:00] [Worker-Main-1/WARN]: Can't keep up!":        LevelWarn,
+		"NO LOG FILE! - setting up server logging...":            LevelInfo,
+	} {
+		if got := DetectLevel(runner.Stdout, line); got != level {
+			t.Errorf("%q: expected %s, got %s", line, level, got)
+		}
+	}
+}
@@ -0,0 +1,33 @@
+// Compare Minecraft numeric versions like "1.21.2.02" or "1.20.4"
+package mcversion
+
+import (
+	"strconv"
+	"strings"
+)
+
+// Compare versions, return -1, 0 or 1 and false if versions are not numeric
+func Compare(a, b string) (int, bool) {
+	partsA, partsB := strings.Split(a, "."), strings.Split(b, ".")
+	for index := range max(len(partsA), len(partsB)) {
+		var numA, numB int
+		var err error
+		if index < len(partsA) {
+			if numA, err = strconv.Atoi(partsA[index]); err != nil {
+				return 0, false
+			}
+		}
+		if index < len(partsB) {
+			if numB, err = strconv.Atoi(partsB[index]); err != nil {
+				return 0, false
+			}
+		}
+		if numA != numB {
+			if numA < numB {
+				return -1, true
+			}
+			return 1, true
+		}
+	}
+	return 0, true
+}
@@ -0,0 +1,20 @@
+package mcversion
+
+import "testing"
+
+func TestCompare(t *testing.T) {
+	for _, test := range []struct {
+		A, B string
+		Cmp  int
+		Ok   bool
+	}{
+		{"1.21.2.02", "1.21.2.02", 0, true},
+		{"1.21.2.02", "1.21.10.01", -1, true},
+		{"1.20.4", "1.20", 1, true},
+		{"24w14a", "1.20", 0, false},
+	} {
+		if cmp, ok := Compare(test.A, test.B); cmp != test.Cmp || ok != test.Ok {
+			t.Errorf("Compare(%q, %q) = %d, %v, expected %d, %v", test.A, test.B, cmp, ok, test.Cmp, test.Ok)
+		}
+	}
+}
@@ -0,0 +1,255 @@
+// Servers network ports: unique ports to servers in runner and start conflicts
+package network
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
+
+	"sirherobrine23.com.br/go-bds/bds/module/db"
+	"sirherobrine23.com.br/go-bds/bds/module/properties"
+	"sirherobrine23.com.br/go-bds/bds/module/runner"
+	"sirherobrine23.com.br/go-bds/bds/module/server"
+)
+
+// Default runner ports range
+const (
+	DefaultMin = 19132
+	DefaultMax = 19332
+)
+
+// server.properties keys
+const (
+	PortKey     = "server-port"
+	PortV6Key   = "server-portv6"
+	RCONPortKey = "rcon.port"
+)
+
+var (
+	ErrNoPorts      error = errors.New("no free ports in runner range")
+	ErrInvalidRange error = errors.New("invalid ports range")
+	ErrPortConflict error = errors.New("port allocated to other server")
+	ErrPortInUse    error = errors.New("port in use by other process")
+)
+
+// Port used by other server or process
+type ConflictError struct {
+	Port     int   // Conflicted port
+	ServerID int64 // Server with port allocated, 0 if port in use by other process
+}
+
+func (err *ConflictError) Error() string {
+	if err.ServerID == 0 {
+		return fmt.Sprintf("%s: %d", ErrPortInUse, err.Port)
+	}
+	return fmt.Sprintf("%s: %d used by server %d", ErrPortConflict, err.Port, err.ServerID)
+}
+
+func (err *ConflictError) Unwrap() error {
+	if err.ServerID == 0 {
+		return ErrPortInUse
+	}
+	return ErrPortConflict
+}
+
+// Allocate unique ports to servers in runner from range, ports are stored in server record and server.properties
+type Allocator struct {
+	Database db.Database
+	Runner   *runner.Manager
+	Min, Max int // Ports range, servers use two ports, Bedrock IPv4 and IPv6, Java server and RCON
+
+	mu sync.Mutex
+}
+
+// Create new allocator and add start check to runner [runner.Manager.PreStart]
+func NewAllocator(database db.Database, manager *runner.Manager, min, max int) (*Allocator, error) {
+	if min == 0 && max == 0 {
+		min, max = DefaultMin, DefaultMax
+	}
+	if min < 1 || max > 65535 || min > max {
+		return nil, ErrInvalidRange
+	}
+	alloc := &Allocator{Database: database, Runner: manager, Min: min, Max: max}
+	manager.PreStart = append(manager.PreStart, alloc.PreStart)
+	return alloc, nil
+}
+
+func isBedrock(srv *server.Server) bool { return strings.EqualFold(srv.Software, "bedrock") }
+
+// Ports allocated to other servers
+func (alloc *Allocator) used(except int64) (map[int]int64, error) {
+	servers, err := alloc.Database.Servers()
+	if err != nil {
+		return nil, fmt.Errorf("cannot get servers: %s", err)
+	}
+	ports := map[int]int64{}
+	for _, srv := range servers {
+		if srv.ID == except {
+			continue
+		}
+		for _, port := range []int{srv.Port, srv.PortV6, srv.RCONPort} {
+			if port > 0 {
+				ports[port] = srv.ID
+			}
+		}
+	}
+	return ports, nil
+}
+
+// Return true if port cannot be listened in TCP or UDP
+func InUse(port int, ipv6 bool) bool {
+	tcp, udp, host := "tcp4", "udp4", "0.0.0.0"
+	if ipv6 {
+		tcp, udp, host = "tcp6", "udp6", "::"
+	}
+	address := net.JoinHostPort(host, strconv.Itoa(port))
+
+	inUse := func(err error) bool {
+		// Host without IPv6 cannot use port
+		return err != nil && !errors.Is(err, syscall.EAFNOSUPPORT) && !errors.Is(err, syscall.EADDRNOTAVAIL)
+	}
+	ln, err := net.Listen(tcp, address)
+	if inUse(err) {
+		return true
+	} else if err == nil {
+		ln.Close()
+	}
+	conn, err := net.ListenPacket(udp, address)
+	if inUse(err) {
+		return true
+	} else if err == nil {
+		conn.Close()
+	}
+	return false
+}
+
+// Next free port in range
+func (alloc *Allocator) next(used map[int]int64, skip ...int) (int, error) {
+	for port := alloc.Min; port <= alloc.Max; port++ {
+		if _, ok := used[port]; ok || slices.Contains(skip, port) {
+			continue
+		} else if !InUse(port, false) && !InUse(port, true) {
+			return port, nil
+		}
+	}
+	return 0, ErrNoPorts
+}
+
+// Write ports to server.properties and server record, second port is IPv6 to Bedrock and RCON to Java
+func (alloc *Allocator) save(srv *server.Server, dir string, port, second int) error {
+	name := filepath.Join(dir, properties.FileName)
+	file, err := properties.Open(name)
+	if err != nil {
+		return fmt.Errorf("cannot open server.properties: %s", err)
+	}
+	portV6, rconPort := second, 0
+	file.Set(PortKey, strconv.Itoa(port))
+	if isBedrock(srv) {
+		file.Set(PortV6Key, strconv.Itoa(portV6))
+	} else {
+		portV6, rconPort = 0, second
+		file.Set(RCONPortKey, strconv.Itoa(rconPort))
+	}
+	if err = file.Save(name); err != nil {
+		return fmt.Errorf("cannot save server.properties: %s", err)
+	}
+
+	if srv.Port != port || srv.PortV6 != portV6 || srv.RCONPort != rconPort {
+		srv.Port, srv.PortV6, srv.RCONPort = port, portV6, rconPort
+		if err = alloc.Database.UpdateServer(srv); err != nil {
+			return fmt.Errorf("cannot update server ports: %s", err)
+		}
+	}
+	return nil
+}
+
+// Allocate new ports to server, old ports are released
+func (alloc *Allocator) Allocate(srv *server.Server) error {
+	alloc.mu.Lock()
+	defer alloc.mu.Unlock()
+
+	used, err := alloc.used(srv.ID)
+	if err != nil {
+		return err
+	}
+	port, err := alloc.next(used)
+	if err != nil {
+		return err
+	}
+	second, err := alloc.next(used, port)
+	if err != nil {
+		return err
+	}
+
+	dir := alloc.Runner.Dir(srv.ID)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return fmt.Errorf("cannot make server directory: %s", err)
+	}
+	return alloc.save(srv, dir, port, second)
+}
+
+// Hook to [runner.Manager.PreStart].
+//
+// Allocate ports to servers without ports, ports changed in server.properties are stored in server record
+// and start is refused with [*ConflictError] if port allocated to other server or in use.
+func (alloc *Allocator) PreStart(srv *server.Server, dir string) error {
+	alloc.mu.Lock()
+	defer alloc.mu.Unlock()
+
+	file, err := properties.Open(filepath.Join(dir, properties.FileName))
+	if err != nil {
+		return fmt.Errorf("cannot open server.properties: %s", err)
+	}
+	used, err := alloc.used(srv.ID)
+	if err != nil {
+		return err
+	}
+
+	// Second port is IPv6 to Bedrock and RCON to Java
+	port, second, secondKey := srv.Port, srv.PortV6, PortV6Key
+	if !isBedrock(srv) {
+		second, secondKey = srv.RCONPort, RCONPortKey
+	}
+
+	// Keep ports changed by user in server.properties
+	propertyPort := func(key string, port int) int {
+		if value, ok := file.Get(key); ok {
+			if number, err := strconv.Atoi(value); err == nil && number > 0 && number <= 65535 {
+				return number
+			}
+		}
+		return port
+	}
+	if port == 0 {
+		if port, err = alloc.next(used); err != nil {
+			return err
+		}
+	} else {
+		port = propertyPort(PortKey, port)
+	}
+	if second == 0 {
+		if second, err = alloc.next(used, port); err != nil {
+			return err
+		}
+	} else {
+		second = propertyPort(secondKey, second)
+	}
+
+	for index, value := range []int{port, second} {
+		if id, ok := used[value]; ok {
+			return &ConflictError{Port: value, ServerID: id}
+		} else if index == 1 && value == port {
+			return &ConflictError{Port: value, ServerID: srv.ID}
+		} else if InUse(value, index == 1 && isBedrock(srv)) {
+			return &ConflictError{Port: value}
+		}
+	}
+	return alloc.save(srv, dir, port, second)
+}
@@ -0,0 +1,97 @@
+package network
+
+import (
+	"errors"
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+
+	"sirherobrine23.com.br/go-bds/bds/module/db"
+	"sirherobrine23.com.br/go-bds/bds/module/properties"
+	"sirherobrine23.com.br/go-bds/bds/module/runner"
+	"sirherobrine23.com.br/go-bds/bds/module/server"
+	"sirherobrine23.com.br/go-bds/bds/module/users"
+)
+
+func TestAllocator(t *testing.T) {
+	database, err := db.NewSqliteConnection(":memory:")
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	user, err := database.CreateNewUser(&users.User{}, &users.Password{Password: "test1234"})
+	if err != nil {
+		t.Errorf("cannot make new user in database: %s", err)
+		return
+	}
+	bedrock, _ := database.CreateServer(user, &server.Server{Software: "bedrock", Version: "1.21.50", Owner: user.UserID})
+	java, _ := database.CreateServer(user, &server.Server{Software: "java", Version: "1.21.4", Owner: user.UserID})
+
+	// Find free range to test
+	min := 0
+	for port := 40000; port < 60000 && min == 0; port += 10 {
+		if !InUse(port, false) && !InUse(port+1, false) && !InUse(port+2, false) && !InUse(port+3, false) {
+			min = port
+		}
+	}
+	manager := runner.NewManager(t.TempDir(), nil)
+	alloc, err := NewAllocator(database, manager, min, min+3)
+	if err != nil {
+		t.Errorf("cannot make allocator: %s", err)
+		return
+	} else if len(manager.PreStart) != 1 {
+		t.Errorf("allocator not added to runner")
+		return
+	}
+
+	if err = alloc.Allocate(bedrock); err != nil {
+		t.Errorf("cannot allocate bedrock ports: %s", err)
+		return
+	} else if bedrock.Port != min || bedrock.PortV6 != min+1 {
+		t.Errorf("invalid bedrock ports: %d and %d", bedrock.Port, bedrock.PortV6)
+		return
+	}
+	file, _ := properties.Open(filepath.Join(manager.Dir(bedrock.ID), properties.FileName))
+	if value, _ := file.Get(PortV6Key); value != strconv.Itoa(min+1) {
+		t.Errorf("port not writed to server.properties: %q", value)
+		return
+	}
+
+	// First start allocate port
+	os.MkdirAll(manager.Dir(java.ID), 0755)
+	if err = alloc.PreStart(java, manager.Dir(java.ID)); err != nil {
+		t.Errorf("cannot allocate java port: %s", err)
+		return
+	} else if stored, _ := database.Server(java.ID); stored.Port != min+2 || stored.PortV6 != 0 || stored.RCONPort != min+3 {
+		t.Errorf("java ports not stored: %d and %d", stored.Port, stored.RCONPort)
+		return
+	}
+
+	// Port changed to other server port
+	name := filepath.Join(manager.Dir(java.ID), properties.FileName)
+	file, _ = properties.Open(name)
+	file.Set(PortKey, strconv.Itoa(min))
+	file.Save(name)
+	if err = alloc.PreStart(java, manager.Dir(java.ID)); !errors.Is(err, ErrPortConflict) {
+		t.Errorf("start with conflict port: %v", err)
+		return
+	}
+
+	// Port in use by other process
+	ln, err := net.Listen("tcp4", "0.0.0.0:"+strconv.Itoa(min+2))
+	if err != nil {
+		t.Skipf("cannot listen port: %s", err)
+		return
+	}
+	defer ln.Close()
+	file.Set(PortKey, strconv.Itoa(min+2))
+	file.Save(name)
+	if err = alloc.PreStart(java, manager.Dir(java.ID)); !errors.Is(err, ErrPortInUse) {
+		t.Errorf("start with port in use: %v", err)
+		return
+	} else if err = alloc.Allocate(java); err != ErrNoPorts {
+		t.Errorf("allocated port out of range: %v", err)
+	}
+}
@@ -0,0 +1,128 @@
+package players
+
+import (
+	"errors"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"sirherobrine23.com.br/go-bds/bds/module/server"
+)
+
+var (
+	ErrInvalidAction   error = errors.New("invalid player action")
+	ErrUnsupported     error = errors.New("action not supported by server software")
+	ErrInvalidUsername error = errors.New("invalid player username")
+	ErrInvalidReason   error = errors.New("reason cannot have line breaks or control characters")
+
+	javaUsername    = regexp.MustCompile(`^[A-Za-z0-9_]{1,16}$`)
+	bedrockUsername = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9 ]{0,15}$`) // Xbox gamertag, letters, numbers and spaces
+)
+
+// Check username is valid to server software, usernames are sent to server console
+func ValidUsername(software, username string) bool {
+	if strings.EqualFold(software, "bedrock") {
+		return bedrockUsername.MatchString(username)
+	}
+	return javaUsername.MatchString(username)
+}
+
+// Check reason not have characters to break console command
+func ValidReason(reason string) bool {
+	return !strings.ContainsFunc(reason, unicode.IsControl)
+}
+
+// Quote username if have spaces, Bedrock gamertags can have spaces
+func quote(username string) string {
+	if strings.ContainsAny(username, " \t\"") {
+		return strconv.Quote(username)
+	}
+	return username
+}
+
+// Console commands to apply action to player in server software.
+//
+// Bedrock not have ban command, ban and pardon return [ErrUnsupported], use unwhitelist and kick with allowlist enabled
+func Commands(software string, action server.PlayerAction, username, reason string) ([]string, error) {
+	if !ValidUsername(software, username) {
+		return nil, ErrInvalidUsername
+	} else if !ValidReason(reason) {
+		return nil, ErrInvalidReason
+	}
+
+	withReason := func(command string) string {
+		if reason = strings.TrimSpace(reason); reason != "" {
+			return command + " " + reason
+		}
+		return command
+	}
+
+	if strings.EqualFold(software, "bedrock") {
+		name := quote(username)
+		switch action {
+		case server.PlayerKick:
+			return []string{withReason("kick " + name)}, nil
+		case server.PlayerBan, server.PlayerPardon:
+			return nil, ErrUnsupported
+		case server.PlayerWhitelist:
+			return []string{"allowlist add " + name}, nil
+		case server.PlayerUnwhitelist:
+			return []string{"allowlist remove " + name}, nil
+		case server.PlayerOp:
+			return []string{"op " + name}, nil
+		case server.PlayerDeop:
+			return []string{"deop " + name}, nil
+		}
+		return nil, ErrInvalidAction
+	}
+
+	switch action {
+	case server.PlayerKick:
+		return []string{withReason("kick " + username)}, nil
+	case server.PlayerBan:
+		return []string{withReason("ban " + username)}, nil
+	case server.PlayerPardon:
+		return []string{"pardon " + username}, nil
+	case server.PlayerWhitelist:
+		return []string{"whitelist add " + username}, nil
+	case server.PlayerUnwhitelist:
+		return []string{"whitelist remove " + username}, nil
+	case server.PlayerOp:
+		return []string{"op " + username}, nil
+	case server.PlayerDeop:
+		return []string{"deop " + username}, nil
+	}
+	return nil, ErrInvalidAction
+}
+
+// Action to revert action, ban to pardon, op to deop and whitelist to unwhitelist
+func Revert(action server.PlayerAction) (server.PlayerAction, error) {
+	switch action {
+	case server.PlayerBan:
+		return server.PlayerPardon, nil
+	case server.PlayerOp:
+		return server.PlayerDeop, nil
+	case server.PlayerWhitelist:
+		return server.PlayerUnwhitelist, nil
+	}
+	return "", ErrInvalidAction
+}
+
+// Update player flags to action
+func Apply(player *server.Player, action server.PlayerAction) {
+	switch action {
+	case server.PlayerBan:
+		player.Banned = true
+	case server.PlayerPardon:
+		player.Banned = false
+	case server.PlayerOp:
+		player.Operator = true
+	case server.PlayerDeop:
+		player.Operator = false
+	case server.PlayerWhitelist:
+		player.Whitelisted = true
+	case server.PlayerUnwhitelist:
+		player.Whitelisted = false
+	}
+}
@@ -0,0 +1,30 @@
+package players
+
+import (
+	"testing"
+
+	"sirherobrine23.com.br/go-bds/bds/module/server"
+)
+
+func TestCommands(t *testing.T) {
+	if commands, err := Commands("bedrock", server.PlayerKick, "Steve Alex", "griefing"); err != nil || commands[0] != `kick "Steve Alex" griefing` {
+		t.Errorf("invalid bedrock commands: %q, %v", commands, err)
+		return
+	} else if _, err = Commands("bedrock", server.PlayerBan, "Steve", ""); err != ErrUnsupported {
+		t.Errorf("bedrock ban accepted: %v", err)
+		return
+	} else if commands, err = Commands("paper", server.PlayerKick, "Steve_01", ""); err != nil || commands[0] != "kick Steve_01" {
+		t.Errorf("invalid java commands: %q, %v", commands, err)
+		return
+	}
+
+	for _, username := range []string{"Steve\nop Alex", "Steve Alex", "", "Steve;op", "SeventeenChars123"} {
+		if _, err := Commands("paper", server.PlayerKick, username, ""); err != ErrInvalidUsername {
+			t.Errorf("username %q accepted: %v", username, err)
+			return
+		}
+	}
+	if _, err := Commands("bedrock", server.PlayerKick, "Steve", "bye\nop Steve"); err != ErrInvalidReason {
+		t.Errorf("reason with line break accepted: %v", err)
+	}
+}
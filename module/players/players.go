@@ -0,0 +1,129 @@
+// Track online players from server output and build moderation commands
+package players
+
+import (
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"sirherobrine23.com.br/go-bds/bds/module/db"
+	"sirherobrine23.com.br/go-bds/bds/module/logparser"
+	"sirherobrine23.com.br/go-bds/bds/module/runner"
+	"sirherobrine23.com.br/go-bds/bds/module/server"
+)
+
+// Player connected to server
+type Online struct {
+	Username string    `json:"username"`       // Player username
+	XUID     string    `json:"xuid,omitempty"` // Bedrock Xbox user ID
+	UUID     string    `json:"uuid,omitempty"` // Java player UUID
+	JoinAt   time.Time `json:"join_at"`        // Time of join
+}
+
+// Online players to all servers
+type Tracker struct {
+	Database db.Database                                          // Database to storage players and history
+	OnEvent  []func(proc *runner.Process, event *logparser.Event) // Called to every event parsed from server
+
+	mu     sync.RWMutex
+	online map[int64]map[string]*Online
+}
+
+// Create new tracker with functions called to every event, like bedrock.CaptureXUID to fill allowlist.json
+func NewTracker(database db.Database, onEvent ...func(*runner.Process, *logparser.Event)) *Tracker {
+	return &Tracker{
+		Database: database,
+		OnEvent:  onEvent,
+		online:   map[int64]map[string]*Online{},
+	}
+}
+
+// Online players in server, ordered by join time
+func (tracker *Tracker) Online(serverID int64) []*Online {
+	tracker.mu.RLock()
+	defer tracker.mu.RUnlock()
+
+	players := []*Online{}
+	for _, player := range tracker.online[serverID] {
+		players = append(players, player)
+	}
+	slices.SortFunc(players, func(a, b *Online) int { return a.JoinAt.Compare(b.JoinAt) })
+	return players
+}
+
+// Return player if online, nil if offline
+func (tracker *Tracker) Player(serverID int64, username string) *Online {
+	tracker.mu.RLock()
+	defer tracker.mu.RUnlock()
+	for name, player := range tracker.online[serverID] {
+		if strings.EqualFold(name, username) {
+			return player
+		}
+	}
+	return nil
+}
+
+// Parse process output until process exit
+func (tracker *Tracker) Attach(proc *runner.Process) {
+	// Events are processed in background, handlers can send commands to process
+	parser, queue := logparser.New(proc.Server.Software), proc.Output.Queue()
+	go func() {
+		defer tracker.clear(proc.Server.ID)
+		for lines, ok := queue.Next(proc.Done()); ok; lines, ok = queue.Next(proc.Done()) {
+			for _, line := range lines {
+				if line.Stream == runner.Stdin {
+					continue
+				} else if event, ok := parser.Parse(line.Text); ok {
+					tracker.process(proc, event)
+				}
+			}
+		}
+	}()
+}
+
+func (tracker *Tracker) clear(serverID int64) {
+	tracker.mu.Lock()
+	defer tracker.mu.Unlock()
+	delete(tracker.online, serverID)
+}
+
+func (tracker *Tracker) process(proc *runner.Process, event *logparser.Event) {
+	for _, fn := range tracker.OnEvent {
+		fn(proc, event)
+	}
+
+	serverID := proc.Server.ID
+	switch event.Type {
+	case logparser.PlayerJoined:
+		tracker.mu.Lock()
+		if tracker.online[serverID] == nil {
+			tracker.online[serverID] = map[string]*Online{}
+		}
+		tracker.online[serverID][event.Player] = &Online{Username: event.Player, XUID: event.XUID, UUID: event.UUID, JoinAt: time.Now()}
+		tracker.mu.Unlock()
+		tracker.record(serverID, event, server.PlayerJoin)
+	case logparser.PlayerLeft:
+		tracker.mu.Lock()
+		delete(tracker.online[serverID], event.Player)
+		tracker.mu.Unlock()
+		tracker.record(serverID, event, server.PlayerLeave)
+	case logparser.ServerStopping:
+		tracker.clear(serverID)
+	}
+}
+
+// Save player and history in database
+func (tracker *Tracker) record(serverID int64, event *logparser.Event, action server.PlayerAction) {
+	if tracker.Database == nil {
+		return
+	}
+
+	player, err := tracker.Database.Player(serverID, event.Player)
+	if err != nil {
+		player = &server.Player{ServerID: serverID, Username: event.Player}
+	}
+	player.XUID, player.UUID = event.XUID, event.UUID
+	tracker.Database.UpdatePlayer(player)
+	tracker.Database.AddPlayerHistory(&server.PlayerHistory{ServerID: serverID, Username: event.Player, Action: action})
+}
@@ -0,0 +1,337 @@
+package plugins
+
+import (
+	"archive/zip"
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"io"
+	"slices"
+	"strings"
+
+	"sirherobrine23.com.br/go-bds/bds/module/mcversion"
+)
+
+// Metadata files in jar
+const (
+	PluginFile      = "plugin.yml"
+	PaperPluginFile = "paper-plugin.yml"
+	FabricFile      = "fabric.mod.json"
+	ForgeFile       = "META-INF/mods.toml"
+)
+
+func readZipFile(zr *zip.Reader, name string) ([]byte, bool) {
+	file, err := zr.Open(name)
+	if err != nil {
+		return nil, false
+	}
+	defer file.Close()
+	data, err := io.ReadAll(io.LimitReader(file, 1<<20))
+	return data, err == nil
+}
+
+// Read jar metadata, return [ErrInvalidJar] if jar not have plugin or mod metadata
+func Read(r io.ReaderAt, size int64) (*Jar, error) {
+	zr, err := zip.NewReader(r, size)
+	if err != nil {
+		return nil, ErrInvalidJar
+	}
+
+	var jar *Jar
+	if data, ok := readZipFile(zr, FabricFile); ok {
+		if jar, err = parseFabric(data); err != nil {
+			return nil, err
+		}
+	} else if data, ok := readZipFile(zr, PaperPluginFile); ok {
+		jar = parsePlugin(data)
+		jar.Loader = Paper
+	} else if data, ok := readZipFile(zr, PluginFile); ok {
+		jar = parsePlugin(data)
+	} else if data, ok := readZipFile(zr, ForgeFile); ok {
+		jar = parseForge(data)
+	}
+	if jar == nil || jar.ID == "" {
+		return nil, ErrInvalidJar // Metadata without plugin name or mod ID
+	}
+	return jar, nil
+}
+
+func unquote(value string) string {
+	value = strings.TrimSpace(value)
+	if len(value) >= 2 && (value[0] == '"' || value[0] == '\'') {
+		if end := strings.IndexByte(value[1:], value[0]); end != -1 {
+			return value[1 : end+1] // Ignore comment after quoted value
+		}
+	}
+	if index := strings.Index(value, " #"); index != -1 {
+		value = strings.TrimSpace(value[:index])
+	}
+	return value
+}
+
+// Top level keys from YAML, nested maps are ignored and lists returned as multiple values.
+//
+// Only used to plugin.yml, full YAML is not required to show metadata.
+func parseYAML(data []byte) map[string][]string {
+	values := map[string][]string{}
+	var key string
+	var block bool // Literal or folded scalar
+	scan := bufio.NewScanner(bytes.NewReader(data))
+	for scan.Scan() {
+		line := strings.TrimRight(scan.Text(), "\r")
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "" || trimmed[0] == '#' {
+			continue
+		}
+
+		// Top level key
+		if line[0] != ' ' && line[0] != '\t' && line[0] != '-' {
+			name, value, ok := strings.Cut(line, ":")
+			if !ok {
+				key = ""
+				continue
+			}
+			key, block = strings.TrimSpace(name), false
+			value = strings.TrimSpace(value)
+			switch {
+			case value == "":
+			case value == "|" || value == ">" || value == "|-" || value == ">-":
+				block = true
+			case value[0] == '[':
+				for item := range strings.SplitSeq(strings.Trim(value, "[]"), ",") {
+					if item = unquote(item); item != "" {
+						values[key] = append(values[key], item)
+					}
+				}
+			default:
+				values[key] = []string{unquote(value)}
+			}
+			continue
+		}
+
+		if key == "" {
+			continue
+		} else if block {
+			if len(values[key]) == 0 {
+				values[key] = []string{trimmed}
+			} else {
+				values[key][0] += " " + trimmed
+			}
+		} else if item, ok := strings.CutPrefix(trimmed, "- "); ok {
+			values[key] = append(values[key], unquote(item))
+		}
+	}
+	return values
+}
+
+func first(values []string) string {
+	if len(values) == 0 {
+		return ""
+	}
+	return values[0]
+}
+
+// Parse Bukkit plugin.yml or paper-plugin.yml
+func parsePlugin(data []byte) *Jar {
+	values := parseYAML(data)
+	jar := &Jar{
+		Kind:          Plugin,
+		Loader:        Bukkit,
+		ID:            first(values["name"]),
+		Name:          first(values["name"]),
+		Version:       first(values["version"]),
+		Description:   first(values["description"]),
+		Authors:       slices.Concat(values["author"], values["authors"]),
+		APIVersion:    first(values["api-version"]),
+		Depends:       values["depend"],
+		SoftDepends:   values["softdepend"],
+		FoliaSupport:  first(values["folia-supported"]) == "true",
+		Dependencies:  map[string]string{},
+		Incompatibles: map[string]string{},
+	}
+	return jar
+}
+
+// fabric.mod.json, depends values can be string or list of strings
+type fabricMod struct {
+	ID          string                     `json:"id"`
+	Name        string                     `json:"name"`
+	Version     string                     `json:"version"`
+	Description string                     `json:"description"`
+	Authors     []json.RawMessage          `json:"authors"`
+	Depends     map[string]json.RawMessage `json:"depends"`
+	Recommends  map[string]json.RawMessage `json:"recommends"`
+	Breaks      map[string]json.RawMessage `json:"breaks"`
+}
+
+// Version constraints as " || " joined string
+func constraint(raw json.RawMessage) string {
+	var list []string
+	if err := json.Unmarshal(raw, &list); err == nil {
+		return strings.Join(list, " || ")
+	}
+	var value string
+	json.Unmarshal(raw, &value)
+	return value
+}
+
+func parseFabric(data []byte) (*Jar, error) {
+	var mod fabricMod
+	if err := json.Unmarshal(data, &mod); err != nil {
+		return nil, ErrInvalidJar
+	}
+	jar := &Jar{
+		Kind:          Mod,
+		Loader:        Fabric,
+		ID:            mod.ID,
+		Name:          mod.Name,
+		Version:       mod.Version,
+		Description:   mod.Description,
+		Dependencies:  map[string]string{},
+		Incompatibles: map[string]string{},
+	}
+	if jar.Name == "" {
+		jar.Name = jar.ID
+	}
+	for _, raw := range mod.Authors {
+		var author struct {
+			Name string `json:"name"`
+		}
+		if err := json.Unmarshal(raw, &author.Name); err != nil {
+			json.Unmarshal(raw, &author)
+		}
+		if author.Name != "" {
+			jar.Authors = append(jar.Authors, author.Name)
+		}
+	}
+	for id, raw := range mod.Depends {
+		jar.Dependencies[id] = constraint(raw)
+	}
+	for id, raw := range mod.Breaks {
+		jar.Incompatibles[id] = constraint(raw)
+	}
+	for id := range mod.Recommends {
+		jar.SoftDepends = append(jar.SoftDepends, id)
+	}
+	return jar, nil
+}
+
+// Parse first [[mods]] from Forge mods.toml, dependencies ranges are not checked
+func parseForge(data []byte) *Jar {
+	jar := &Jar{Kind: Mod, Loader: Forge, Dependencies: map[string]string{}, Incompatibles: map[string]string{}}
+	section := ""
+	scan := bufio.NewScanner(bytes.NewReader(data))
+	for scan.Scan() {
+		line := strings.TrimSpace(scan.Text())
+		if strings.HasPrefix(line, "[") {
+			section = strings.Trim(line, "[] ")
+			if section == "mods" && jar.ID != "" {
+				break // Only first mod
+			}
+			continue
+		}
+		key, value, ok := strings.Cut(line, "=")
+		if !ok || section != "mods" {
+			continue
+		}
+		switch value = unquote(value); strings.TrimSpace(key) {
+		case "modId":
+			jar.ID = value
+		case "version":
+			jar.Version = value
+		case "displayName":
+			jar.Name = value
+		case "description":
+			jar.Description = value
+		case "authors":
+			jar.Authors = []string{value}
+		}
+	}
+	if jar.Name == "" {
+		jar.Name = jar.ID
+	}
+	return jar
+}
+
+// Remove prerelease and build from version, "1.21-rc.1" to "1.21"
+func release(version string) string {
+	version, _, _ = strings.Cut(version, "+")
+	version, _, _ = strings.Cut(version, "-")
+	return version
+}
+
+// Check version in Fabric constraint like ">=1.20 <1.21", "~1.20.1", "1.20.x" or list joined with " || ".
+// Return false in ok if constraint or version cannot be compared.
+func Match(constraint, version string) (match bool, ok bool) {
+	version = release(version)
+	for alternative := range strings.SplitSeq(constraint, "||") {
+		all, valid := true, true
+		for predicate := range strings.FieldsSeq(alternative) {
+			matched, predicateOk := matchPredicate(predicate, version)
+			if !predicateOk {
+				valid = false
+				break
+			}
+			all = all && matched
+		}
+		if !valid {
+			return false, false
+		} else if all {
+			return true, true
+		}
+	}
+	return false, true
+}
+
+func matchPredicate(predicate, version string) (bool, bool) {
+	if predicate == "*" {
+		return true, true
+	}
+
+	operator := ""
+	for _, op := range []string{">=", "<=", ">", "<", "=", "~", "^"} {
+		if rest, ok := strings.CutPrefix(predicate, op); ok {
+			operator, predicate = op, rest
+			break
+		}
+	}
+	target := release(predicate)
+
+	// Wildcards, "1.20.x"
+	if parts := strings.Split(target, "."); parts[len(parts)-1] == "x" || parts[len(parts)-1] == "X" || parts[len(parts)-1] == "*" {
+		prefix := strings.Join(parts[:len(parts)-1], ".")
+		if _, ok := mcversion.Compare(prefix, version); !ok {
+			return false, false
+		}
+		return version == prefix || strings.HasPrefix(version, prefix+"."), true
+	}
+
+	compare, ok := mcversion.Compare(version, target)
+	if !ok {
+		return false, false
+	}
+	switch operator {
+	case ">=":
+		return compare >= 0, true
+	case "<=":
+		return compare <= 0, true
+	case ">":
+		return compare > 0, true
+	case "<":
+		return compare < 0, true
+	case "~", "^":
+		// "~" allow patch changes and "^" minor changes, Minecraft "1.x" is major so both keep minor
+		parts := strings.Split(target, ".")
+		keep := 2
+		if operator == "^" && parts[0] != "1" {
+			keep = 1
+		}
+		if len(parts) < keep {
+			keep = len(parts)
+		}
+		prefix := strings.Join(parts[:keep], ".")
+		return compare >= 0 && (version == prefix || strings.HasPrefix(version, prefix+".")), true
+	default:
+		return compare == 0, true
+	}
+}
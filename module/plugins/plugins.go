@@ -0,0 +1,357 @@
+// Java servers plugins (Bukkit, Spigot, Paper) and mods (Fabric, Forge): list, install, enable and disable
+package plugins
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+
+	"sirherobrine23.com.br/go-bds/bds/module/mcversion"
+	"sirherobrine23.com.br/go-bds/bds/module/server"
+)
+
+const DisabledExt = ".disabled" // Disabled jars are renamed to "name.jar.disabled"
+
+var (
+	ErrInvalidJar   error = errors.New("jar not have plugin.yml, fabric.mod.json or mods.toml")
+	ErrInvalidName  error = errors.New("invalid jar file name")
+	ErrJarNotExists error = errors.New("plugin or mod not exists")
+	ErrJarExists    error = errors.New("other plugin or mod with same file name")
+	ErrInvalidKind  error = errors.New("invalid kind, use plugins or mods")
+	ErrJavaOnly     error = errors.New("plugins and mods are only to java servers")
+)
+
+var jarsMu sync.Mutex // Lock plugins and mods directories
+
+// Jar kind, also directory name in server
+type Kind string
+
+const (
+	Plugin Kind = "plugins"
+	Mod    Kind = "mods"
+)
+
+// Plugin or mod loader
+type Loader string
+
+const (
+	Bukkit Loader = "bukkit" // plugin.yml, loaded by Spigot, Paper and forks
+	Paper  Loader = "paper"  // paper-plugin.yml, only Paper and forks
+	Fabric Loader = "fabric"
+	Forge  Loader = "forge"
+)
+
+// Softwares can load loader jars
+var Softwares = map[Loader][]string{
+	Bukkit: {"paper", "folia", "purpur", "spigot", "bukkit"},
+	Paper:  {"paper", "folia", "purpur"},
+	Fabric: {"fabric", "quilt"},
+	Forge:  {"forge", "neoforge"},
+}
+
+// Mods ids provided by loader or game
+var builtin = []string{"minecraft", "java", "fabricloader", "fabric-loader", "forge", "neoforge"}
+
+// Plugin or mod jar
+type Jar struct {
+	File          string            `json:"file"` // File name without DisabledExt
+	Kind          Kind              `json:"kind"`
+	Loader        Loader            `json:"loader"`
+	ID            string            `json:"id"` // Plugin name or mod id
+	Name          string            `json:"name"`
+	Version       string            `json:"version"`
+	Description   string            `json:"description,omitempty"`
+	Authors       []string          `json:"authors,omitempty"`
+	APIVersion    string            `json:"api_version,omitempty"`   // Bukkit api-version
+	FoliaSupport  bool              `json:"folia_support,omitempty"` // Plugin declare folia-supported
+	Depends       []string          `json:"depends,omitempty"`       // Required plugins
+	SoftDepends   []string          `json:"soft_depends,omitempty"`  // Optional plugins or recommended mods
+	Dependencies  map[string]string `json:"dependencies,omitempty"`  // Fabric mods dependencies with version constraint, include "minecraft"
+	Incompatibles map[string]string `json:"incompatibles,omitempty"` // Fabric breaks
+	Enabled       bool              `json:"enabled"`
+	Size          int64             `json:"size"`
+	Issues        []string          `json:"issues"` // Incompatibilities with server or other jars
+}
+
+// Check jar file name, accept enabled or disabled name
+func validName(name string) (string, bool) {
+	name = strings.TrimSuffix(name, DisabledExt)
+	return name, name != "" && !strings.HasPrefix(name, ".") && !strings.ContainsAny(name, "/\\\x00") && strings.HasSuffix(strings.ToLower(name), ".jar")
+}
+
+func javaServer(srv *server.Server) error {
+	if strings.EqualFold(srv.Software, "bedrock") {
+		return ErrJavaOnly
+	}
+	return nil
+}
+
+func readJar(name string) (*Jar, error) {
+	file, err := os.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	info, err := file.Stat()
+	if err != nil {
+		return nil, err
+	}
+	jar, err := Read(file, info.Size())
+	if err != nil {
+		return nil, err
+	}
+	jar.Size = info.Size()
+	return jar, nil
+}
+
+// List jars in plugins and mods with issues
+func List(dir string, srv *server.Server) ([]*Jar, error) {
+	if err := javaServer(srv); err != nil {
+		return nil, err
+	}
+	jarsMu.Lock()
+	defer jarsMu.Unlock()
+	jars, err := list(dir)
+	if err != nil {
+		return nil, err
+	}
+	Check(jars, srv)
+	return jars, nil
+}
+
+func list(dir string) ([]*Jar, error) {
+	jars := []*Jar{}
+	for _, kind := range []Kind{Plugin, Mod} {
+		entries, err := os.ReadDir(filepath.Join(dir, string(kind)))
+		if err != nil {
+			if errors.Is(err, fs.ErrNotExist) {
+				continue
+			}
+			return nil, err
+		}
+		for _, entry := range entries {
+			name, ok := validName(entry.Name())
+			if !ok || !entry.Type().IsRegular() {
+				continue
+			}
+			jar, err := readJar(filepath.Join(dir, string(kind), entry.Name()))
+			if err != nil {
+				jar = &Jar{Kind: kind, ID: name, Name: name, Issues: []string{"cannot read metadata"}}
+			}
+			jar.File, jar.Enabled = name, !strings.HasSuffix(entry.Name(), DisabledExt)
+			if jar.Kind != kind {
+				jar.Issues = append(jar.Issues, fmt.Sprintf("%s jar in %s directory", jar.Loader, kind))
+				jar.Kind = kind
+			}
+			jars = append(jars, jar)
+		}
+	}
+	return jars, nil
+}
+
+// Fill jars issues: loader not supported by server, Minecraft version, missing dependencies, incompatible and duplicated jars
+func Check(jars []*Jar, srv *server.Server) {
+	enabled := map[string]*Jar{}
+	for _, jar := range jars {
+		if jar.Enabled {
+			if other, ok := enabled[strings.ToLower(jar.ID)]; ok && jar.ID != "" {
+				jar.Issues = append(jar.Issues, fmt.Sprintf("duplicated with %s", other.File))
+				other.Issues = append(other.Issues, fmt.Sprintf("duplicated with %s", jar.File))
+			}
+			enabled[strings.ToLower(jar.ID)] = jar
+		}
+	}
+
+	_, versionOk := mcversion.Compare(release(srv.Version), "0")
+	for _, jar := range jars {
+		if jar.Issues == nil {
+			jar.Issues = []string{}
+		}
+		if jar.Loader == "" {
+			continue
+		}
+		if !slices.ContainsFunc(Softwares[jar.Loader], func(software string) bool { return strings.EqualFold(software, srv.Software) }) {
+			jar.Issues = append(jar.Issues, fmt.Sprintf("server software %s not load %s jars", srv.Software, jar.Loader))
+		}
+		if strings.EqualFold(srv.Software, "folia") && jar.Kind == Plugin && !jar.FoliaSupport {
+			jar.Issues = append(jar.Issues, "plugin not declare Folia support")
+		}
+
+		// api-version is minimum Minecraft version like "1.20"
+		if jar.APIVersion != "" && versionOk {
+			if compare, ok := mcversion.Compare(jar.APIVersion, release(srv.Version)); ok && compare > 0 {
+				jar.Issues = append(jar.Issues, fmt.Sprintf("require api-version %s, server is %s", jar.APIVersion, srv.Version))
+			}
+		}
+
+		for _, dep := range jar.Depends {
+			if _, ok := enabled[strings.ToLower(dep)]; !ok {
+				jar.Issues = append(jar.Issues, fmt.Sprintf("missing dependency %s", dep))
+			}
+		}
+		for _, id := range slices.Sorted(maps.Keys(jar.Dependencies)) {
+			constraint := jar.Dependencies[id]
+			if id == "minecraft" {
+				if match, ok := Match(constraint, srv.Version); versionOk && ok && !match {
+					jar.Issues = append(jar.Issues, fmt.Sprintf("require minecraft %s, server is %s", constraint, srv.Version))
+				}
+				continue
+			} else if slices.Contains(builtin, id) {
+				continue
+			}
+
+			dep, ok := enabled[strings.ToLower(id)]
+			if !ok {
+				jar.Issues = append(jar.Issues, fmt.Sprintf("missing dependency %s %s", id, constraint))
+			} else if match, ok := Match(constraint, dep.Version); ok && !match {
+				jar.Issues = append(jar.Issues, fmt.Sprintf("require %s %s, installed %s", id, constraint, dep.Version))
+			}
+		}
+		for _, id := range slices.Sorted(maps.Keys(jar.Incompatibles)) {
+			if other, ok := enabled[strings.ToLower(id)]; ok && jar.Enabled {
+				if match, ok := Match(jar.Incompatibles[id], other.Version); !ok || match {
+					jar.Issues = append(jar.Issues, fmt.Sprintf("incompatible with %s %s", id, other.Version))
+				}
+			}
+		}
+	}
+}
+
+// Find jar by kind and file name
+func find(jars []*Jar, kind Kind, file string) *Jar {
+	file, _ = validName(file)
+	for _, jar := range jars {
+		if jar.Kind == kind && jar.File == file {
+			return jar
+		}
+	}
+	return nil
+}
+
+func jarPath(dir string, jar *Jar) string {
+	name := filepath.Join(dir, string(jar.Kind), jar.File)
+	if !jar.Enabled {
+		name += DisabledExt
+	}
+	return name
+}
+
+// Install plugin or mod jar, directory from jar metadata.
+// Jar with same id is replaced, jars without metadata are refused.
+func Install(dir string, srv *server.Server, file string, r io.ReaderAt, size int64) (*Jar, error) {
+	if err := javaServer(srv); err != nil {
+		return nil, err
+	}
+	file, ok := validName(filepath.Base(file))
+	if !ok {
+		return nil, ErrInvalidName
+	}
+	jar, err := Read(r, size)
+	if err != nil {
+		return nil, err
+	}
+	jar.File, jar.Enabled, jar.Size = file, true, size
+
+	jarsMu.Lock()
+	defer jarsMu.Unlock()
+	jars, err := list(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	var old *Jar
+	for _, installed := range jars {
+		if installed.Kind == jar.Kind && strings.EqualFold(installed.ID, jar.ID) {
+			old = installed // Upgrade
+		}
+	}
+	if other := find(jars, jar.Kind, file); other != nil && other != old {
+		return nil, ErrJarExists
+	}
+
+	target := filepath.Join(dir, string(jar.Kind))
+	if err := os.MkdirAll(target, 0755); err != nil {
+		return nil, err
+	}
+	tmp, err := os.CreateTemp(target, ".install-*.jar")
+	if err != nil {
+		return nil, err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err = io.Copy(tmp, io.NewSectionReader(r, 0, size)); err != nil {
+		tmp.Close()
+		return nil, err
+	} else if err = tmp.Close(); err != nil {
+		return nil, err
+	}
+
+	// New jar is moved in place before old is removed, old jar with same name is replaced by rename
+	if err = os.Rename(tmp.Name(), jarPath(dir, jar)); err != nil {
+		return nil, err
+	} else if old != nil && jarPath(dir, old) != jarPath(dir, jar) {
+		if err := os.Remove(jarPath(dir, old)); err != nil {
+			os.Remove(jarPath(dir, jar)) // Keep only old version
+			return nil, err
+		}
+	}
+
+	Check(append(slices.DeleteFunc(jars, func(item *Jar) bool { return item == old }), jar), srv)
+	return jar, nil
+}
+
+// Enable or disable jar, disabled jars are renamed with [DisabledExt]
+func SetEnabled(dir string, srv *server.Server, kind Kind, file string, enabled bool) (*Jar, error) {
+	if err := javaServer(srv); err != nil {
+		return nil, err
+	} else if kind != Plugin && kind != Mod {
+		return nil, ErrInvalidKind
+	}
+
+	jarsMu.Lock()
+	defer jarsMu.Unlock()
+	jars, err := list(dir)
+	if err != nil {
+		return nil, err
+	}
+	jar := find(jars, kind, file)
+	if jar == nil {
+		return nil, ErrJarNotExists
+	}
+	if jar.Enabled != enabled {
+		old := jarPath(dir, jar)
+		jar.Enabled = enabled
+		if err := os.Rename(old, jarPath(dir, jar)); err != nil {
+			return nil, err
+		}
+	}
+	Check(jars, srv)
+	return jar, nil
+}
+
+// Delete jar file
+func Delete(dir string, srv *server.Server, kind Kind, file string) error {
+	if err := javaServer(srv); err != nil {
+		return err
+	} else if kind != Plugin && kind != Mod {
+		return ErrInvalidKind
+	}
+
+	jarsMu.Lock()
+	defer jarsMu.Unlock()
+	jars, err := list(dir)
+	if err != nil {
+		return err
+	}
+	jar := find(jars, kind, file)
+	if jar == nil {
+		return ErrJarNotExists
+	}
+	return os.Remove(jarPath(dir, jar))
+}
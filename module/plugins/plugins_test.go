@@ -0,0 +1,106 @@
+package plugins
+
+import (
+	"archive/zip"
+	"bytes"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"sirherobrine23.com.br/go-bds/bds/module/server"
+)
+
+func jarFile(name, data string) *bytes.Reader {
+	var buff bytes.Buffer
+	zw := zip.NewWriter(&buff)
+	w, _ := zw.Create(name)
+	w.Write([]byte(data))
+	zw.Close()
+	return bytes.NewReader(buff.Bytes())
+}
+
+func TestMatch(t *testing.T) {
+	for _, test := range []struct {
+		constraint, version string
+		match               bool
+	}{
+		{">=1.20 <1.21", "1.20.4", true},
+		{">=1.20 <1.21", "1.21.1", false},
+		{"~1.20.1", "1.20.6", true},
+		{"~1.20.1", "1.21", false},
+		{"1.21.x", "1.21.4", true},
+		{"1.20.4 || 1.21.x", "1.21", true},
+		{"*", "1.21.4", true},
+		{">=1.21-", "1.21-rc.1", true},
+	} {
+		if match, ok := Match(test.constraint, test.version); !ok || match != test.match {
+			t.Errorf("Match(%q, %q) = %v, %v", test.constraint, test.version, match, ok)
+		}
+	}
+}
+
+func TestPlugins(t *testing.T) {
+	dir := t.TempDir()
+	paper := &server.Server{Software: "paper", Version: "1.20.4"}
+
+	jar := jarFile(PluginFile, `name: Essentials
+version: "2.20.1" # Release
+main: com.earth2me.essentials.Essentials
+api-version: '1.21'
+authors: [zenexer, ementalo]
+depend:
+  - Vault
+commands:
+  home:
+    aliases:
+      - h
+`)
+	installed, err := Install(dir, paper, "EssentialsX.jar", jar, jar.Size())
+	if err != nil {
+		t.Errorf("cannot install plugin: %s", err)
+		return
+	} else if installed.Name != "Essentials" || installed.Version != "2.20.1" || !slices.Equal(installed.Authors, []string{"zenexer", "ementalo"}) || !slices.Equal(installed.Depends, []string{"Vault"}) {
+		t.Errorf("invalid plugin metadata: %+v", installed)
+		return
+	} else if !slices.Contains(installed.Issues, "missing dependency Vault") || !slices.Contains(installed.Issues, "require api-version 1.21, server is 1.20.4") {
+		t.Errorf("issues not flagged: %q", installed.Issues)
+		return
+	}
+
+	mod := jarFile(FabricFile, `{"schemaVersion": 1, "id": "lithium", "version": "0.12.0", "authors": ["JellySquid", {"name": "2No2Name"}], "depends": {"minecraft": "~1.21", "fabricloader": ">=0.15"}}`)
+	if installed, err = Install(dir, paper, "lithium.jar", mod, mod.Size()); err != nil {
+		t.Errorf("cannot install mod: %s", err)
+		return
+	} else if _, err = os.Stat(filepath.Join(dir, "mods", "lithium.jar")); err != nil {
+		t.Errorf("mod not installed in mods: %s", err)
+		return
+	} else if len(installed.Issues) != 2 {
+		t.Errorf("mod issues not flagged: %q", installed.Issues)
+		return
+	}
+
+	if installed, err = SetEnabled(dir, paper, Plugin, "EssentialsX.jar", false); err != nil || installed.Enabled {
+		t.Errorf("cannot disable plugin: %v", err)
+		return
+	} else if _, err = os.Stat(filepath.Join(dir, "plugins", "EssentialsX.jar"+DisabledExt)); err != nil {
+		t.Errorf("plugin not renamed: %s", err)
+		return
+	} else if err = Delete(dir, paper, Plugin, "EssentialsX.jar"); err != nil {
+		t.Errorf("cannot delete plugin: %s", err)
+		return
+	} else if jars, _ := List(dir, paper); len(jars) != 1 {
+		t.Errorf("plugin not deleted: %+v", jars)
+		return
+	}
+
+	notJar := jarFile("README.md", "")
+	if _, err = Install(dir, paper, "readme.jar", notJar, notJar.Size()); err != ErrInvalidJar {
+		t.Errorf("jar without metadata installed: %v", err)
+		return
+	}
+	noID := jarFile(ForgeFile, "modLoader=\"javafml\"\n[[dependencies.other]]\nmodId=\"forge\"\n")
+	if _, err = Read(noID, noID.Size()); err != ErrInvalidJar {
+		t.Errorf("mods.toml without mod ID accepted: %v", err)
+	}
+}
@@ -0,0 +1,236 @@
+// Read and write server.properties keeping comments and keys order
+package properties
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// Default file name in server directory
+const FileName = "server.properties"
+
+type line struct {
+	raw   string // Original line, used to comments and blank lines
+	key   string // Key, empty if comment or blank
+	value string // Value
+}
+
+// server.properties file
+type File struct {
+	lines []*line
+}
+
+// Create empty file
+func New() *File { return &File{} }
+
+// Parse properties file, values with backslash escapes and continuation lines like Java properties
+func Parse(r io.Reader) (*File, error) {
+	file := New()
+	scan := bufio.NewScanner(r)
+	for scan.Scan() {
+		raw := strings.TrimRight(scan.Text(), "\r")
+		trimmed := strings.TrimSpace(raw)
+		if trimmed == "" || trimmed[0] == '#' || trimmed[0] == '!' {
+			file.lines = append(file.lines, &line{raw: raw})
+			continue
+		}
+
+		// Line ending with odd backslashes continue in next line
+		for continues(trimmed) && scan.Scan() {
+			next := strings.TrimRight(scan.Text(), "\r")
+			raw += "\n" + next
+			trimmed = trimmed[:len(trimmed)-1] + strings.TrimSpace(next)
+		}
+		key, value := split(trimmed)
+		file.lines = append(file.lines, &line{raw: raw, key: unescape(key), value: unescape(value)})
+	}
+	return file, scan.Err()
+}
+
+func continues(text string) bool {
+	count := len(text) - len(strings.TrimRight(text, "\\"))
+	return count%2 == 1
+}
+
+// Split key and value in first not escaped '=' or ':'
+func split(text string) (string, string) {
+	for index := 0; index < len(text); index++ {
+		switch text[index] {
+		case '\\':
+			index++
+		case '=', ':':
+			return strings.TrimSpace(text[:index]), strings.TrimSpace(text[index+1:])
+		}
+	}
+	return text, ""
+}
+
+func unescape(text string) string {
+	if !strings.Contains(text, "\\") {
+		return text
+	}
+	var buff strings.Builder
+	for index := 0; index < len(text); index++ {
+		if text[index] != '\\' || index+1 == len(text) {
+			buff.WriteByte(text[index])
+			continue
+		}
+		index++
+		switch text[index] {
+		case 't':
+			buff.WriteByte('\t')
+		case 'n':
+			buff.WriteByte('\n')
+		case 'r':
+			buff.WriteByte('\r')
+		case 'f':
+			buff.WriteByte('\f')
+		case 'u':
+			if index+5 <= len(text) {
+				if code, err := strconv.ParseUint(text[index+1:index+5], 16, 16); err == nil {
+					buff.WriteRune(rune(code))
+					index += 4
+					continue
+				}
+			}
+			buff.WriteByte('u')
+		default:
+			buff.WriteByte(text[index]) // \\, \=, \:, \# and \!
+		}
+	}
+	return buff.String()
+}
+
+// Escape backslashes and control characters, key also escape separators and spaces
+func escape(text string, key bool) string {
+	var buff strings.Builder
+	for _, char := range text {
+		switch {
+		case char == '\\':
+			buff.WriteString(`\\`)
+		case char == '\t':
+			buff.WriteString(`\t`)
+		case char == '\n':
+			buff.WriteString(`\n`)
+		case char == '\r':
+			buff.WriteString(`\r`)
+		case char == '\f':
+			buff.WriteString(`\f`)
+		case key && strings.ContainsRune("=: #!", char):
+			buff.WriteByte('\\')
+			buff.WriteRune(char)
+		default:
+			buff.WriteRune(char)
+		}
+	}
+	return buff.String()
+}
+
+// Open and parse file, return empty file if not exists
+func Open(name string) (*File, error) {
+	osFile, err := os.Open(name)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return New(), nil
+		}
+		return nil, err
+	}
+	defer osFile.Close()
+	return Parse(osFile)
+}
+
+// Write file to temporary file and replace name
+func (file *File) Save(name string) error {
+	var buff bytes.Buffer
+	if _, err := file.WriteTo(&buff); err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(name), ".properties-*")
+	if err != nil {
+		return fmt.Errorf("cannot create temporary file: %s", err)
+	}
+	defer os.Remove(tmp.Name())
+	if _, err = tmp.Write(buff.Bytes()); err != nil {
+		tmp.Close()
+		return err
+	} else if err = tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), name)
+}
+
+// Write file, changed keys are written as "key=value"
+func (file *File) WriteTo(w io.Writer) (int64, error) {
+	var total int64
+	for _, ln := range file.lines {
+		text := ln.raw
+		if ln.key != "" {
+			text = escape(ln.key, true) + "=" + escape(ln.value, false)
+		}
+		n, err := io.WriteString(w, text+"\n")
+		if total += int64(n); err != nil {
+			return total, err
+		}
+	}
+	return total, nil
+}
+
+// Get key value
+func (file *File) Get(key string) (string, bool) {
+	for _, ln := range file.lines {
+		if ln.key == key {
+			return ln.value, true
+		}
+	}
+	return "", false
+}
+
+// Set key value, append key to end if not exists
+func (file *File) Set(key, value string) {
+	for _, ln := range file.lines {
+		if ln.key == key {
+			ln.value = value
+			return
+		}
+	}
+	file.lines = append(file.lines, &line{key: key, value: value})
+}
+
+// Remove key from file
+func (file *File) Delete(key string) {
+	for index, ln := range file.lines {
+		if ln.key == key {
+			file.lines = append(file.lines[:index], file.lines[index+1:]...)
+			return
+		}
+	}
+}
+
+// Keys in file order
+func (file *File) Keys() []string {
+	var keys []string
+	for _, ln := range file.lines {
+		if ln.key != "" {
+			keys = append(keys, ln.key)
+		}
+	}
+	return keys
+}
+
+// Return all keys and values
+func (file *File) Map() map[string]string {
+	values := map[string]string{}
+	for _, ln := range file.lines {
+		if ln.key != "" {
+			values[ln.key] = ln.value
+		}
+	}
+	return values
+}
@@ -0,0 +1,92 @@
+package properties
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+const bedrockProperties = `server-name=Dedicated Server
+# Used as the server name
+# Allowed values: Any string without semicolon symbol.
+
+gamemode=survival
+# Sets the game mode for new players.
+max-players=10
+custom-key = value with spaces
+`
+
+func TestParse(t *testing.T) {
+	file, err := Parse(strings.NewReader(bedrockProperties))
+	if err != nil {
+		t.Errorf("cannot parse properties: %s", err)
+		return
+	}
+
+	if value, _ := file.Get("custom-key"); value != "value with spaces" {
+		t.Errorf("invalid custom-key value: %q", value)
+		return
+	}
+
+	file.Set("gamemode", "creative")
+	file.Set("level-seed", "1234")
+	var buff bytes.Buffer
+	if _, err = file.WriteTo(&buff); err != nil {
+		t.Errorf("cannot write properties: %s", err)
+		return
+	}
+
+	expected := strings.Replace(bedrockProperties, "gamemode=survival", "gamemode=creative", 1)
+	expected = strings.Replace(expected, "custom-key = value with spaces", "custom-key=value with spaces", 1) + "level-seed=1234\n"
+	if buff.String() != expected {
+		t.Errorf("comments or order not preserved:\n%s\nexpected:\n%s", buff.String(), expected)
+	}
+}
+
+func TestEscapes(t *testing.T) {
+	file, err := Parse(strings.NewReader("resource-pack=https\\://example.com/pack.zip\nmotd=\\u00a7aHello \\\n    World\nlevel-name=C\\\\worlds\n"))
+	if err != nil {
+		t.Errorf("cannot parse properties: %s", err)
+		return
+	} else if value, _ := file.Get("resource-pack"); value != "https://example.com/pack.zip" {
+		t.Errorf("invalid resource-pack value: %q", value)
+		return
+	} else if value, _ = file.Get("motd"); value != "§aHello World" {
+		t.Errorf("invalid motd value: %q", value)
+		return
+	}
+
+	var buff bytes.Buffer
+	file.WriteTo(&buff)
+	if !strings.Contains(buff.String(), "level-name=C\\\\worlds\n") {
+		t.Errorf("backslash not escaped:\n%s", buff.String())
+	}
+}
+
+func TestValidate(t *testing.T) {
+	for _, test := range []struct {
+		Software, Key, Value string
+		Valid                bool
+	}{
+		{"bedrock", "gamemode", "creative", true},
+		{"bedrock", "gamemode", "spectator", false},
+		{"java", "gamemode", "spectator", true},
+		{"bedrock", "server-port", "19132", true},
+		{"bedrock", "server-port", "70000", false},
+		{"bedrock", "max-players", "many", false},
+		{"java", "pvp", "yes", false},
+		{"java", "unknown-key", "anything", true},
+		{"java", "motd", "line\nbreak", false},
+		{"java", "bad=key", "value", false},
+		{"java", "level-name", "worlds/survival", true},
+		{"java", "level-name", "../other", false},
+		{"java", "level-name", "/etc", false},
+		{"java", "level-name", "worlds/../..", false},
+		{"bedrock", "level-name", "Bedrock level", true},
+		{"bedrock", "level-name", "../Bedrock level", false},
+	} {
+		if err := Validate(test.Software, test.Key, test.Value); (err == nil) != test.Valid {
+			t.Errorf("%s %s=%q: expected valid %v, got %v", test.Software, test.Key, test.Value, test.Valid, err)
+		}
+	}
+}
@@ -0,0 +1,177 @@
+package properties
+
+import (
+	"fmt"
+	"path"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// Value type
+type Type string
+
+const (
+	String Type = "string"
+	Int    Type = "int"
+	Bool   Type = "bool"
+	Enum   Type = "enum"
+)
+
+// Known server.properties key
+type Key struct {
+	Name        string   `json:"name"`          // Key name
+	Type        Type     `json:"type"`          // Value type
+	Default     string   `json:"default"`       // Default value
+	Values      []string `json:"values"`        // Valid values to enum
+	Min         *int     `json:"min,omitempty"` // Min value to int
+	Max         *int     `json:"max,omitempty"` // Max value to int
+	Restart     bool     `json:"restart"`       // Server require restart to apply
+	Description string   `json:"description"`   // Key description
+}
+
+func intPtr(n int) *int { return &n }
+
+var (
+	gamemodes    = []string{"survival", "creative", "adventure"}
+	difficulties = []string{"peaceful", "easy", "normal", "hard"}
+
+	// Bedrock dedicated server keys
+	BedrockKeys = []Key{
+		{Name: "server-name", Type: String, Default: "Dedicated Server", Description: "Server name showed in LAN games"},
+		{Name: "gamemode", Type: Enum, Default: "survival", Values: gamemodes, Description: "Game mode to new players"},
+		{Name: "force-gamemode", Type: Bool, Default: "false", Description: "Force gamemode to players"},
+		{Name: "difficulty", Type: Enum, Default: "easy", Values: difficulties, Description: "World difficulty"},
+		{Name: "allow-cheats", Type: Bool, Default: "false", Description: "Allow commands with cheats"},
+		{Name: "max-players", Type: Int, Default: "10", Min: intPtr(1), Description: "Max players online"},
+		{Name: "online-mode", Type: Bool, Default: "true", Description: "Require Xbox Live authentication"},
+		{Name: "allow-list", Type: Bool, Default: "false", Description: "Only players in allowlist.json can join"},
+		{Name: "server-port", Type: Int, Default: "19132", Min: intPtr(1), Max: intPtr(65535), Restart: true, Description: "IPv4 port"},
+		{Name: "server-portv6", Type: Int, Default: "19133", Min: intPtr(1), Max: intPtr(65535), Restart: true, Description: "IPv6 port"},
+		{Name: "enable-lan-visibility", Type: Bool, Default: "true", Restart: true, Description: "Show server in LAN games"},
+		{Name: "view-distance", Type: Int, Default: "32", Min: intPtr(5), Description: "Max view distance in chunks"},
+		{Name: "tick-distance", Type: Int, Default: "4", Min: intPtr(4), Max: intPtr(12), Description: "World simulation distance in chunks"},
+		{Name: "player-idle-timeout", Type: Int, Default: "30", Min: intPtr(0), Description: "Minutes to kick idle players, 0 to disable"},
+		{Name: "max-threads", Type: Int, Default: "8", Min: intPtr(0), Restart: true, Description: "Max threads, 0 to use all"},
+		{Name: "level-name", Type: String, Default: "Bedrock level", Restart: true, Description: "World directory name"},
+		{Name: "level-seed", Type: String, Default: "", Restart: true, Description: "World seed to new worlds"},
+		{Name: "default-player-permission-level", Type: Enum, Default: "member", Values: []string{"visitor", "member", "operator"}, Description: "Permission to new players"},
+		{Name: "texturepack-required", Type: Bool, Default: "false", Description: "Force clients to use world packs"},
+		{Name: "content-log-file-enabled", Type: Bool, Default: "false", Description: "Write content errors to file"},
+		{Name: "compression-threshold", Type: Int, Default: "1", Min: intPtr(0), Max: intPtr(65535), Description: "Min packet size to compress"},
+		{Name: "server-authoritative-movement", Type: Enum, Default: "server-auth", Values: []string{"client-auth", "server-auth", "server-auth-with-rewind"}, Restart: true, Description: "Movement authority"},
+		{Name: "emit-server-telemetry", Type: Bool, Default: "false", Description: "Send telemetry to Mojang"},
+	}
+
+	// Java server keys
+	JavaKeys = []Key{
+		{Name: "motd", Type: String, Default: "A Minecraft Server", Description: "Message showed in server list"},
+		{Name: "gamemode", Type: Enum, Default: "survival", Values: append(slices.Clone(gamemodes), "spectator"), Description: "Game mode to new players"},
+		{Name: "force-gamemode", Type: Bool, Default: "false", Description: "Force gamemode to players"},
+		{Name: "difficulty", Type: Enum, Default: "easy", Values: difficulties, Description: "World difficulty"},
+		{Name: "hardcore", Type: Bool, Default: "false", Restart: true, Description: "Hardcore mode"},
+		{Name: "max-players", Type: Int, Default: "20", Min: intPtr(0), Description: "Max players online"},
+		{Name: "online-mode", Type: Bool, Default: "true", Restart: true, Description: "Check players with Mojang"},
+		{Name: "white-list", Type: Bool, Default: "false", Description: "Only players in whitelist.json can join"},
+		{Name: "enforce-whitelist", Type: Bool, Default: "false", Description: "Kick players not in whitelist on reload"},
+		{Name: "server-ip", Type: String, Default: "", Restart: true, Description: "Address to bind"},
+		{Name: "server-port", Type: Int, Default: "25565", Min: intPtr(1), Max: intPtr(65535), Restart: true, Description: "TCP port"},
+		{Name: "pvp", Type: Bool, Default: "true", Description: "Allow player versus player"},
+		{Name: "allow-flight", Type: Bool, Default: "false", Description: "Allow flight in survival"},
+		{Name: "allow-nether", Type: Bool, Default: "true", Restart: true, Description: "Allow nether"},
+		{Name: "spawn-monsters", Type: Bool, Default: "true", Description: "Spawn monsters"},
+		{Name: "level-name", Type: String, Default: "world", Restart: true, Description: "World directory name"},
+		{Name: "level-seed", Type: String, Default: "", Restart: true, Description: "World seed to new worlds"},
+		{Name: "level-type", Type: String, Default: "minecraft:normal", Restart: true, Description: "World generator"},
+		{Name: "view-distance", Type: Int, Default: "10", Min: intPtr(3), Max: intPtr(32), Description: "Max view distance in chunks"},
+		{Name: "simulation-distance", Type: Int, Default: "10", Min: intPtr(3), Max: intPtr(32), Description: "World simulation distance in chunks"},
+		{Name: "spawn-protection", Type: Int, Default: "16", Min: intPtr(0), Description: "Spawn protection radius"},
+		{Name: "enable-rcon", Type: Bool, Default: "false", Restart: true, Description: "Enable remote console"},
+		{Name: "rcon.port", Type: Int, Default: "25575", Min: intPtr(1), Max: intPtr(65535), Restart: true, Description: "RCON port"},
+		{Name: "rcon.password", Type: String, Default: "", Restart: true, Description: "RCON password"},
+		{Name: "enable-query", Type: Bool, Default: "false", Restart: true, Description: "Enable GameSpy4 query"},
+		{Name: "query.port", Type: Int, Default: "25565", Min: intPtr(1), Max: intPtr(65535), Restart: true, Description: "Query UDP port"},
+		{Name: "enable-status", Type: Bool, Default: "true", Description: "Show server in server list"},
+	}
+)
+
+// Known keys to software, Bedrock keys to "bedrock" and Java keys to others
+func Schema(software string) []Key {
+	if strings.EqualFold(software, "bedrock") {
+		return BedrockKeys
+	}
+	return JavaKeys
+}
+
+// Find key in schema
+func Find(software, name string) (Key, bool) {
+	schema := Schema(software)
+	if index := slices.IndexFunc(schema, func(key Key) bool { return key.Name == name }); index >= 0 {
+		return schema[index], true
+	}
+	return Key{}, false
+}
+
+// Check value to key, unknown keys always valid
+func (key Key) Validate(value string) error {
+	switch key.Type {
+	case Bool:
+		if value != "true" && value != "false" {
+			return fmt.Errorf("%s: %q is not true or false", key.Name, value)
+		}
+	case Enum:
+		if !slices.Contains(key.Values, value) {
+			return fmt.Errorf("%s: %q is not one of %s", key.Name, value, strings.Join(key.Values, ", "))
+		}
+	case Int:
+		n, err := strconv.Atoi(value)
+		if err != nil {
+			return fmt.Errorf("%s: %q is not integer", key.Name, value)
+		} else if key.Min != nil && n < *key.Min {
+			return fmt.Errorf("%s: %d is less than %d", key.Name, n, *key.Min)
+		} else if key.Max != nil && n > *key.Max {
+			return fmt.Errorf("%s: %d is greater than %d", key.Name, n, *key.Max)
+		}
+	}
+	if strings.ContainsAny(value, "\r\n") {
+		return fmt.Errorf("%s: value cannot have break lines", key.Name)
+	}
+	return nil
+}
+
+// Validate key and value to software
+func Validate(software, name, value string) error {
+	if name == "" || strings.ContainsAny(name, "=\r\n#") {
+		return fmt.Errorf("invalid key name %q", name)
+	}
+	key, ok := Find(software, name)
+	if !ok {
+		key = Key{Name: name, Type: String}
+	} else if name == "level-name" && !ValidLevel(software, value) {
+		return fmt.Errorf("%s: %q is outside server directory", name, value)
+	}
+	return key.Validate(value)
+}
+
+// Check level-name is world inside server directory, empty is default world.
+// Bedrock level-name is directory name in worlds, Java is path relative to server directory
+func ValidLevel(software, level string) bool {
+	if level == "" {
+		return true
+	} else if strings.ContainsAny(level, "\\\x00") || level == "." || level == ".." {
+		return false
+	} else if strings.EqualFold(software, "bedrock") {
+		return !strings.Contains(level, "/")
+	}
+	return filepath.IsLocal(filepath.FromSlash(level)) && path.Clean(level) != "."
+}
+
+// Set default values not defined in file
+func (file *File) SetDefaults(software string) {
+	for _, key := range Schema(software) {
+		if _, ok := file.Get(key.Name); !ok {
+			file.Set(key.Name, key.Default)
+		}
+	}
+}
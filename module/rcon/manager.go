@@ -0,0 +1,158 @@
+package rcon
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+
+	"sirherobrine23.com.br/go-bds/bds/module/db"
+	"sirherobrine23.com.br/go-bds/bds/module/encrypt"
+	"sirherobrine23.com.br/go-bds/bds/module/properties"
+	"sirherobrine23.com.br/go-bds/bds/module/runner"
+	"sirherobrine23.com.br/go-bds/bds/module/server"
+)
+
+const DefaultPort = 25575 // Java default rcon.port
+
+var (
+	ErrManagedKey error = errors.New("rcon keys in server.properties are managed by server")
+
+	ManagedKeys = []string{"enable-rcon", "rcon.password", "rcon.port"} // server.properties keys set in [Manager.PreStart]
+)
+
+// Report if server.properties key is managed by RCON and cannot be changed by users
+func Managed(key string) bool { return slices.Contains(ManagedKeys, key) }
+
+// Remove RCON password from server.properties before send to users
+func Redact(file *properties.File) { file.Delete("rcon.password") }
+
+// Copy managed keys from old to file, return [ErrManagedKey] if file change any managed key.
+//
+// Managed keys not in file are kept from old, so file read with redacted password can be saved back
+func KeepManaged(old, file *properties.File) error {
+	for _, key := range ManagedKeys {
+		oldValue, oldExists := old.Get(key)
+		if value, exists := file.Get(key); !exists {
+			if oldExists {
+				file.Set(key, oldValue)
+			}
+		} else if !oldExists || value != oldValue {
+			return ErrManagedKey
+		}
+	}
+	return nil
+}
+
+// Enable RCON in Java servers and use it to send commands when server stdin is not attached
+type Manager struct {
+	Database   db.Database
+	EncryptKey string // Instance key to encrypt passwords in database, RCON is not enabled without key
+}
+
+// Create new RCON maneger and add hooks to runner
+func NewManager(database db.Database, manager *runner.Manager, encryptKey string) *Manager {
+	mg := &Manager{Database: database, EncryptKey: encryptKey}
+	manager.PreStart = append(manager.PreStart, mg.PreStart)
+	manager.OnStart = append(manager.OnStart, mg.Attach)
+	return mg
+}
+
+// Generate random password
+func GeneratePassword() (string, error) {
+	buff := make([]byte, 24)
+	if _, err := rand.Read(buff); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(buff), nil
+}
+
+func (mg *Manager) enabled(srv *server.Server) bool {
+	return mg.EncryptKey != "" && !strings.EqualFold(srv.Software, "bedrock")
+}
+
+// Server RCON password, generate and store new password if server not have
+func (mg *Manager) Password(srv *server.Server) (string, error) {
+	if srv.RCONPassword != "" {
+		password, err := encrypt.Decrypt(mg.EncryptKey, srv.RCONPassword)
+		if err != nil {
+			return "", fmt.Errorf("cannot decrypt rcon password: %s", err)
+		}
+		return password, nil
+	}
+
+	password, err := GeneratePassword()
+	if err != nil {
+		return "", fmt.Errorf("cannot generate rcon password: %s", err)
+	}
+	encrypted, err := encrypt.Encrypt(mg.EncryptKey, password)
+	if err != nil {
+		return "", fmt.Errorf("cannot encrypt rcon password: %s", err)
+	}
+	srv.RCONPassword = encrypted
+	if err = mg.Database.UpdateServer(srv); err != nil {
+		return "", fmt.Errorf("cannot save rcon password: %s", err)
+	}
+	return password, nil
+}
+
+// RCON port from server record or server.properties
+func port(srv *server.Server, file *properties.File) int {
+	if srv.RCONPort > 0 {
+		return srv.RCONPort
+	} else if value, ok := file.Get("rcon.port"); ok {
+		if number, err := strconv.Atoi(value); err == nil && number > 0 {
+			return number
+		}
+	}
+	return DefaultPort
+}
+
+// Hook to [runner.Manager.PreStart], enable RCON in server.properties with server password
+func (mg *Manager) PreStart(srv *server.Server, dir string) error {
+	if !mg.enabled(srv) {
+		return nil
+	}
+	password, err := mg.Password(srv)
+	if err != nil {
+		return err
+	}
+
+	name := filepath.Join(dir, properties.FileName)
+	file, err := properties.Open(name)
+	if err != nil {
+		return fmt.Errorf("cannot open server.properties: %s", err)
+	}
+	file.Set("enable-rcon", "true")
+	file.Set("rcon.password", password)
+	file.Set("rcon.port", strconv.Itoa(port(srv, file)))
+	if err = file.Save(name); err != nil {
+		return fmt.Errorf("cannot save server.properties: %s", err)
+	}
+	return nil
+}
+
+// Hook to [runner.Manager.OnStart], set RCON client as process commander, RCON is connected in process host
+func (mg *Manager) Attach(proc *runner.Process) {
+	if !mg.enabled(proc.Server) || proc.Server.RCONPassword == "" {
+		return
+	}
+	password, err := encrypt.Decrypt(mg.EncryptKey, proc.Server.RCONPassword)
+	if err != nil {
+		return
+	}
+	file, err := properties.Open(filepath.Join(proc.Dir, properties.FileName))
+	if err != nil {
+		return
+	}
+	host := proc.Host
+	if host == "" {
+		host = runner.DefaultHost
+	}
+	proc.SetCommander(NewClient(net.JoinHostPort(host, strconv.Itoa(port(proc.Server, file))), password))
+}
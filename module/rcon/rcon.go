@@ -0,0 +1,209 @@
+// Minecraft RCON client and Java servers RCON with generated passwords
+package rcon
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Packets types
+const (
+	TypeResponse int32 = 0
+	TypeCommand  int32 = 2
+	TypeAuth     int32 = 3
+)
+
+const (
+	MaxCommand = 1446 // Max command length accepted by server
+	maxPacket  = 4096 + 14
+)
+
+var (
+	Timeout = 10 * time.Second // Default connect and command timeout
+
+	ErrAuth          error = errors.New("rcon authentication failed")
+	ErrInvalidPacket error = errors.New("invalid rcon packet")
+	ErrCommandLength error = errors.New("command too long to rcon")
+	ErrClosed        error = errors.New("rcon client closed")
+)
+
+type packet struct {
+	ID   int32
+	Type int32
+	Body string
+}
+
+func writePacket(w io.Writer, pkt packet) error {
+	buff := make([]byte, 0, 14+len(pkt.Body))
+	buff = binary.LittleEndian.AppendUint32(buff, uint32(10+len(pkt.Body)))
+	buff = binary.LittleEndian.AppendUint32(buff, uint32(pkt.ID))
+	buff = binary.LittleEndian.AppendUint32(buff, uint32(pkt.Type))
+	buff = append(buff, pkt.Body...)
+	_, err := w.Write(append(buff, 0, 0))
+	return err
+}
+
+func readPacket(r io.Reader) (packet, error) {
+	var size int32
+	if err := binary.Read(r, binary.LittleEndian, &size); err != nil {
+		return packet{}, err
+	} else if size < 10 || size > maxPacket {
+		return packet{}, fmt.Errorf("%w: size %d", ErrInvalidPacket, size)
+	}
+	data := make([]byte, size)
+	if _, err := io.ReadFull(r, data); err != nil {
+		return packet{}, err
+	}
+	return packet{
+		ID:   int32(binary.LittleEndian.Uint32(data[0:4])),
+		Type: int32(binary.LittleEndian.Uint32(data[4:8])),
+		Body: strings.TrimRight(string(data[8:]), "\x00"),
+	}, nil
+}
+
+// RCON client, connect on first command and reconnect if connection is lost
+type Client struct {
+	Address  string
+	Password string
+	Timeout  time.Duration
+
+	mu     sync.Mutex
+	conn   net.Conn
+	reader *bufio.Reader
+	id     int32
+	closed bool
+}
+
+// Create new client, connection is made on first command
+func NewClient(address, password string) *Client {
+	return &Client{Address: address, Password: password, Timeout: Timeout}
+}
+
+// Connect and authenticate now
+func Dial(address, password string) (*Client, error) {
+	client := NewClient(address, password)
+	client.mu.Lock()
+	defer client.mu.Unlock()
+	if err := client.connect(); err != nil {
+		return nil, err
+	}
+	return client, nil
+}
+
+func (client *Client) nextID() int32 {
+	if client.id++; client.id <= 0 {
+		client.id = 1 // -1 is auth fail
+	}
+	return client.id
+}
+
+func (client *Client) reset() {
+	if client.conn != nil {
+		client.conn.Close()
+	}
+	client.conn, client.reader = nil, nil
+}
+
+func (client *Client) connect() error {
+	conn, err := net.DialTimeout("tcp", client.Address, client.Timeout)
+	if err != nil {
+		return fmt.Errorf("cannot connect to rcon: %s", err)
+	}
+	client.conn, client.reader = conn, bufio.NewReader(conn)
+	conn.SetDeadline(time.Now().Add(client.Timeout))
+
+	id := client.nextID()
+	if err = writePacket(conn, packet{ID: id, Type: TypeAuth, Body: client.Password}); err != nil {
+		client.reset()
+		return fmt.Errorf("cannot send rcon auth: %s", err)
+	}
+	for {
+		pkt, err := readPacket(client.reader)
+		if err != nil {
+			client.reset()
+			return fmt.Errorf("cannot read rcon auth: %s", err)
+		} else if pkt.Type != TypeCommand {
+			continue // Some servers send empty response before auth response
+		} else if pkt.ID == -1 || pkt.ID != id {
+			client.reset()
+			return ErrAuth
+		}
+		return nil
+	}
+}
+
+// Send command and read all response packets.
+//
+// Server split long responses in many packets, so packet with invalid type is sent after command,
+// server response it after command response and mark end of command response.
+func (client *Client) exec(command string) (string, bool, error) {
+	client.conn.SetDeadline(time.Now().Add(client.Timeout))
+	id, end := client.nextID(), client.nextID()
+	if err := writePacket(client.conn, packet{ID: id, Type: TypeCommand, Body: command}); err != nil {
+		return "", false, err
+	} else if err = writePacket(client.conn, packet{ID: end, Type: TypeResponse}); err != nil {
+		return "", false, err
+	}
+
+	var response strings.Builder
+	read := false
+	for {
+		pkt, err := readPacket(client.reader)
+		if err != nil {
+			return "", read, err
+		}
+		read = true
+		switch pkt.ID {
+		case id:
+			response.WriteString(pkt.Body)
+		case end:
+			return response.String(), true, nil
+		}
+	}
+}
+
+// Send command to server and return response, reconnect if connection lost before response
+func (client *Client) Command(command string) (string, error) {
+	if len(command) > MaxCommand {
+		return "", ErrCommandLength
+	}
+	client.mu.Lock()
+	defer client.mu.Unlock()
+	if client.closed {
+		return "", ErrClosed
+	}
+
+	for attempt := 0; ; attempt++ {
+		reconnected := client.conn == nil
+		if reconnected {
+			if err := client.connect(); err != nil {
+				return "", err
+			}
+		}
+		response, read, err := client.exec(command)
+		if err == nil {
+			return response, nil
+		}
+		client.reset()
+		// Retry only with old connection and without response, command may be executed
+		if read || reconnected || attempt > 0 {
+			return "", fmt.Errorf("cannot send rcon command: %s", err)
+		}
+	}
+}
+
+// Close connection, client cannot be used after close
+func (client *Client) Close() error {
+	client.mu.Lock()
+	defer client.mu.Unlock()
+	client.closed = true
+	client.reset()
+	return nil
+}
@@ -0,0 +1,160 @@
+package rcon
+
+import (
+	"bufio"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"sirherobrine23.com.br/go-bds/bds/module/db"
+	"sirherobrine23.com.br/go-bds/bds/module/encrypt"
+	"sirherobrine23.com.br/go-bds/bds/module/properties"
+	"sirherobrine23.com.br/go-bds/bds/module/runner"
+	"sirherobrine23.com.br/go-bds/bds/module/server"
+	"sirherobrine23.com.br/go-bds/bds/module/users"
+)
+
+// Local RCON server like vanilla, responses splited in 4096 bytes and unknown types responded.
+// Connections are closed after "drop" command without response.
+func rconServer(t *testing.T, password string, connections *atomic.Int32) string {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Skipf("cannot listen tcp: %s", err)
+	}
+	t.Cleanup(func() { ln.Close() })
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			connections.Add(1)
+			go func() {
+				defer conn.Close()
+				reader, authed := bufio.NewReader(conn), false
+				for {
+					pkt, err := readPacket(reader)
+					if err != nil {
+						return
+					}
+					switch {
+					case pkt.Type == TypeAuth && pkt.Body == password:
+						authed = true
+						writePacket(conn, packet{ID: pkt.ID, Type: TypeCommand})
+					case pkt.Type == TypeAuth:
+						writePacket(conn, packet{ID: -1, Type: TypeCommand})
+					case pkt.Type == TypeCommand && authed && pkt.Body == "drop":
+						return
+					case pkt.Type == TypeCommand && authed:
+						response := fmt.Sprintf("Executed: %s", pkt.Body)
+						if pkt.Body == "help" {
+							response = strings.Repeat("/help [command]\n", 600)
+						}
+						for len(response) > 4096 {
+							writePacket(conn, packet{ID: pkt.ID, Type: TypeResponse, Body: response[:4096]})
+							response = response[4096:]
+						}
+						writePacket(conn, packet{ID: pkt.ID, Type: TypeResponse, Body: response})
+					default:
+						writePacket(conn, packet{ID: pkt.ID, Type: TypeResponse, Body: fmt.Sprintf("Unknown request %x", pkt.Type)})
+					}
+				}
+			}()
+		}
+	}()
+	return ln.Addr().String()
+}
+
+func TestClient(t *testing.T) {
+	var connections atomic.Int32
+	address := rconServer(t, "secret", &connections)
+	if _, err := Dial(address, "wrong"); err != ErrAuth {
+		t.Errorf("auth with wrong password: %v", err)
+		return
+	}
+
+	client := NewClient(address, "secret")
+	defer client.Close()
+	if response, err := client.Command("list"); err != nil || response != "Executed: list" {
+		t.Errorf("invalid response: %q, %v", response, err)
+		return
+	} else if response, err = client.Command("help"); err != nil || response != strings.Repeat("/help [command]\n", 600) {
+		t.Errorf("multi-packet response not joined: %d bytes, %v", len(response), err)
+		return
+	}
+
+	// Connection lost
+	if _, err := client.Command("drop"); err == nil {
+		t.Errorf("command without response")
+		return
+	} else if response, err := client.Command("say hello"); err != nil || response != "Executed: say hello" {
+		t.Errorf("client not reconnected: %q, %v", response, err)
+		return
+	} else if connections.Load() != 4 {
+		t.Errorf("invalid connections count: %d", connections.Load())
+	}
+}
+
+func TestManager(t *testing.T) {
+	database, err := db.NewSqliteConnection(":memory:")
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	user, err := database.CreateNewUser(&users.User{}, &users.Password{Password: "test1234"})
+	if err != nil {
+		t.Errorf("cannot make new user in database: %s", err)
+		return
+	}
+	srv, _ := database.CreateServer(user, &server.Server{Software: "paper", Version: "1.21.4", Owner: user.UserID})
+
+	manager := runner.NewManager(t.TempDir(), nil)
+	mg := NewManager(database, manager, "instance key")
+	dir := manager.Dir(srv.ID)
+	os.MkdirAll(dir, 0755)
+	if err = mg.PreStart(srv, dir); err != nil {
+		t.Errorf("cannot enable rcon: %s", err)
+		return
+	}
+
+	stored, _ := database.Server(srv.ID)
+	password, err := encrypt.Decrypt("instance key", stored.RCONPassword)
+	if err != nil {
+		t.Errorf("cannot decrypt stored password: %s", err)
+		return
+	}
+	file, _ := properties.Open(filepath.Join(dir, properties.FileName))
+	if value, _ := file.Get("rcon.password"); value != password || len(password) != 48 {
+		t.Errorf("password not writed to server.properties: %q", value)
+		return
+	} else if value, _ = file.Get("enable-rcon"); value != "true" {
+		t.Errorf("rcon not enabled")
+		return
+	}
+
+	// Password is kept in next starts
+	if err = mg.PreStart(stored, dir); err != nil {
+		t.Errorf("cannot enable rcon: %s", err)
+		return
+	} else if file, _ = properties.Open(filepath.Join(dir, properties.FileName)); file.Map()["rcon.password"] != password {
+		t.Errorf("password changed in restart")
+		return
+	}
+
+	// Users edit file read with redacted password
+	edited, _ := properties.Open(filepath.Join(dir, properties.FileName))
+	Redact(edited)
+	edited.Set("motd", "edited")
+	if err = KeepManaged(file, edited); err != nil || edited.Map()["rcon.password"] != password {
+		t.Errorf("redacted password not kept: %v", err)
+		return
+	}
+	edited.Set("rcon.port", "25580")
+	if err = KeepManaged(file, edited); err != ErrManagedKey {
+		t.Errorf("managed key changed: %v", err)
+	}
+}
@@ -0,0 +1,223 @@
+package runner
+
+import (
+	"errors"
+	"sync"
+	"time"
+)
+
+var (
+	ErrSlowConsumer error = errors.New("subscriber too slow, output dropped")
+	ErrOutputClosed error = errors.New("process output closed")
+
+	DefaultScrollback = 1000 // Lines keep in memory to replay to new viewers
+	DefaultBuffer     = 256  // Lines buffered to each subscriber before drop
+)
+
+// Output stream name
+type Stream string
+
+const (
+	Stdout Stream = "stdout"
+	Stderr Stream = "stderr"
+	Stdin  Stream = "stdin" // Commands sent to process
+)
+
+// Line printed by process
+type Line struct {
+	Time   time.Time `json:"time"`   // Time line received
+	Stream Stream    `json:"stream"` // stdout or stderr
+	Text   string    `json:"line"`   // Line without break line
+}
+
+// Broadcast process lines to multiple subscribers with scrollback replay
+type Output struct {
+	mu         sync.RWMutex
+	scrollback []Line
+	next       int  // Next index to write in scrollback ring
+	full       bool // Scrollback ring is full
+	closed     bool
+	subs       map[*Subscriber]struct{}
+	listeners  []func(Line)
+}
+
+// Subscriber to process output
+type Subscriber struct {
+	C      <-chan Line // Lines after subscribe
+	Replay []Line      // Lines from scrollback at subscribe time
+
+	ch     chan Line
+	output *Output
+	err    error
+	once   sync.Once
+}
+
+// Create new output with scrollback size, if size <= 0 use [DefaultScrollback]
+func NewOutput(size int) *Output {
+	if size <= 0 {
+		size = DefaultScrollback
+	}
+	return &Output{
+		scrollback: make([]Line, size),
+		subs:       map[*Subscriber]struct{}{},
+	}
+}
+
+// Return copy of current scrollback in order
+func (out *Output) Scrollback() []Line {
+	out.mu.RLock()
+	defer out.mu.RUnlock()
+	return out.scrollbackCopy()
+}
+
+func (out *Output) scrollbackCopy() []Line {
+	if !out.full {
+		return append([]Line(nil), out.scrollback[:out.next]...)
+	}
+	lines := make([]Line, 0, len(out.scrollback))
+	lines = append(lines, out.scrollback[out.next:]...)
+	return append(lines, out.scrollback[:out.next]...)
+}
+
+// Write line to scrollback and send to all subscribers.
+//
+// Never blocks, subscribers with full buffer are closed with [ErrSlowConsumer]
+func (out *Output) Publish(line Line) {
+	out.mu.Lock()
+	defer out.mu.Unlock()
+	if out.closed {
+		return
+	}
+
+	out.scrollback[out.next] = line
+	if out.next++; out.next == len(out.scrollback) {
+		out.next, out.full = 0, true
+	}
+
+	for _, fn := range out.listeners {
+		fn(line)
+	}
+
+	for sub := range out.subs {
+		select {
+		case sub.ch <- line:
+		default:
+			delete(out.subs, sub)
+			sub.close(ErrSlowConsumer)
+		}
+	}
+}
+
+// Call fn to every line, lines in scrollback are replayed to fn before return.
+//
+// fn is called synchronously in [Output.Publish] and should never block, use to storage lines without drops
+func (out *Output) Listen(fn func(Line)) {
+	out.mu.Lock()
+	defer out.mu.Unlock()
+	for _, line := range out.scrollbackCopy() {
+		fn(line)
+	}
+	out.listeners = append(out.listeners, fn)
+}
+
+// Listen lines to unbounded queue, lines in scrollback are queued before return.
+//
+// Use to process all lines in background without block [Output.Publish]
+func (out *Output) Queue() *Queue {
+	queue := &Queue{notify: make(chan struct{}, 1)}
+	out.Listen(queue.push)
+	return queue
+}
+
+// Subscribe to new lines, buffer is size of channel, if buffer <= 0 use [DefaultBuffer]
+func (out *Output) Subscribe(buffer int) *Subscriber {
+	if buffer <= 0 {
+		buffer = DefaultBuffer
+	}
+
+	ch := make(chan Line, buffer)
+	sub := &Subscriber{C: ch, ch: ch, output: out}
+
+	out.mu.Lock()
+	defer out.mu.Unlock()
+	sub.Replay = out.scrollbackCopy()
+	if out.closed {
+		sub.close(ErrOutputClosed)
+		return sub
+	}
+	out.subs[sub] = struct{}{}
+	return sub
+}
+
+// Close all subscribers
+func (out *Output) Close() {
+	out.mu.Lock()
+	defer out.mu.Unlock()
+	out.closed = true
+	for sub := range out.subs {
+		delete(out.subs, sub)
+		sub.close(ErrOutputClosed)
+	}
+}
+
+func (sub *Subscriber) close(err error) {
+	sub.once.Do(func() {
+		sub.err = err
+		close(sub.ch)
+	})
+}
+
+// Return reason of channel closed, nil if open or closed by [Subscriber.Close]
+func (sub *Subscriber) Err() error {
+	sub.output.mu.RLock()
+	defer sub.output.mu.RUnlock()
+	return sub.err
+}
+
+// Stop receiving lines
+func (sub *Subscriber) Close() {
+	sub.output.mu.Lock()
+	defer sub.output.mu.Unlock()
+	delete(sub.output.subs, sub)
+	sub.close(nil)
+}
+
+// Unbounded lines queue from [Output.Queue]
+type Queue struct {
+	mu     sync.Mutex
+	lines  []Line
+	notify chan struct{}
+}
+
+func (queue *Queue) push(line Line) {
+	queue.mu.Lock()
+	queue.lines = append(queue.lines, line)
+	queue.mu.Unlock()
+	select {
+	case queue.notify <- struct{}{}:
+	default:
+	}
+}
+
+func (queue *Queue) take() []Line {
+	queue.mu.Lock()
+	defer queue.mu.Unlock()
+	lines := queue.lines
+	queue.lines = nil
+	return lines
+}
+
+// Wait and return queued lines, return false after done closed and queue empty
+func (queue *Queue) Next(done <-chan struct{}) ([]Line, bool) {
+	for {
+		if lines := queue.take(); len(lines) > 0 {
+			return lines, true
+		}
+		select {
+		case <-queue.notify:
+		case <-done:
+			lines := queue.take()
+			return lines, len(lines) > 0
+		}
+	}
+}
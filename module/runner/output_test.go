@@ -0,0 +1,55 @@
+package runner
+
+import (
+	"strconv"
+	"testing"
+)
+
+func TestOutput(t *testing.T) {
+	out := NewOutput(4)
+	for i := range 6 {
+		out.Publish(Line{Stream: Stdout, Text: strconv.Itoa(i)})
+	}
+
+	sub := out.Subscribe(2)
+	if len(sub.Replay) != 4 || sub.Replay[0].Text != "2" || sub.Replay[3].Text != "5" {
+		t.Errorf("invalid scrollback replay: %v", sub.Replay)
+		return
+	}
+
+	// Fill buffer and drop subscriber
+	for i := range 3 {
+		out.Publish(Line{Stream: Stdout, Text: strconv.Itoa(i)})
+	}
+	for range sub.C {
+	}
+	if sub.Err() != ErrSlowConsumer {
+		t.Errorf("expected slow consumer, got %v", sub.Err())
+		return
+	}
+
+	out.Close()
+	if sub = out.Subscribe(0); sub.Err() != ErrOutputClosed {
+		t.Errorf("expected closed output, got %v", sub.Err())
+	}
+}
+
+func TestQueue(t *testing.T) {
+	out, done := NewOutput(4), make(chan struct{})
+	out.Publish(Line{Stream: Stdout, Text: "0"})
+	queue := out.Queue()
+	for i := range 3 {
+		out.Publish(Line{Stream: Stdout, Text: strconv.Itoa(i + 1)})
+	}
+	close(done)
+
+	var texts []string
+	for lines, ok := queue.Next(done); ok; lines, ok = queue.Next(done) {
+		for _, line := range lines {
+			texts = append(texts, line.Text)
+		}
+	}
+	if len(texts) != 4 || texts[0] != "0" || texts[3] != "3" {
+		t.Errorf("invalid queued lines: %v", texts)
+	}
+}
@@ -0,0 +1,250 @@
+package runner
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"strings"
+	"sync"
+	"time"
+
+	"sirherobrine23.com.br/go-bds/bds/module/server"
+)
+
+var (
+	ErrProcessExited  error = errors.New("process exited")
+	ErrStopTimeout    error = errors.New("process not stopped in time, killed")
+	ErrNoStdin        error = errors.New("server stdin not attached and without commander")
+	DefaultStopTimout       = time.Minute // Time to wait server stop after "stop" command
+	MaxLineSize             = 1024 * 1024 // Longer lines from process are split in many lines
+)
+
+// Send commands to server without stdin, like RCON
+type Commander interface {
+	Command(command string) (string, error) // Send command and return response
+	Close() error
+}
+
+// Running Minecraft server
+type Process struct {
+	Server  *server.Server // Server info
+	Dir     string         // Server work directory
+	Host    string         // Host to connect to server ports
+	StartAt time.Time      // Process start time
+	Output  *Output        // Process output
+
+	cmd       *exec.Cmd
+	stdinMu   sync.Mutex
+	stdin     io.WriteCloser // nil if runner cannot attach to stdin
+	commander Commander
+	done      chan struct{}
+	exitErr   error
+}
+
+// Start process and read stdout and stderr to [Output]
+func StartProcess(srv *server.Server, cmd *exec.Cmd) (*Process, error) {
+	return startProcess(srv, cmd, true)
+}
+
+func startProcess(srv *server.Server, cmd *exec.Cmd, attachStdin bool) (*Process, error) {
+	var stdin io.WriteCloser
+	if attachStdin {
+		var err error
+		if stdin, err = cmd.StdinPipe(); err != nil {
+			return nil, fmt.Errorf("cannot get stdin: %s", err)
+		}
+	}
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, fmt.Errorf("cannot get stdout: %s", err)
+	}
+	stderr, err := cmd.StderrPipe()
+	if err != nil {
+		return nil, fmt.Errorf("cannot get stderr: %s", err)
+	}
+
+	proc := &Process{
+		Server: srv,
+		Dir:    cmd.Dir,
+		Output: NewOutput(0),
+		cmd:    cmd,
+		stdin:  stdin,
+		done:   make(chan struct{}),
+	}
+
+	if err := cmd.Start(); err != nil {
+		return nil, fmt.Errorf("cannot start server: %s", err)
+	}
+	proc.StartAt = time.Now()
+
+	var readers sync.WaitGroup
+	readers.Add(2)
+	go proc.readLines(&readers, Stdout, stdout)
+	go proc.readLines(&readers, Stderr, stderr)
+	go func() {
+		readers.Wait() // Wait all output before wait process
+		proc.exitErr = cmd.Wait()
+		proc.Output.Close()
+		close(proc.done)
+
+		proc.stdinMu.Lock()
+		defer proc.stdinMu.Unlock()
+		if proc.commander != nil {
+			proc.commander.Close()
+		}
+	}()
+
+	return proc, nil
+}
+
+func (proc *Process) readLines(wg *sync.WaitGroup, stream Stream, r io.Reader) {
+	defer wg.Done()
+	defer io.Copy(io.Discard, r) // Never block process output after read error
+
+	reader := bufio.NewReaderSize(r, 64*1024)
+	var line []byte
+	for {
+		part, isPrefix, err := reader.ReadLine()
+		line = append(line, part...)
+		if err == nil && isPrefix && len(line) < MaxLineSize {
+			continue
+		}
+		if len(line) > 0 || (err == nil && !isPrefix) {
+			proc.Output.Publish(Line{
+				Time:   time.Now(),
+				Stream: stream,
+				Text:   strings.TrimRight(string(line), "\r"),
+			})
+		}
+		if err != nil {
+			return
+		}
+		line = line[:0]
+	}
+}
+
+// Write command to server stdin
+func (proc *Process) SendCommand(command string) error {
+	command = strings.TrimSpace(command)
+	commander, err := proc.writeStdin(command)
+	if err == nil || commander == nil {
+		return err
+	}
+
+	// Fallback to commander without stdin lock, commander can wait server response, response is published as stdout
+	response, err := commander.Command(command)
+	if err != nil {
+		return err
+	}
+	proc.Output.Publish(Line{Time: time.Now(), Stream: Stdin, Text: command})
+	for line := range strings.SplitSeq(strings.TrimRight(response, "\n"), "\n") {
+		if line != "" {
+			proc.Output.Publish(Line{Time: time.Now(), Stream: Stdout, Text: line})
+		}
+	}
+	return nil
+}
+
+// Write command to stdin, return current commander if stdin not attached or write fail
+func (proc *Process) writeStdin(command string) (Commander, error) {
+	proc.stdinMu.Lock()
+	defer proc.stdinMu.Unlock()
+	select {
+	case <-proc.done:
+		return nil, ErrProcessExited
+	default:
+	}
+
+	err := ErrNoStdin
+	if proc.stdin != nil {
+		if _, err = io.WriteString(proc.stdin, command+"\n"); err == nil {
+			proc.Output.Publish(Line{Time: time.Now(), Stream: Stdin, Text: command})
+			return nil, nil
+		}
+	}
+	return proc.commander, err
+}
+
+// Set commander used when stdin not attached or write fail, commander is closed on process exit
+func (proc *Process) SetCommander(commander Commander) {
+	proc.stdinMu.Lock()
+	defer proc.stdinMu.Unlock()
+	if proc.commander != nil {
+		proc.commander.Close()
+	}
+	select {
+	case <-proc.done:
+		commander.Close()
+		proc.commander = nil
+	default:
+		proc.commander = commander
+	}
+}
+
+// Channel closed when process exit
+func (proc *Process) Done() <-chan struct{} { return proc.done }
+
+// Return process exit error after [Process.Done] closed
+func (proc *Process) Err() error {
+	select {
+	case <-proc.done:
+		return proc.exitErr
+	default:
+		return nil
+	}
+}
+
+// Process is running
+func (proc *Process) Running() bool {
+	select {
+	case <-proc.done:
+		return false
+	default:
+		return true
+	}
+}
+
+// Send "stop" command and wait process exit, kill process if not exit in timeout
+func (proc *Process) Stop(timeout time.Duration) error {
+	if timeout <= 0 {
+		timeout = DefaultStopTimout
+	}
+
+	if err := proc.SendCommand("stop"); err != nil {
+		if err == ErrProcessExited {
+			return nil
+		}
+		proc.stdinMu.Lock()
+		remote := proc.commander != nil
+		proc.stdinMu.Unlock()
+		if !remote {
+			return proc.Kill()
+		}
+		// Server can close commander connection while stopping, wait exit
+	}
+
+	select {
+	case <-proc.done:
+		return nil
+	case <-time.After(timeout):
+		if err := proc.Kill(); err != nil {
+			return err
+		}
+		return ErrStopTimeout
+	}
+}
+
+// Kill process and wait exit
+func (proc *Process) Kill() error {
+	if !proc.Running() {
+		return nil
+	}
+	if err := proc.cmd.Process.Kill(); err != nil && !errors.Is(err, os.ErrProcessDone) {
+		return err
+	}
+	<-proc.done
+	return nil
+}
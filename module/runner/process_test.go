@@ -0,0 +1,55 @@
+package runner
+
+import (
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestReadLines(t *testing.T) {
+	proc := &Process{Output: NewOutput(0)}
+	long := strings.Repeat("a", MaxLineSize+10)
+
+	var wg sync.WaitGroup
+	wg.Add(1)
+	proc.readLines(&wg, Stdout, strings.NewReader("first\r\n\n"+long+"\nlast"))
+
+	var texts []string
+	for _, line := range proc.Output.Scrollback() {
+		texts = append(texts, line.Text)
+	}
+	if len(texts) != 5 || texts[0] != "first" || texts[1] != "" || texts[4] != "last" {
+		t.Errorf("invalid lines: %d", len(texts))
+		return
+	} else if len(texts[2]) != MaxLineSize || len(texts[3]) != 10 {
+		t.Errorf("long line not split: %d, %d", len(texts[2]), len(texts[3]))
+	}
+}
+
+type testCommander struct{ err error }
+
+func (commander testCommander) Command(command string) (string, error) {
+	return "done " + command, commander.err
+}
+
+func (testCommander) Close() error { return nil }
+
+func TestCommanderFallback(t *testing.T) {
+	proc := &Process{Output: NewOutput(0), done: make(chan struct{}), commander: testCommander{errors.New("rcon closed")}}
+	if err := proc.SendCommand("list"); err == nil {
+		t.Errorf("commander error not returned")
+		return
+	} else if lines := proc.Output.Scrollback(); len(lines) != 0 {
+		t.Errorf("failed command published: %+v", lines)
+		return
+	}
+
+	proc.commander = testCommander{}
+	if err := proc.SendCommand("list"); err != nil {
+		t.Errorf("cannot send command: %s", err)
+		return
+	} else if lines := proc.Output.Scrollback(); len(lines) != 2 || lines[0].Stream != Stdin || lines[1].Text != "done list" {
+		t.Errorf("invalid output: %+v", lines)
+	}
+}
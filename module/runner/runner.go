@@ -0,0 +1,124 @@
+// Start, stop and attach to local Minecraft servers
+package runner
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"time"
+
+	"sirherobrine23.com.br/go-bds/bds/module/server"
+)
+
+var (
+	ErrServerRunning    error = errors.New("server already running")
+	ErrServerNotRunning error = errors.New("server not running")
+	ErrNoCommand        error = errors.New("runner without command builder")
+
+	DefaultHost = "127.0.0.1" // Host to connect to local servers ports
+)
+
+// Build command to start server in work directory
+type CommandBuilder func(srv *server.Server, dir string) (*exec.Cmd, error)
+
+// Local servers maneger
+type Manager struct {
+	Root     string                                       // Root directory to servers data
+	Command  CommandBuilder                               // Function to make server command
+	PreStart []func(srv *server.Server, dir string) error // Functions called before start, error cancel start
+	OnStart  []func(*Process)                             // Functions called after server started
+	Detached bool                                         // Runner cannot attach to servers stdin, commands are sent by [Process.SetCommander]
+	Host     string                                       // Host where servers ports are reachable, [DefaultHost] if empty
+
+	mu        sync.Mutex
+	processes map[int64]*Process
+}
+
+// Create new maneger with servers data in root
+func NewManager(root string, command CommandBuilder) *Manager {
+	return &Manager{
+		Root:      root,
+		Command:   command,
+		processes: map[int64]*Process{},
+	}
+}
+
+// Server data directory
+func (mg *Manager) Dir(serverID int64) string {
+	return filepath.Join(mg.Root, strconv.FormatInt(serverID, 10))
+}
+
+// Get running process, return nil if server not running
+func (mg *Manager) Process(serverID int64) *Process {
+	mg.mu.Lock()
+	defer mg.mu.Unlock()
+	if proc, ok := mg.processes[serverID]; ok && proc.Running() {
+		return proc
+	}
+	return nil
+}
+
+// Start server if not running
+func (mg *Manager) Start(srv *server.Server) (*Process, error) {
+	if mg.Command == nil {
+		return nil, ErrNoCommand
+	}
+
+	mg.mu.Lock()
+	defer mg.mu.Unlock()
+	if proc, ok := mg.processes[srv.ID]; ok && proc.Running() {
+		return proc, ErrServerRunning
+	}
+
+	dir := mg.Dir(srv.ID)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, fmt.Errorf("cannot make server directory: %s", err)
+	}
+	for _, fn := range mg.PreStart {
+		if err := fn(srv, dir); err != nil {
+			return nil, err
+		}
+	}
+
+	cmd, err := mg.Command(srv, dir)
+	if err != nil {
+		return nil, err
+	}
+	if cmd.Dir == "" {
+		cmd.Dir = dir
+	}
+
+	proc, err := startProcess(srv, cmd, !mg.Detached)
+	if err != nil {
+		return nil, err
+	}
+	if proc.Host = mg.Host; proc.Host == "" {
+		proc.Host = DefaultHost
+	}
+	mg.processes[srv.ID] = proc
+	for _, fn := range mg.OnStart {
+		fn(proc)
+	}
+	return proc, nil
+}
+
+// Stop server if running
+func (mg *Manager) Stop(serverID int64, timeout time.Duration) error {
+	proc := mg.Process(serverID)
+	if proc == nil {
+		return ErrServerNotRunning
+	}
+	return proc.Stop(timeout)
+}
+
+// Stop server if running and start again
+func (mg *Manager) Restart(srv *server.Server, timeout time.Duration) (*Process, error) {
+	if err := mg.Stop(srv.ID, timeout); err != nil && err != ErrServerNotRunning && err != ErrStopTimeout {
+		return nil, err
+	}
+	return mg.Start(srv)
+}
@@ -0,0 +1,298 @@
+// Run servers scheduled tasks like restarts, console commands, world saves and backups
+package schedule
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"sirherobrine23.com.br/go-bds/bds/module/backup"
+	"sirherobrine23.com.br/go-bds/bds/module/cron"
+	"sirherobrine23.com.br/go-bds/bds/module/db"
+	"sirherobrine23.com.br/go-bds/bds/module/runner"
+	"sirherobrine23.com.br/go-bds/bds/module/server"
+)
+
+var (
+	ErrInvalidType   error = errors.New("invalid task type")
+	ErrInvalidMissed error = errors.New("invalid missed run policy")
+	ErrNoCommand     error = errors.New("command task without commands")
+	ErrTaskRunning   error = errors.New("task already running")
+	ErrNoBackups     error = errors.New("backups not configured")
+
+	Interval      = time.Second * 30 // Interval to check tasks
+	MissedAfter   = time.Minute * 2  // Task is missed if not run until this time after schedule
+	StopTimeout   = time.Minute      // Max time to wait server stop before kill
+	SaveTimeout   = time.Minute * 5  // Max time to wait world save
+	CountdownUnit = time.Second      // Countdown unit, only changed in tests
+
+	// Seconds left to broadcast before restart or stop
+	CountdownMarks = []int{900, 600, 300, 120, 60, 30, 10, 5, 4, 3, 2, 1}
+)
+
+// Check task fields and schedule, set default missed policy
+func Validate(task *server.Task) error {
+	if task.Name = strings.TrimSpace(task.Name); task.Name == "" {
+		task.Name = string(task.Type)
+	}
+	switch task.Type {
+	case server.TaskCommand:
+		if strings.TrimSpace(task.Command) == "" {
+			return ErrNoCommand
+		}
+	case server.TaskSave, server.TaskRestart, server.TaskStop, server.TaskStart, server.TaskBackup:
+	default:
+		return ErrInvalidType
+	}
+
+	switch task.Missed {
+	case "":
+		task.Missed = server.MissedSkip
+	case server.MissedSkip, server.MissedRunOnce:
+	default:
+		return ErrInvalidMissed
+	}
+
+	if task.Countdown < 0 {
+		task.Countdown = 0
+	}
+	_, err := cron.Parse(task.Schedule)
+	return err
+}
+
+// Run enabled tasks of all servers with cron schedules.
+//
+// Last run is stored in task, so runs missed while panel was stopped are found at start and handled with task missed policy
+type Scheduler struct {
+	Database db.Database
+	Runner   *runner.Manager
+	Backups  *backup.Manager // Backups manager to backup tasks
+
+	mu      sync.Mutex
+	running map[int64]bool
+}
+
+// Create new tasks scheduler
+func NewScheduler(database db.Database, manager *runner.Manager, backups *backup.Manager) *Scheduler {
+	return &Scheduler{
+		Database: database,
+		Runner:   manager,
+		Backups:  backups,
+		running:  map[int64]bool{},
+	}
+}
+
+// Check tasks every [Interval] until context is done, running tasks are canceled
+func (sch *Scheduler) Run(ctx context.Context) error {
+	ticker := time.NewTicker(Interval)
+	defer ticker.Stop()
+	for {
+		sch.runDue(ctx, time.Now())
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// Run due tasks and wait to finish
+func (sch *Scheduler) RunDue(now time.Time) {
+	sch.runDue(context.Background(), now).Wait()
+}
+
+func (sch *Scheduler) runDue(ctx context.Context, now time.Time) *sync.WaitGroup {
+	wg := &sync.WaitGroup{}
+	tasks, err := sch.Database.EnabledTasks()
+	if err != nil {
+		return wg
+	}
+
+	for _, task := range tasks {
+		schedule, err := cron.Parse(task.Schedule)
+		if err != nil {
+			continue
+		}
+
+		last := task.LastRun
+		if last.IsZero() {
+			last = task.CreateAt
+		}
+		due := schedule.Prev(now)
+		if due.IsZero() || !due.After(last) {
+			continue
+		}
+
+		// Schedule passed long ago, panel was stopped or server busy
+		if now.Sub(due) > MissedAfter && task.Missed != server.MissedRunOnce {
+			sch.Database.SetTaskLastRun(task, now)
+			sch.Database.AddTaskRun(&server.TaskRun{
+				TaskID:   task.ID,
+				ServerID: task.ServerID,
+				Status:   server.TaskSkipped,
+				Error:    fmt.Sprintf("missed run at %s", due.Format(time.RFC3339)),
+				StartAt:  now,
+				EndAt:    now,
+			})
+			continue
+		}
+
+		wg.Add(1)
+		go func(task *server.Task) {
+			defer wg.Done()
+			sch.RunTask(ctx, task, now)
+		}(task)
+	}
+	return wg
+}
+
+// Check if task is running
+func (sch *Scheduler) Running(taskID int64) bool {
+	sch.mu.Lock()
+	defer sch.mu.Unlock()
+	return sch.running[taskID]
+}
+
+// Run task now and record in history, return [ErrTaskRunning] if task is already running
+func (sch *Scheduler) RunTask(ctx context.Context, task *server.Task, now time.Time) (*server.TaskRun, error) {
+	sch.mu.Lock()
+	if sch.running[task.ID] {
+		sch.mu.Unlock()
+		return nil, ErrTaskRunning
+	}
+	sch.running[task.ID] = true
+	sch.mu.Unlock()
+	defer func() {
+		sch.mu.Lock()
+		delete(sch.running, task.ID)
+		sch.mu.Unlock()
+	}()
+
+	// Update before run so long countdowns are not started again
+	if err := sch.Database.SetTaskLastRun(task, now); err != nil {
+		return nil, fmt.Errorf("cannot update task last run: %s", err)
+	}
+
+	run := &server.TaskRun{TaskID: task.ID, ServerID: task.ServerID, StartAt: time.Now()}
+	err := sch.execute(ctx, task)
+	run.Status, run.EndAt = server.TaskSuccess, time.Now()
+	if err != nil {
+		run.Status, run.Error = server.TaskFailed, err.Error()
+	}
+	if err := sch.Database.AddTaskRun(run); err != nil {
+		return run, fmt.Errorf("cannot record task run: %s", err)
+	}
+	return run, nil
+}
+
+func (sch *Scheduler) execute(ctx context.Context, task *server.Task) error {
+	srv, err := sch.Database.Server(task.ServerID)
+	if err != nil {
+		return err
+	}
+
+	proc := sch.Runner.Process(srv.ID)
+	if task.Type == server.TaskBackup {
+		return sch.backup(srv) // Running servers use save hold
+	} else if task.Type == server.TaskStart {
+		if proc != nil {
+			return nil
+		}
+		_, err = sch.Runner.Start(srv)
+		return err
+	} else if proc == nil {
+		return runner.ErrServerNotRunning
+	}
+
+	switch task.Type {
+	case server.TaskCommand:
+		for command := range strings.Lines(task.Command) {
+			if command = strings.TrimSpace(command); command == "" {
+				continue
+			} else if err := proc.SendCommand(command); err != nil {
+				return err
+			}
+		}
+		return nil
+	case server.TaskSave:
+		ctx, cancel := context.WithTimeout(ctx, SaveTimeout)
+		defer cancel()
+		return backup.SaveWorld(ctx, proc)
+	case server.TaskRestart:
+		if err := Countdown(ctx, proc, task.Countdown, "restarting"); err != nil {
+			return err
+		}
+		_, err = sch.Runner.Restart(srv, StopTimeout)
+		return err
+	case server.TaskStop:
+		if err := Countdown(ctx, proc, task.Countdown, "stopping"); err != nil {
+			return err
+		}
+		if err = proc.Stop(StopTimeout); err == runner.ErrStopTimeout {
+			err = nil // Process killed
+		}
+		return err
+	}
+	return ErrInvalidType
+}
+
+// Create backup, apply retention policy and record in backup jobs
+func (sch *Scheduler) backup(srv *server.Server) error {
+	if sch.Backups == nil {
+		return ErrNoBackups
+	}
+
+	job := &server.BackupJob{ServerID: srv.ID, StartAt: time.Now()}
+	created, err := sch.Backups.Create(srv)
+	if err == nil {
+		job.BackupID = created.ID
+		job.Removed, err = sch.Backups.Prune(srv.ID)
+	}
+
+	job.Status, job.EndAt = server.BackupJobSuccess, time.Now()
+	if err != nil {
+		job.Status, job.Error = server.BackupJobFailed, err.Error()
+	}
+	sch.Database.AddBackupJob(job)
+	return err
+}
+
+// Broadcast "Server <action> in N seconds" to players in [CountdownMarks] until countdown end
+func Countdown(ctx context.Context, proc *runner.Process, seconds int, action string) error {
+	left := seconds
+	for _, mark := range append(CountdownMarks, 0) {
+		if mark > left {
+			continue
+		}
+
+		// Wait until next mark
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-proc.Done():
+			return runner.ErrProcessExited
+		case <-time.After(time.Duration(left-mark) * CountdownUnit):
+		}
+		left = mark
+		if mark == 0 {
+			break
+		}
+
+		message := fmt.Sprintf("Server %s in %d seconds", action, mark)
+		if mark >= 60 && mark%60 == 0 {
+			message = fmt.Sprintf("Server %s in %d minutes", action, mark/60)
+		}
+		if mark == 1 {
+			message = fmt.Sprintf("Server %s in 1 second", action)
+		} else if mark == 60 {
+			message = fmt.Sprintf("Server %s in 1 minute", action)
+		}
+		if err := proc.SendCommand("say " + message); err != nil {
+			return err
+		}
+	}
+	return nil
+}
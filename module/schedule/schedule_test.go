@@ -0,0 +1,188 @@
+package schedule
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"sirherobrine23.com.br/go-bds/bds/module/backup"
+	"sirherobrine23.com.br/go-bds/bds/module/db"
+	"sirherobrine23.com.br/go-bds/bds/module/runner"
+	"sirherobrine23.com.br/go-bds/bds/module/server"
+	"sirherobrine23.com.br/go-bds/bds/module/users"
+)
+
+const fakeJava = `
+echo "[10:00:00] [Server thread/INFO]: Done (1.000s)!"
+while read -r line; do
+  case "$line" in
+    "save-all flush") echo "[10:00:00] [Server thread/INFO]: Saved the game" ;;
+    "stop") exit 0 ;;
+  esac
+done
+`
+
+func sentCommand(proc *runner.Process, command string) bool {
+	return slices.ContainsFunc(proc.Output.Scrollback(), func(line runner.Line) bool {
+		return line.Stream == runner.Stdin && strings.TrimSpace(line.Text) == command
+	})
+}
+
+func TestScheduler(t *testing.T) {
+	database, err := db.NewSqliteConnection(":memory:")
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	user, err := database.CreateNewUser(&users.User{Username: "schedule"}, &users.Password{Password: "test1234"})
+	if err != nil {
+		t.Errorf("cannot make new user in database: %s", err)
+		return
+	}
+	mcServer, err := database.CreateServer(user, &server.Server{Software: "java", Version: "1.21.4", Owner: user.UserID})
+	if err != nil {
+		t.Errorf("cannot make new server in database: %s", err)
+		return
+	}
+
+	manager := runner.NewManager(filepath.Join(t.TempDir(), "servers"), func(srv *server.Server, dir string) (*exec.Cmd, error) {
+		return exec.Command("sh", "-c", fakeJava), nil
+	})
+	scheduler := NewScheduler(database, manager, nil)
+	CountdownUnit = time.Millisecond * 10
+
+	var tasks []*server.Task
+	for _, task := range []*server.Task{
+		{Type: server.TaskCommand, Command: "say hello\n\nweather clear", Schedule: "*/5 * * * *"},
+		{Type: server.TaskSave, Schedule: "*/5 * * * *", Missed: server.MissedRunOnce},
+		{Type: server.TaskRestart, Schedule: "0 4 * * *", Countdown: 5},
+	} {
+		task.ServerID, task.Enabled = mcServer.ID, true
+		if err := Validate(task); err != nil {
+			t.Errorf("invalid task: %s", err)
+			return
+		} else if task, err = database.CreateTask(task); err != nil {
+			t.Errorf("cannot create task: %s", err)
+			return
+		}
+		tasks = append(tasks, task)
+	}
+	if err := Validate(&server.Task{Type: "jump", Schedule: "* * * * *"}); err != ErrInvalidType {
+		t.Errorf("invalid type accepted: %v", err)
+		return
+	}
+
+	proc, err := manager.Start(mcServer)
+	if err != nil {
+		t.Errorf("cannot start fake server: %s", err)
+		return
+	}
+	defer func() { manager.Stop(mcServer.ID, time.Second) }()
+
+	// Run in time
+	next := tasks[0].CreateAt.Add(time.Minute * 5).Truncate(time.Minute * 5)
+	scheduler.RunDue(next.Add(time.Second * 10))
+	if !sentCommand(proc, "say hello") || !sentCommand(proc, "weather clear") || !sentCommand(proc, "save-all flush") {
+		t.Errorf("commands not sent to server")
+		return
+	} else if task, _ := database.Task(tasks[0].ID); !task.LastRun.Equal(next.Add(time.Second * 10)) {
+		t.Errorf("task last run not saved: %s", task.LastRun)
+		return
+	}
+
+	// Missed by 3 minutes, command task is skipped and save run once
+	scheduler.RunDue(next.Add(time.Hour + time.Minute*3))
+	if runs, _ := database.TaskRuns(tasks[0].ID, 10); len(runs) != 2 || runs[0].Status != server.TaskSkipped {
+		t.Errorf("missed command not skipped: %+v", runs)
+		return
+	} else if runs, _ = database.TaskRuns(tasks[1].ID, 10); len(runs) != 2 || runs[0].Status != server.TaskSuccess {
+		t.Errorf("missed save not run once: %+v", runs)
+		return
+	}
+
+	// Restart with countdown
+	restart := time.Date(next.Year(), next.Month(), next.Day()+1, 4, 0, 0, 0, next.Location())
+	scheduler.RunDue(restart)
+	if runs, _ := database.TaskRuns(tasks[2].ID, 10); len(runs) != 1 || runs[0].Status != server.TaskSuccess {
+		t.Errorf("restart failed: %+v", runs)
+		return
+	} else if !sentCommand(proc, "say Server restarting in 5 seconds") || !sentCommand(proc, "say Server restarting in 1 second") {
+		t.Errorf("countdown not broadcast")
+		return
+	} else if newProc := manager.Process(mcServer.ID); newProc == nil || newProc == proc {
+		t.Errorf("server not restarted")
+	}
+}
+
+func TestBackupTask(t *testing.T) {
+	database, err := db.NewSqliteConnection(":memory:")
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	user, err := database.CreateNewUser(&users.User{Username: "schedule"}, &users.Password{Password: "test1234"})
+	if err != nil {
+		t.Errorf("cannot make new user in database: %s", err)
+		return
+	}
+	mcServer, err := database.CreateServer(user, &server.Server{Software: "java", Version: "1.21.4", Owner: user.UserID})
+	if err != nil {
+		t.Errorf("cannot make new server in database: %s", err)
+		return
+	}
+
+	root := t.TempDir()
+	manager := runner.NewManager(filepath.Join(root, "servers"), nil)
+	backups := backup.NewManager(filepath.Join(root, "backups"), database, manager)
+	os.MkdirAll(filepath.Join(manager.Dir(mcServer.ID), "world"), 0755)
+	os.WriteFile(filepath.Join(manager.Dir(mcServer.ID), "world", "level.dat"), []byte("level"), 0644)
+	if err = database.SetBackupConfig(&server.BackupConfig{ServerID: mcServer.ID, KeepLast: 1}); err != nil {
+		t.Errorf("cannot set backup config: %s", err)
+		return
+	}
+	safety, err := backups.CreateSafety(mcServer)
+	if err != nil {
+		t.Errorf("cannot create safety backup: %s", err)
+		return
+	}
+
+	task := &server.Task{ServerID: mcServer.ID, Type: server.TaskBackup, Schedule: "0 * * * *", Enabled: true}
+	if err = Validate(task); err != nil {
+		t.Errorf("invalid task: %s", err)
+		return
+	} else if task, err = database.CreateTask(task); err != nil {
+		t.Errorf("cannot create task: %s", err)
+		return
+	}
+
+	if run, _ := NewScheduler(database, manager, nil).RunTask(context.Background(), task, time.Now()); run == nil || run.Status != server.TaskFailed {
+		t.Errorf("backup task run without backups manager: %+v", run)
+		return
+	}
+
+	scheduler := NewScheduler(database, manager, backups)
+	for range 2 {
+		if run, err := scheduler.RunTask(context.Background(), task, time.Now()); err != nil || run.Status != server.TaskSuccess {
+			t.Errorf("backup task failed: %+v %v", run, err)
+			return
+		}
+	}
+
+	jobs, _ := database.BackupJobs(mcServer.ID, 10)
+	if len(jobs) != 2 || jobs[0].Status != server.BackupJobSuccess || jobs[0].Removed != 1 {
+		t.Errorf("invalid jobs: %+v", jobs)
+		return
+	}
+	list, _ := database.ServerBackups(mcServer.ID)
+	if len(list) != 2 || !slices.ContainsFunc(list, func(backup *server.ServerBackup) bool { return backup.ID == jobs[0].BackupID }) {
+		t.Errorf("retention not applied: %d backups", len(list))
+		return
+	} else if !slices.ContainsFunc(list, func(backup *server.ServerBackup) bool { return backup.ID == safety.ID && backup.Safety }) {
+		t.Errorf("safety backup removed by retention")
+	}
+}
@@ -13,6 +13,7 @@ const (
 	Unknown ServerPermission = iota
 	View
 	Edit
+	Console // Send commands to server console
 )
 
 func (ns ServerPermission) String() string {
@@ -21,6 +22,8 @@ func (ns ServerPermission) String() string {
 		return "view"
 	case Edit:
 		return "edit"
+	case Console:
+		return "console"
 	default:
 		return "unknown"
 	}
@@ -33,6 +36,8 @@ func (ns *ServerPermission) TextUnmarshal(data []byte) error {
 		*ns = View
 	case "edit":
 		*ns = Edit
+	case "console":
+		*ns = Console
 	default:
 		*ns = Unknown
 	}
@@ -1,7 +1,11 @@
 // Server maneger
 package server
 
-import "time"
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+)
 
 // Server info
 type Server struct {
@@ -12,6 +16,12 @@ type Server struct {
 	Software string `json:"software"` // Server software
 	Version  string `json:"version"`  // Server version
 
+	Port   int `json:"port"`    // Server IPv4 port, 0 if not allocated
+	PortV6 int `json:"port_v6"` // Server IPv6 port, only to bedrock
+
+	RCONPort     int    `json:"rcon_port,omitempty"` // Java RCON port
+	RCONPassword string `json:"-"`                   // Java RCON password encrypted with instance key
+
 	CreateAt time.Time `json:"create_at"` // Date of creation
 	UpdateAt time.Time `json:"update_at"` // Date to update any row in database
 }
@@ -26,12 +36,54 @@ type ServerFriends struct {
 
 // Server backup
 type ServerBackup struct {
-	ID       int64     `json:"id"`        // Backup ID
-	ServerID int64     `json:"server_id"` // Server reference, foregin key
-	UUID     string    `json:"uuid"`      // Backup UUID
-	Software string    `json:"software"`  // Server software backuped
-	Version  string    `json:"version"`   // Server version
-	CreateAt time.Time `json:"create_at"` // Date of creation
+	ID          int64     `json:"id"`          // Backup ID
+	ServerID    int64     `json:"server_id"`   // Server reference, foregin key
+	UUID        string    `json:"uuid"`        // Backup UUID
+	Software    string    `json:"software"`    // Server software backuped
+	Version     string    `json:"version"`     // Server version
+	Storage     string    `json:"storage"`     // Storage backend name with backup archive
+	Encrypted   bool      `json:"encrypted"`   // Archive is encrypted with instance key
+	Incremental bool      `json:"incremental"` // Backup is manifest to chunks in chunk store
+	Safety      bool      `json:"safety"`      // Taken before restore, upgrade or world change, not removed by retention
+	CreateAt    time.Time `json:"create_at"`   // Date of creation
+}
+
+// Server backups config
+type BackupConfig struct {
+	ServerID    int64  `json:"server_id"`   // Server reference, foregin key
+	Storage     string `json:"storage"`     // Storage backend name, empty to use global backend
+	Incremental bool   `json:"incremental"` // Save only changed chunks
+	KeepLast    int    `json:"keep_last"`   // Keep last backups
+	KeepDaily   int    `json:"keep_daily"`  // Keep newest backup of each day in last days
+	KeepWeekly  int    `json:"keep_weekly"` // Keep newest backup of each week in last weeks
+}
+
+// Backup job status
+type BackupJobStatus string
+
+const (
+	BackupJobSuccess BackupJobStatus = "success"
+	BackupJobFailed  BackupJobStatus = "failed"
+)
+
+// Scheduled backup run
+type BackupJob struct {
+	ID       int64           `json:"id"`        // Job ID
+	ServerID int64           `json:"server_id"` // Server reference, foregin key
+	BackupID int64           `json:"backup_id"` // Backup created, 0 if failed
+	Status   BackupJobStatus `json:"status"`    // Job result
+	Error    string          `json:"error"`     // Error message if failed
+	Removed  int             `json:"removed"`   // Backups removed by retention policy
+	StartAt  time.Time       `json:"start_at"`  // Job start
+	EndAt    time.Time       `json:"end_at"`    // Job end
+}
+
+// Backup chunk in content-addressed store
+type BackupChunk struct {
+	Storage string `json:"storage"` // Storage backend name
+	Hash    string `json:"hash"`    // Chunk ID
+	Size    int64  `json:"size"`    // Chunk size in storage
+	Refs    int64  `json:"refs"`    // Backups using chunk
 }
 
 // Runner info
@@ -41,3 +93,85 @@ type ServerRunner struct {
 	Local  bool  `json:"local"`   // Runner is to the specifiq user
 	UserID int64 `json:"user_id"` // user id if is local runner
 }
+
+// Server logs retention policy
+type LogRetention struct {
+	ServerID int64         `json:"server_id"` // Server reference, foregin key
+	MaxAge   time.Duration `json:"max_age"`   // Remove runs older than this, 0 to keep all
+	MaxRuns  int           `json:"max_runs"`  // Keep only last runs, 0 to keep all
+}
+
+type logRetentionJSON struct {
+	ServerID int64 `json:"server_id"`
+	MaxAge   any   `json:"max_age"`
+	MaxRuns  int   `json:"max_runs"`
+}
+
+// Encode max_age in seconds
+func (policy LogRetention) MarshalJSON() ([]byte, error) {
+	return json.Marshal(logRetentionJSON{policy.ServerID, int64(policy.MaxAge / time.Second), policy.MaxRuns})
+}
+
+// Decode max_age from seconds or duration string, like "72h"
+func (policy *LogRetention) UnmarshalJSON(data []byte) error {
+	var value logRetentionJSON
+	if err := json.Unmarshal(data, &value); err != nil {
+		return err
+	}
+
+	policy.ServerID, policy.MaxRuns, policy.MaxAge = value.ServerID, value.MaxRuns, 0
+	switch age := value.MaxAge.(type) {
+	case nil:
+	case float64:
+		policy.MaxAge = time.Duration(age * float64(time.Second))
+	case string:
+		duration, err := time.ParseDuration(age)
+		if err != nil {
+			return fmt.Errorf("invalid max_age: %s", err)
+		}
+		policy.MaxAge = duration
+	default:
+		return fmt.Errorf("invalid max_age, require seconds or duration string")
+	}
+	return nil
+}
+
+// Player seen in server
+type Player struct {
+	ID          int64     `json:"id"`          // Player ID
+	ServerID    int64     `json:"server_id"`   // Server reference, foregin key
+	Username    string    `json:"username"`    // Player username
+	XUID        string    `json:"xuid"`        // Bedrock Xbox user ID
+	UUID        string    `json:"uuid"`        // Java player UUID
+	Banned      bool      `json:"banned"`      // Player is banned
+	Operator    bool      `json:"operator"`    // Player is operator
+	Whitelisted bool      `json:"whitelisted"` // Player in whitelist/allowlist
+	FirstSeen   time.Time `json:"first_seen"`  // First join
+	LastSeen    time.Time `json:"last_seen"`   // Last join or leave
+}
+
+// Player action
+type PlayerAction string
+
+const (
+	PlayerJoin        PlayerAction = "join"
+	PlayerLeave       PlayerAction = "leave"
+	PlayerKick        PlayerAction = "kick"
+	PlayerBan         PlayerAction = "ban"
+	PlayerPardon      PlayerAction = "pardon"
+	PlayerOp          PlayerAction = "op"
+	PlayerDeop        PlayerAction = "deop"
+	PlayerWhitelist   PlayerAction = "whitelist"
+	PlayerUnwhitelist PlayerAction = "unwhitelist"
+)
+
+// Player history entry
+type PlayerHistory struct {
+	ID       int64        `json:"id"`        // History ID
+	ServerID int64        `json:"server_id"` // Server reference, foregin key
+	Username string       `json:"username"`  // Player username
+	Action   PlayerAction `json:"action"`    // Action
+	Reason   string       `json:"reason"`    // Reason to action, kick or ban
+	UserID   int64        `json:"user_id"`   // User make action, 0 if action is from server
+	CreateAt time.Time    `json:"create_at"` // Date of action
+}
@@ -0,0 +1,58 @@
+package server
+
+import "time"
+
+// Scheduled task action
+type TaskType string
+
+const (
+	TaskCommand TaskType = "command" // Send console commands
+	TaskSave    TaskType = "save"    // Flush world to disk
+	TaskRestart TaskType = "restart" // Restart server with countdown
+	TaskStop    TaskType = "stop"    // Stop server with countdown
+	TaskStart   TaskType = "start"   // Start server if stopped
+	TaskBackup  TaskType = "backup"  // Create backup and apply retention policy
+)
+
+// What to do when task run time is missed, like panel stopped
+type MissedPolicy string
+
+const (
+	MissedSkip    MissedPolicy = "skip"     // Wait to next time
+	MissedRunOnce MissedPolicy = "run_once" // Run one time as soon possible
+)
+
+// Task run status
+type TaskStatus string
+
+const (
+	TaskSuccess TaskStatus = "success"
+	TaskFailed  TaskStatus = "failed"
+	TaskSkipped TaskStatus = "skipped"
+)
+
+// Server scheduled task
+type Task struct {
+	ID        int64        `json:"id"`        // Task ID
+	ServerID  int64        `json:"server_id"` // Server reference, foregin key
+	Name      string       `json:"name"`      // Task name
+	Type      TaskType     `json:"type"`      // Action
+	Schedule  string       `json:"schedule"`  // Cron expression
+	Command   string       `json:"command"`   // Commands to command task, one per line
+	Countdown int          `json:"countdown"` // Seconds to warn players before restart or stop
+	Missed    MissedPolicy `json:"missed"`    // Missed run policy
+	Enabled   bool         `json:"enabled"`   // Task is enabled
+	LastRun   time.Time    `json:"last_run"`  // Last run or skip, zero if never run
+	CreateAt  time.Time    `json:"create_at"` // Date of creation
+}
+
+// Task run history
+type TaskRun struct {
+	ID       int64      `json:"id"`        // Run ID
+	TaskID   int64      `json:"task_id"`   // Task reference, foregin key
+	ServerID int64      `json:"server_id"` // Server reference, foregin key
+	Status   TaskStatus `json:"status"`    // Run result
+	Error    string     `json:"error"`     // Error message if failed or skip reason
+	StartAt  time.Time  `json:"start_at"`  // Run start
+	EndAt    time.Time  `json:"end_at"`    // Run end
+}
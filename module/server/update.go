@@ -0,0 +1,26 @@
+package server
+
+import "time"
+
+// Server update channel
+type UpdateChannel string
+
+const (
+	UpdatePinned  UpdateChannel = "pinned"         // Never update
+	UpdateRelease UpdateChannel = "latest-release" // Follow latest release
+	UpdatePreview UpdateChannel = "latest-preview" // Follow latest preview
+)
+
+// Server update policy, servers created with "latest" version follow latest release by default
+type UpdatePolicy struct {
+	ServerID    int64         `json:"server_id"`    // Server reference, foregin key
+	Channel     UpdateChannel `json:"channel"`      // Update channel
+	AutoUpgrade bool          `json:"auto_upgrade"` // Upgrade in maintenance window, if false only notify
+	WindowStart string        `json:"window_start"` // Maintenance window start "HH:MM", empty to any time
+	WindowEnd   string        `json:"window_end"`   // Maintenance window end "HH:MM"
+	MaxPlayers  int           `json:"max_players"`  // Upgrade only if online players less or equal
+	Available   string        `json:"available"`    // New version found in channel, empty if updated
+	Failed      string        `json:"failed"`       // Version failed to upgrade, not upgraded automatically again
+	LastError   string        `json:"last_error"`   // Last check or upgrade error
+	CheckedAt   time.Time     `json:"checked_at"`   // Last check, zero if never checked
+}
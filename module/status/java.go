@@ -0,0 +1,205 @@
+package status
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Handshake protocol version, -1 is accepted by all servers to status
+const StatusProtocol = -1
+
+var ErrInvalidStatus error = errors.New("invalid server list ping response")
+
+// Max packet size accepted, status JSON with favicon is bellow it
+const maxPacket = 1 << 21
+
+func appendVarInt(buff []byte, value int32) []byte {
+	number := uint32(value)
+	for number >= 0x80 {
+		buff = append(buff, byte(number)|0x80)
+		number >>= 7
+	}
+	return append(buff, byte(number))
+}
+
+func readVarInt(r io.ByteReader) (int32, error) {
+	var value uint32
+	for shift := 0; shift < 35; shift += 7 {
+		b, err := r.ReadByte()
+		if err != nil {
+			return 0, err
+		}
+		value |= uint32(b&0x7f) << shift
+		if b&0x80 == 0 {
+			return int32(value), nil
+		}
+	}
+	return 0, fmt.Errorf("%w: varint too big", ErrInvalidStatus)
+}
+
+// Packet with length prefix
+func framePacket(id int32, data []byte) []byte {
+	payload := appendVarInt(nil, id)
+	payload = append(payload, data...)
+	return append(appendVarInt(nil, int32(len(payload))), payload...)
+}
+
+func readPacket(r *bufio.Reader) (int32, []byte, error) {
+	size, err := readVarInt(r)
+	if err != nil {
+		return 0, nil, err
+	} else if size <= 0 || size > maxPacket {
+		return 0, nil, fmt.Errorf("%w: packet size %d", ErrInvalidStatus, size)
+	}
+	packet := make([]byte, size)
+	if _, err = io.ReadFull(r, packet); err != nil {
+		return 0, nil, err
+	}
+	reader := bytes.NewReader(packet)
+	id, err := readVarInt(reader)
+	if err != nil {
+		return 0, nil, err
+	}
+	return id, packet[len(packet)-reader.Len():], nil
+}
+
+// Text component, description can be string or component with extra components
+type chatComponent struct {
+	Text  string          `json:"text"`
+	Extra []chatComponent `json:"extra"`
+}
+
+func (component *chatComponent) UnmarshalJSON(data []byte) error {
+	var text string
+	if err := json.Unmarshal(data, &text); err == nil {
+		*component = chatComponent{Text: text}
+		return nil
+	}
+	type plain chatComponent
+	return json.Unmarshal(data, (*plain)(component))
+}
+
+func (component chatComponent) String() string {
+	var text strings.Builder
+	text.WriteString(component.Text)
+	for _, extra := range component.Extra {
+		text.WriteString(extra.String())
+	}
+	return text.String()
+}
+
+// Remove legacy "§" formatting codes
+func StripFormatting(text string) string {
+	var out strings.Builder
+	runes := []rune(text)
+	for index := 0; index < len(runes); index++ {
+		if runes[index] == '§' {
+			index++ // Skip code
+			continue
+		}
+		out.WriteRune(runes[index])
+	}
+	return out.String()
+}
+
+// Status response JSON
+type javaStatus struct {
+	Version struct {
+		Name     string `json:"name"`
+		Protocol int    `json:"protocol"`
+	} `json:"version"`
+	Players struct {
+		Max    int `json:"max"`
+		Online int `json:"online"`
+		Sample []struct {
+			Name string `json:"name"`
+			ID   string `json:"id"`
+		} `json:"sample"`
+	} `json:"players"`
+	Description chatComponent `json:"description"`
+}
+
+// Get Java server status with Server List Ping, handshake with status state and status request
+func PingJava(ctx context.Context, address string) (*Status, error) {
+	host, portText, err := net.SplitHostPort(address)
+	if err != nil {
+		return nil, err
+	}
+	port, err := strconv.ParseUint(portText, 10, 16)
+	if err != nil {
+		return nil, fmt.Errorf("invalid port: %s", err)
+	}
+
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(ctx, "tcp", address)
+	if err != nil {
+		return nil, fmt.Errorf("cannot connect to server: %s", err)
+	}
+	defer conn.Close()
+	if deadline, ok := ctx.Deadline(); ok {
+		conn.SetDeadline(deadline)
+	} else {
+		conn.SetDeadline(time.Now().Add(Timeout))
+	}
+	stop := context.AfterFunc(ctx, func() { conn.SetDeadline(time.Now()) })
+	defer stop()
+
+	// Handshake: protocol, host, port and next state 1 (status)
+	handshake := appendVarInt(nil, StatusProtocol)
+	handshake = appendVarInt(handshake, int32(len(host)))
+	handshake = append(handshake, host...)
+	handshake = binary.BigEndian.AppendUint16(handshake, uint16(port))
+	handshake = appendVarInt(handshake, 1)
+
+	start := time.Now()
+	if _, err = conn.Write(append(framePacket(0x00, handshake), framePacket(0x00, nil)...)); err != nil {
+		return nil, fmt.Errorf("cannot send status request: %s", err)
+	}
+
+	reader := bufio.NewReader(conn)
+	id, packet, err := readPacket(reader)
+	if err != nil {
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		return nil, fmt.Errorf("cannot read status response: %s", err)
+	} else if id != 0x00 {
+		return nil, fmt.Errorf("%w: packet id %d", ErrInvalidStatus, id)
+	}
+	latency := time.Since(start)
+
+	body := bytes.NewReader(packet)
+	size, err := readVarInt(body)
+	if err != nil || int(size) > body.Len() || size < 0 {
+		return nil, fmt.Errorf("%w: invalid json size", ErrInvalidStatus)
+	}
+	var response javaStatus
+	if err = json.Unmarshal(packet[len(packet)-body.Len():][:size], &response); err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrInvalidStatus, err)
+	}
+
+	status := &Status{
+		Online:     true,
+		Edition:    "java",
+		MOTD:       StripFormatting(response.Description.String()),
+		Version:    response.Version.Name,
+		Protocol:   response.Version.Protocol,
+		Players:    response.Players.Online,
+		MaxPlayers: response.Players.Max,
+		Latency:    latency,
+	}
+	for _, player := range response.Players.Sample {
+		status.Sample = append(status.Sample, player.Name)
+	}
+	return status, nil
+}
@@ -0,0 +1,171 @@
+package status
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// GameSpy4 query packets types
+const (
+	QueryHandshake byte = 0x09
+	QueryStat      byte = 0x00
+)
+
+var (
+	queryMagic = []byte{0xfe, 0xfd}
+
+	ErrInvalidQuery error = errors.New("invalid query response")
+)
+
+// Query full stat
+type Query struct {
+	MOTD       string            `json:"motd"`
+	GameType   string            `json:"game_type"`
+	Version    string            `json:"version"`
+	Software   string            `json:"software,omitempty"` // Server software from plugins, "Paper on Bukkit 1.21.4"
+	Plugins    []string          `json:"plugins,omitempty"`
+	Map        string            `json:"map"`
+	Players    int               `json:"players"`
+	MaxPlayers int               `json:"max_players"`
+	PlayerList []string          `json:"player_list"`
+	Values     map[string]string `json:"values"` // All key values from server
+}
+
+// Send query packet and wait response with same type and session, resend until deadline
+func queryRequest(ctx context.Context, conn net.Conn, deadline time.Time, packet []byte) ([]byte, error) {
+	buff := make([]byte, 64<<10) // Full stat with many players or plugins can be bigger than MTU, read max UDP payload
+	for {
+		if _, err := conn.Write(packet); err != nil {
+			return nil, fmt.Errorf("cannot send query: %s", err)
+		}
+		if resend := time.Now().Add(ResendInterval); resend.Before(deadline) {
+			conn.SetReadDeadline(resend)
+		} else {
+			conn.SetReadDeadline(deadline)
+		}
+
+		for {
+			n, err := conn.Read(buff)
+			if err != nil {
+				var netErr net.Error
+				if errors.As(err, &netErr) && netErr.Timeout() && ctx.Err() == nil && time.Now().Before(deadline) {
+					break // Resend
+				} else if ctx.Err() != nil {
+					return nil, ctx.Err()
+				}
+				return nil, fmt.Errorf("cannot read query response: %s", err)
+			} else if n < 5 || buff[0] != packet[2] || !bytes.Equal(buff[1:5], packet[3:7]) {
+				continue
+			}
+			return bytes.Clone(buff[5:n]), nil
+		}
+	}
+}
+
+// Split null terminated strings
+func cString(data []byte) (string, []byte, bool) {
+	index := bytes.IndexByte(data, 0)
+	if index == -1 {
+		return "", nil, false
+	}
+	return string(data[:index]), data[index+1:], true
+}
+
+// Query Java server full stat with GameSpy4 protocol, server require "enable-query=true"
+func QueryJava(ctx context.Context, address string) (*Query, error) {
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(ctx, "udp", address)
+	if err != nil {
+		return nil, fmt.Errorf("cannot dial server: %s", err)
+	}
+	defer conn.Close()
+
+	deadline, ok := ctx.Deadline()
+	if !ok {
+		deadline = time.Now().Add(Timeout)
+	}
+	stop := context.AfterFunc(ctx, func() { conn.SetDeadline(time.Now()) })
+	defer stop()
+
+	var session [4]byte
+	rand.Read(session[:])
+	binary.BigEndian.PutUint32(session[:], binary.BigEndian.Uint32(session[:])&0x0f0f0f0f)
+
+	// Challenge token as null terminated number
+	response, err := queryRequest(ctx, conn, deadline, append(append(bytes.Clone(queryMagic), QueryHandshake), session[:]...))
+	if err != nil {
+		return nil, err
+	}
+	tokenText, _, ok := cString(response)
+	if !ok {
+		return nil, fmt.Errorf("%w: invalid challenge", ErrInvalidQuery)
+	}
+	token, err := strconv.ParseInt(tokenText, 10, 32)
+	if err != nil {
+		return nil, fmt.Errorf("%w: invalid challenge: %s", ErrInvalidQuery, err)
+	}
+
+	// Full stat request have 4 bytes padding
+	packet := append(append(bytes.Clone(queryMagic), QueryStat), session[:]...)
+	packet = binary.BigEndian.AppendUint32(packet, uint32(token))
+	if response, err = queryRequest(ctx, conn, deadline, append(packet, 0, 0, 0, 0)); err != nil {
+		return nil, err
+	}
+	return parseFullStat(response)
+}
+
+// Parse full stat: "splitnum\x00\x80\x00", key values, "\x01player_\x00\x00" and players names
+func parseFullStat(data []byte) (*Query, error) {
+	data, ok := bytes.CutPrefix(data, []byte("splitnum\x00\x80\x00"))
+	if !ok {
+		return nil, fmt.Errorf("%w: invalid padding", ErrInvalidQuery)
+	}
+
+	query := &Query{Values: map[string]string{}, PlayerList: []string{}}
+	for {
+		var key, value string
+		if key, data, ok = cString(data); !ok {
+			return nil, fmt.Errorf("%w: invalid key values", ErrInvalidQuery)
+		} else if key == "" {
+			break
+		} else if value, data, ok = cString(data); !ok {
+			return nil, fmt.Errorf("%w: invalid key values", ErrInvalidQuery)
+		}
+		query.Values[key] = value
+	}
+	if data, ok = bytes.CutPrefix(data, []byte("\x01player_\x00\x00")); !ok {
+		return nil, fmt.Errorf("%w: invalid players padding", ErrInvalidQuery)
+	}
+	for {
+		var name string
+		if name, data, ok = cString(data); !ok || name == "" {
+			break
+		}
+		query.PlayerList = append(query.PlayerList, name)
+	}
+
+	query.MOTD = StripFormatting(query.Values["hostname"])
+	query.GameType = query.Values["gametype"]
+	query.Version = query.Values["version"]
+	query.Map = query.Values["map"]
+	query.Players, _ = strconv.Atoi(query.Values["numplayers"])
+	query.MaxPlayers, _ = strconv.Atoi(query.Values["maxplayers"])
+
+	// "Paper on Bukkit 1.21.4-R0.1-SNAPSHOT: Essentials 2.20.1; Vault 1.7.3"
+	software, plugins, _ := strings.Cut(query.Values["plugins"], ":")
+	query.Software = strings.TrimSpace(software)
+	for plugin := range strings.SplitSeq(plugins, ";") {
+		if plugin = strings.TrimSpace(plugin); plugin != "" {
+			query.Plugins = append(query.Plugins, plugin)
+		}
+	}
+	return query, nil
+}
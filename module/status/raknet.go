@@ -0,0 +1,125 @@
+package status
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// RakNet packets ids
+const (
+	UnconnectedPing byte = 0x01
+	UnconnectedPong byte = 0x1c
+)
+
+var (
+	// RakNet offline message magic
+	Magic = []byte{0x00, 0xff, 0xff, 0x00, 0xfe, 0xfe, 0xfe, 0xfe, 0xfd, 0xfd, 0xfd, 0xfd, 0x12, 0x34, 0x56, 0x78}
+
+	ErrInvalidPong error = errors.New("invalid unconnected pong")
+)
+
+// Unconnected ping packet with client time in milliseconds
+func pingPacket(clientTime int64, guid uint64) []byte {
+	packet := make([]byte, 0, 33)
+	packet = append(packet, UnconnectedPing)
+	packet = binary.BigEndian.AppendUint64(packet, uint64(clientTime))
+	packet = append(packet, Magic...)
+	return binary.BigEndian.AppendUint64(packet, guid)
+}
+
+// Parse unconnected pong, server id string is "MCPE;motd;protocol;version;online;max;guid;level;gamemode;gamemode id;port;portv6;"
+func parsePong(packet []byte) (*Status, error) {
+	if len(packet) < 35 || packet[0] != UnconnectedPong || !bytes.Equal(packet[17:33], Magic) {
+		return nil, ErrInvalidPong
+	}
+	size := int(binary.BigEndian.Uint16(packet[33:35]))
+	if len(packet) < 35+size {
+		return nil, ErrInvalidPong
+	}
+
+	fields := strings.Split(string(packet[35:35+size]), ";")
+	if len(fields) < 6 || (fields[0] != "MCPE" && fields[0] != "MCEE") {
+		return nil, fmt.Errorf("%w: %q", ErrInvalidPong, packet[35:35+size])
+	}
+	field := func(index int) string {
+		if index < len(fields) {
+			return fields[index]
+		}
+		return ""
+	}
+
+	status := &Status{
+		Online:   true,
+		Edition:  field(0),
+		MOTD:     field(1),
+		Version:  field(3),
+		Level:    field(7),
+		GameMode: strings.ToLower(field(8)),
+	}
+	status.Protocol, _ = strconv.Atoi(field(2))
+	status.Players, _ = strconv.Atoi(field(4))
+	status.MaxPlayers, _ = strconv.Atoi(field(5))
+	return status, nil
+}
+
+// Send RakNet unconnected ping to Bedrock server and wait pong, ping is resent every [ResendInterval] until [Timeout] or context done
+func PingBedrock(ctx context.Context, address string) (*Status, error) {
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(ctx, "udp", address)
+	if err != nil {
+		return nil, fmt.Errorf("cannot dial server: %s", err)
+	}
+	defer conn.Close()
+
+	deadline, ok := ctx.Deadline()
+	if !ok {
+		deadline = time.Now().Add(Timeout)
+	}
+	stop := context.AfterFunc(ctx, func() { conn.SetDeadline(time.Now()) })
+	defer stop()
+
+	var guid [8]byte
+	rand.Read(guid[:])
+	buff := make([]byte, 1500)
+	for {
+		start := time.Now()
+		if _, err := conn.Write(pingPacket(start.UnixMilli(), binary.BigEndian.Uint64(guid[:]))); err != nil {
+			return nil, fmt.Errorf("cannot send ping: %s", err)
+		}
+		if resend := start.Add(ResendInterval); resend.Before(deadline) {
+			conn.SetReadDeadline(resend)
+		} else {
+			conn.SetReadDeadline(deadline)
+		}
+
+		for {
+			n, err := conn.Read(buff)
+			if err != nil {
+				var netErr net.Error
+				if errors.As(err, &netErr) && netErr.Timeout() && ctx.Err() == nil && time.Now().Before(deadline) {
+					break // Resend ping
+				} else if ctx.Err() != nil {
+					return nil, ctx.Err()
+				}
+				return nil, fmt.Errorf("cannot read pong: %s", err)
+			} else if n == 0 || buff[0] != UnconnectedPong {
+				continue
+			}
+
+			status, err := parsePong(buff[:n])
+			if err != nil {
+				return nil, err
+			}
+			status.Latency = time.Since(start)
+			return status, nil
+		}
+	}
+}
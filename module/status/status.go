@@ -0,0 +1,202 @@
+// Servers status from network protocols, Bedrock RakNet ping and Java Server List Ping with Query, independent of server logs
+package status
+
+import (
+	"context"
+	"net"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"sirherobrine23.com.br/go-bds/bds/module/properties"
+	"sirherobrine23.com.br/go-bds/bds/module/runner"
+	"sirherobrine23.com.br/go-bds/bds/module/server"
+)
+
+var (
+	Timeout        = 5 * time.Second        // Default ping timeout
+	ResendInterval = 500 * time.Millisecond // Resend UDP ping if not responded
+)
+
+// Server status, same to all softwares
+type Status struct {
+	Online     bool          `json:"online"`              // Server responded
+	Edition    string        `json:"edition"`             // MCPE, MCEE or java
+	MOTD       string        `json:"motd"`                // Server message of the day
+	Level      string        `json:"level,omitempty"`     // World name
+	Version    string        `json:"version"`             // Game version reported by server
+	Protocol   int           `json:"protocol"`            // Protocol version
+	Players    int           `json:"players"`             // Online players
+	MaxPlayers int           `json:"max_players"`         // Max players
+	GameMode   string        `json:"game_mode,omitempty"` // Default game mode
+	Latency    time.Duration `json:"latency"`             // Ping response time
+	Sample     []string      `json:"sample,omitempty"`    // Online players names, Java only
+	Software   string        `json:"software,omitempty"`  // Server software from Java query
+	Plugins    []string      `json:"plugins,omitempty"`   // Plugins from Java query
+}
+
+// Last status of running server
+type Health struct {
+	*Status
+	Healthy  bool      `json:"healthy"`         // Server responding to pings
+	Failures int       `json:"failures"`        // Pings failed in sequence
+	Error    string    `json:"error,omitempty"` // Last ping error
+	CheckAt  time.Time `json:"check_at"`        // Last ping
+}
+
+// Ping server in host by software protocol, Java servers with "enable-query=true" are also queried to get all players and plugins
+func Ping(ctx context.Context, srv *server.Server, host, dir string) (*Status, error) {
+	address := Address(srv, host, dir)
+	if strings.EqualFold(srv.Software, "bedrock") {
+		return PingBedrock(ctx, address)
+	}
+
+	status, err := PingJava(ctx, address)
+	if err != nil {
+		return nil, err
+	}
+	file, err := properties.Open(filepath.Join(dir, properties.FileName))
+	if err != nil {
+		return status, nil
+	}
+	status.GameMode, _ = file.Get("gamemode")
+	status.Level, _ = file.Get("level-name")
+	if enabled, _ := file.Get("enable-query"); enabled != "true" {
+		return status, nil
+	}
+
+	// Query port default to server port
+	host, port, _ := net.SplitHostPort(address)
+	if value, ok := file.Get("query.port"); ok && value != "" {
+		port = value
+	}
+	query, err := QueryJava(ctx, net.JoinHostPort(host, port))
+	if err != nil {
+		return status, nil // Keep status from ping
+	}
+	status.Sample, status.Software, status.Plugins = query.PlayerList, query.Software, query.Plugins
+	if query.Map != "" {
+		status.Level = query.Map
+	}
+	return status, nil
+}
+
+// Server address in host, port from server record or server.properties. Empty host is [runner.DefaultHost]
+func Address(srv *server.Server, host, dir string) string {
+	if host == "" {
+		host = runner.DefaultHost
+	}
+	port := srv.Port
+	if port == 0 {
+		if file, err := properties.Open(filepath.Join(dir, properties.FileName)); err == nil {
+			if value, ok := file.Get("server-port"); ok {
+				port, _ = strconv.Atoi(value)
+			}
+		}
+	}
+	if port == 0 {
+		port = 19132
+		if !strings.EqualFold(srv.Software, "bedrock") {
+			port = 25565
+		}
+	}
+	return net.JoinHostPort(host, strconv.Itoa(port))
+}
+
+// Ping running servers periodically and keep last status
+type Poller struct {
+	Interval     time.Duration // Ping interval
+	Timeout      time.Duration // Ping timeout
+	StartTimeout time.Duration // Failed pings after start are ignored until this time or first response
+	MaxFailures  int           // Pings failed in sequence to mark server as unhealthy
+
+	mu     sync.RWMutex
+	status map[int64]*Health
+}
+
+// Create new poller with default values
+func NewPoller() *Poller {
+	return &Poller{
+		Interval:     30 * time.Second,
+		Timeout:      Timeout,
+		StartTimeout: 2 * time.Minute,
+		MaxFailures:  3,
+		status:       map[int64]*Health{},
+	}
+}
+
+// Last server status, nil if server not running or not pinged yet
+func (poller *Poller) Status(serverID int64) *Health {
+	poller.mu.RLock()
+	defer poller.mu.RUnlock()
+	if health, ok := poller.status[serverID]; ok {
+		value := *health
+		return &value
+	}
+	return nil
+}
+
+// Record ping result
+func (poller *Poller) record(serverID int64, startAt, now time.Time, status *Status, err error) *Health {
+	poller.mu.Lock()
+	defer poller.mu.Unlock()
+	health, ok := poller.status[serverID]
+	if !ok {
+		health = &Health{Healthy: true}
+		poller.status[serverID] = health
+	}
+
+	health.CheckAt = now
+	if err == nil {
+		health.Status, health.Healthy, health.Failures, health.Error = status, true, 0, ""
+		return health
+	}
+
+	health.Error = err.Error()
+	if health.Status != nil && health.Status.Online {
+		offline := *health.Status // Keep last status to show, copies returned by Status share it
+		offline.Online = false
+		health.Status = &offline
+	}
+	// Server starting
+	if health.Failures == 0 && health.Status == nil && now.Sub(startAt) < poller.StartTimeout {
+		return health
+	}
+	if health.Failures++; health.Failures >= poller.MaxFailures {
+		health.Healthy = false
+	}
+	return health
+}
+
+// Hook to [runner.Manager.OnStart], ping server until process exit
+func (poller *Poller) Attach(proc *runner.Process) {
+	go func() {
+		defer func() {
+			poller.mu.Lock()
+			delete(poller.status, proc.Server.ID)
+			poller.mu.Unlock()
+		}()
+
+		ticker := time.NewTicker(poller.Interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-proc.Done():
+				return
+			case <-ticker.C:
+			}
+
+			ctx, cancel := context.WithTimeout(context.Background(), poller.Timeout)
+			status, err := Ping(ctx, proc.Server, proc.Host, proc.Dir)
+			cancel()
+			select {
+			case <-proc.Done():
+				return
+			default:
+				poller.record(proc.Server.ID, proc.StartAt, time.Now(), status, err)
+			}
+		}
+	}()
+}
@@ -0,0 +1,188 @@
+package status
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/binary"
+	"errors"
+	"net"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+
+	"sirherobrine23.com.br/go-bds/bds/module/properties"
+	"sirherobrine23.com.br/go-bds/bds/module/server"
+)
+
+// Local RakNet server responding to unconnected pings, first pings are dropped
+func raknetResponder(t *testing.T, serverID string, drop int) string {
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Skipf("cannot listen udp: %s", err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	go func() {
+		buff := make([]byte, 1500)
+		for {
+			n, addr, err := conn.ReadFrom(buff)
+			if err != nil {
+				return
+			} else if n != 33 || buff[0] != UnconnectedPing || !bytes.Equal(buff[9:25], Magic) {
+				continue
+			} else if drop > 0 {
+				drop--
+				continue
+			}
+
+			pong := []byte{UnconnectedPong}
+			pong = append(pong, buff[1:9]...)                        // Client time
+			pong = binary.BigEndian.AppendUint64(pong, 0x1122334455) // Server GUID
+			pong = append(pong, Magic...)
+			pong = binary.BigEndian.AppendUint16(pong, uint16(len(serverID)))
+			conn.WriteTo(append(pong, serverID...), addr)
+		}
+	}()
+	return conn.LocalAddr().String()
+}
+
+func TestPingBedrock(t *testing.T) {
+	address := raknetResponder(t, "MCPE;Dedicated Server;766;1.21.50;3;10;12345678;Bedrock level;Survival;1;19132;19133;", 1)
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	status, err := PingBedrock(ctx, address)
+	if err != nil {
+		t.Errorf("cannot ping server: %s", err)
+		return
+	} else if !status.Online || status.MOTD != "Dedicated Server" || status.Version != "1.21.50" || status.Protocol != 766 {
+		t.Errorf("invalid status: %+v", status)
+		return
+	} else if status.Players != 3 || status.MaxPlayers != 10 || status.Level != "Bedrock level" || status.GameMode != "survival" {
+		t.Errorf("invalid players or level: %+v", status)
+		return
+	}
+
+	address = raknetResponder(t, "not raknet", 0)
+	if _, err = PingBedrock(ctx, address); !errors.Is(err, ErrInvalidPong) {
+		t.Errorf("invalid pong accepted: %v", err)
+		return
+	}
+
+	// Server not responding
+	address = raknetResponder(t, "", 1000)
+	ctx, cancel = context.WithTimeout(context.Background(), 200*time.Millisecond)
+	defer cancel()
+	if _, err = PingBedrock(ctx, address); err == nil {
+		t.Errorf("ping without response")
+	}
+}
+
+func TestPollerHealth(t *testing.T) {
+	poller := NewPoller()
+	startAt := time.Now()
+	failed := errors.New("timeout")
+
+	// Starting server is not unhealthy
+	if health := poller.record(1, startAt, startAt.Add(time.Second), nil, failed); !health.Healthy || health.Failures != 0 {
+		t.Errorf("starting server marked as unhealthy: %+v", health)
+		return
+	}
+	poller.record(1, startAt, startAt.Add(2*time.Second), &Status{Online: true, Players: 2}, nil)
+	for index := range poller.MaxFailures {
+		poller.record(1, startAt, startAt.Add(time.Duration(3+index)*time.Second), nil, failed)
+	}
+	if health := poller.Status(1); health.Healthy || health.Online || health.Players != 2 || health.Error != "timeout" {
+		t.Errorf("server not marked as unhealthy: %+v", health)
+	}
+}
+
+// Local Java server responding to status request on TCP and full stat query on UDP in same port
+func javaResponder(t *testing.T, response string, players []string) int {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Skipf("cannot listen tcp: %s", err)
+	}
+	t.Cleanup(func() { ln.Close() })
+	port := ln.Addr().(*net.TCPAddr).Port
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			reader := bufio.NewReader(conn)
+			if id, _, err := readPacket(reader); err != nil || id != 0x00 { // Handshake
+				conn.Close()
+				continue
+			} else if id, _, err = readPacket(reader); err != nil || id != 0x00 { // Status request
+				conn.Close()
+				continue
+			}
+			conn.Write(framePacket(0x00, append(appendVarInt(nil, int32(len(response))), response...)))
+			conn.Close()
+		}
+	}()
+
+	udp, err := net.ListenPacket("udp", ln.Addr().String())
+	if err != nil {
+		t.Skipf("cannot listen udp: %s", err)
+	}
+	t.Cleanup(func() { udp.Close() })
+	go func() {
+		buff := make([]byte, 1500)
+		for {
+			n, addr, err := udp.ReadFrom(buff)
+			if err != nil {
+				return
+			} else if n < 7 || !bytes.Equal(buff[:2], queryMagic) {
+				continue
+			}
+			response := append([]byte{buff[2]}, buff[3:7]...)
+			switch {
+			case buff[2] == QueryHandshake:
+				response = append(response, "9513307\x00"...)
+			case buff[2] == QueryStat && n == 15 && binary.BigEndian.Uint32(buff[7:11]) == 9513307:
+				response = append(response, "splitnum\x00\x80\x00"...)
+				for _, value := range []string{"hostname", "§aA Minecraft Server", "gametype", "SMP", "version", "1.21.4", "plugins", "Paper on Bukkit 1.21.4-R0.1-SNAPSHOT: Essentials 2.20.1; Vault 1.7.3", "map", "world", "numplayers", "2", "maxplayers", "20"} {
+					response = append(append(response, value...), 0)
+				}
+				response = append(response, "\x00\x01player_\x00\x00"...)
+				for _, name := range players {
+					response = append(append(response, name...), 0)
+				}
+				response = append(response, 0)
+			default:
+				continue
+			}
+			udp.WriteTo(response, addr)
+		}
+	}()
+	return port
+}
+
+func TestPingJava(t *testing.T) {
+	port := javaResponder(t, `{"version":{"name":"Paper 1.21.4","protocol":769},"players":{"max":20,"online":2,"sample":[{"name":"Steve","id":"8667ba71-b85a-4004-af54-457a9734eed7"}]},"description":{"text":"§aA ","extra":[{"text":"Minecraft Server"}]}}`, []string{"Steve", "Alex"})
+	dir := t.TempDir()
+	file := properties.New()
+	file.Set("enable-query", "true")
+	file.Set("gamemode", "survival")
+	file.Save(filepath.Join(dir, properties.FileName))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+	status, err := Ping(ctx, &server.Server{Software: "paper", Port: port}, "", dir)
+	if err != nil {
+		t.Errorf("cannot ping java server: %s", err)
+		return
+	} else if status.MOTD != "A Minecraft Server" || status.Version != "Paper 1.21.4" || status.Protocol != 769 || status.Players != 2 || status.GameMode != "survival" {
+		t.Errorf("invalid status: %+v", status)
+		return
+	} else if !slices.Equal(status.Sample, []string{"Steve", "Alex"}) || status.Level != "world" {
+		t.Errorf("query not merged: %+v", status)
+		return
+	} else if status.Software != "Paper on Bukkit 1.21.4-R0.1-SNAPSHOT" || !slices.Equal(status.Plugins, []string{"Essentials 2.20.1", "Vault 1.7.3"}) {
+		t.Errorf("invalid plugins: %q %q", status.Software, status.Plugins)
+	}
+}
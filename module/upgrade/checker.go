@@ -0,0 +1,184 @@
+package upgrade
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"sirherobrine23.com.br/go-bds/bds/module/mcversion"
+	"sirherobrine23.com.br/go-bds/bds/module/players"
+	"sirherobrine23.com.br/go-bds/bds/module/server"
+	"sirherobrine23.com.br/go-bds/bds/module/users"
+	"sirherobrine23.com.br/go-bds/bds/module/versions"
+)
+
+var (
+	ErrInvalidChannel error = errors.New("invalid update channel, use pinned, latest-release or latest-preview")
+	ErrInvalidWindow  error = errors.New("invalid maintenance window, use HH:MM")
+
+	CheckInterval = time.Minute * 15 // Interval to check new versions
+)
+
+// Check update policy fields
+func ValidatePolicy(policy *server.UpdatePolicy) error {
+	switch policy.Channel {
+	case server.UpdatePinned, server.UpdateRelease, server.UpdatePreview:
+	default:
+		return ErrInvalidChannel
+	}
+	if (policy.WindowStart == "") != (policy.WindowEnd == "") {
+		return ErrInvalidWindow
+	}
+	for _, value := range []string{policy.WindowStart, policy.WindowEnd} {
+		if _, err := parseClock(value); value != "" && err != nil {
+			return ErrInvalidWindow
+		}
+	}
+	if policy.MaxPlayers < 0 {
+		policy.MaxPlayers = 0
+	}
+	return nil
+}
+
+// Minutes from midnight
+func parseClock(value string) (int, error) {
+	clock, err := time.Parse("15:04", value)
+	if err != nil {
+		return 0, err
+	}
+	return clock.Hour()*60 + clock.Minute(), nil
+}
+
+// Check if now is in policy maintenance window, window can cross midnight like "22:00" to "04:00"
+func InWindow(policy *server.UpdatePolicy, now time.Time) bool {
+	start, errStart := parseClock(policy.WindowStart)
+	end, errEnd := parseClock(policy.WindowEnd)
+	if errStart != nil || errEnd != nil || start == end {
+		return true // Any time
+	}
+
+	minute := now.Hour()*60 + now.Minute()
+	if start < end {
+		return minute >= start && minute < end
+	}
+	return minute >= start || minute < end
+}
+
+// Check servers update channels, notify new versions and upgrade in maintenance window
+type Checker struct {
+	Upgrader *Upgrader
+	Players  *players.Tracker // Online players, if nil servers are upgraded with any player count
+	Location *time.Location   // Maintenance windows location, default is [time.Local]
+
+	OnAvailable []func(srv *server.Server, policy *server.UpdatePolicy) // Called one time to each new version found
+}
+
+// Create new checker, server owner is notified when new version is found
+func NewChecker(upgrader *Upgrader, tracker *players.Tracker) *Checker {
+	checker := &Checker{Upgrader: upgrader, Players: tracker}
+	checker.OnAvailable = append(checker.OnAvailable, checker.notifyOwner)
+	return checker
+}
+
+// Save notification to server owner
+func (checker *Checker) notifyOwner(srv *server.Server, policy *server.UpdatePolicy) {
+	message := fmt.Sprintf("New %s version %s available to server %q", policy.Channel, policy.Available, srv.Name)
+	if policy.AutoUpgrade {
+		message += ", server will be upgraded in maintenance window"
+	}
+	checker.Upgrader.Database.CreateNotification(&users.Notification{UserID: srv.Owner, ServerID: srv.ID, Message: message})
+}
+
+// Check servers every [CheckInterval] until context is done
+func (checker *Checker) Run(ctx context.Context) error {
+	ticker := time.NewTicker(CheckInterval)
+	defer ticker.Stop()
+	for {
+		checker.CheckAll(ctx, time.Now())
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// Check all servers not pinned
+func (checker *Checker) CheckAll(ctx context.Context, now time.Time) {
+	policies, err := checker.Upgrader.Database.UpdatePolicies()
+	if err != nil {
+		return
+	}
+	for _, policy := range policies {
+		checker.Check(ctx, policy, now)
+	}
+}
+
+// Check new version to server policy, upgrade if enabled and in window, policy is saved with result
+func (checker *Checker) Check(ctx context.Context, policy *server.UpdatePolicy, now time.Time) (*Result, error) {
+	if policy.Channel == server.UpdatePinned {
+		return nil, nil
+	}
+	srv, err := checker.Upgrader.Database.Server(policy.ServerID)
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := checker.check(ctx, srv, policy, now)
+	policy.CheckedAt, policy.LastError = now, ""
+	if err != nil {
+		policy.LastError = err.Error()
+	}
+	if err := checker.Upgrader.Database.SetUpdatePolicy(policy); err != nil {
+		return result, fmt.Errorf("cannot save update policy: %s", err)
+	}
+	return result, err
+}
+
+func (checker *Checker) check(ctx context.Context, srv *server.Server, policy *server.UpdatePolicy, now time.Time) (*Result, error) {
+	query := versions.Latest
+	if policy.Channel == server.UpdatePreview {
+		query = versions.Preview
+	}
+	version, err := checker.Upgrader.Versions.Resolve(ctx, srv.Software, query)
+	if err != nil {
+		return nil, err
+	} else if version.Version == srv.Version {
+		policy.Available = ""
+		return nil, nil
+	}
+
+	if policy.Available != version.Version {
+		policy.Available = version.Version
+		for _, fn := range checker.OnAvailable {
+			fn(srv, policy)
+		}
+	}
+
+	// Channel can resolve older version, like release after preview, only notified.
+	// Not numeric versions like snapshots cannot be ordered
+	if cmp, ok := mcversion.Compare(version.Version, srv.Version); ok && cmp <= 0 {
+		return nil, nil
+	}
+
+	location := checker.Location
+	if location == nil {
+		location = time.Local
+	}
+	if !policy.AutoUpgrade || policy.Failed == version.Version || !InWindow(policy, now.In(location)) {
+		return nil, nil
+	} else if checker.Players != nil && len(checker.Players.Online(srv.ID)) > policy.MaxPlayers {
+		return nil, nil // Wait players leave
+	}
+
+	result, err := checker.Upgrader.Upgrade(ctx, srv, version.Version)
+	if err != nil {
+		return nil, err
+	} else if result.RolledBack {
+		policy.Failed = version.Version
+		return result, errors.New(result.Error)
+	}
+	policy.Available = ""
+	return result, nil
+}
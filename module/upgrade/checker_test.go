@@ -0,0 +1,103 @@
+package upgrade
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"sirherobrine23.com.br/go-bds/bds/module/server"
+)
+
+func TestInWindow(t *testing.T) {
+	policy := &server.UpdatePolicy{WindowStart: "22:00", WindowEnd: "04:00"}
+	for hour, expected := range map[int]bool{21: false, 22: true, 0: true, 3: true, 4: false, 12: false} {
+		if InWindow(policy, time.Date(2024, time.June, 12, hour, 0, 0, 0, time.UTC)) != expected {
+			t.Errorf("window at %d:00 expected %v", hour, expected)
+			return
+		}
+	}
+	if !InWindow(&server.UpdatePolicy{}, time.Now()) {
+		t.Errorf("empty window not match any time")
+	}
+}
+
+func TestChecker(t *testing.T) {
+	upgrader, mcServer, err := testUpgrader(t, "latest")
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	database := upgrader.Database
+	checker := NewChecker(upgrader, nil)
+	checker.Location = time.UTC
+	notified := 0
+	checker.OnAvailable = append(checker.OnAvailable, func(srv *server.Server, policy *server.UpdatePolicy) { notified++ })
+
+	// "latest" server follow release and only notify by default
+	ctx, noon := context.Background(), time.Date(2024, time.June, 12, 12, 0, 0, 0, time.UTC)
+	checker.CheckAll(ctx, noon)
+	checker.CheckAll(ctx, noon)
+	policy, _ := database.UpdatePolicy(mcServer.ID)
+	if policy.Channel != server.UpdateRelease || policy.Available != "2.0" || notified != 1 {
+		t.Errorf("new version not notified one time: %+v, %d notifications", policy, notified)
+		return
+	} else if srv, _ := database.Server(mcServer.ID); srv.Version != "latest" {
+		t.Errorf("server upgraded without auto upgrade")
+		return
+	} else if notifications, _ := database.Notifications(mcServer.Owner); len(notifications) != 1 || notifications[0].ServerID != mcServer.ID {
+		t.Errorf("owner not notified: %+v", notifications)
+		return
+	}
+
+	// Upgrade only in maintenance window
+	policy.AutoUpgrade, policy.WindowStart, policy.WindowEnd = true, "03:00", "04:00"
+	if err = database.SetUpdatePolicy(policy); err != nil {
+		t.Errorf("cannot set policy: %s", err)
+		return
+	}
+	checker.CheckAll(ctx, noon)
+	if srv, _ := database.Server(mcServer.ID); srv.Version != "latest" {
+		t.Errorf("server upgraded out of window")
+		return
+	}
+	checker.CheckAll(ctx, noon.Add(-time.Hour*8-time.Minute*30))
+	if srv, _ := database.Server(mcServer.ID); srv.Version != "2.0" {
+		t.Errorf("server not upgraded in window: %s", srv.Version)
+		return
+	} else if policy, _ = database.UpdatePolicy(mcServer.ID); policy.Available != "" || policy.Channel != server.UpdateRelease {
+		t.Errorf("policy not updated after upgrade: %+v", policy)
+		return
+	}
+
+	// Broken preview is rolled back and not tried again
+	policy.Channel = server.UpdatePreview
+	database.SetUpdatePolicy(policy)
+	checker.CheckAll(ctx, noon.Add(-time.Hour*8-time.Minute*30))
+	if policy, _ = database.UpdatePolicy(mcServer.ID); policy.Failed != "3.0" || policy.LastError == "" {
+		t.Errorf("failed upgrade not recorded: %+v", policy)
+		return
+	} else if srv, _ := database.Server(mcServer.ID); srv.Version != "2.0" {
+		t.Errorf("server not rolled back: %s", srv.Version)
+		return
+	}
+	backups, _ := database.ServerBackups(mcServer.ID)
+	checker.CheckAll(ctx, noon.Add(-time.Hour*8-time.Minute*20))
+	if after, _ := database.ServerBackups(mcServer.ID); len(after) != len(backups) {
+		t.Errorf("failed version upgraded again")
+		return
+	}
+
+	// Older version in channel is only notified
+	srv, _ := database.Server(mcServer.ID)
+	srv.Version = "5.0"
+	database.UpdateServer(srv)
+	policy.Channel, notified = server.UpdateRelease, 0
+	database.SetUpdatePolicy(policy)
+	checker.CheckAll(ctx, noon.Add(-time.Hour*8-time.Minute*10))
+	if srv, _ = database.Server(mcServer.ID); srv.Version != "5.0" {
+		t.Errorf("server downgraded to %s", srv.Version)
+		return
+	} else if policy, _ = database.UpdatePolicy(mcServer.ID); policy.Available != "2.0" || notified != 1 {
+		t.Errorf("older version not notified: %+v, %d notifications", policy, notified)
+	}
+}
@@ -0,0 +1,322 @@
+// Upgrade servers in place with backup and automatic rollback
+package upgrade
+
+import (
+	"archive/zip"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"sirherobrine23.com.br/go-bds/bds/module/backup"
+	"sirherobrine23.com.br/go-bds/bds/module/db"
+	"sirherobrine23.com.br/go-bds/bds/module/properties"
+	"sirherobrine23.com.br/go-bds/bds/module/runner"
+	"sirherobrine23.com.br/go-bds/bds/module/server"
+	"sirherobrine23.com.br/go-bds/bds/module/versions"
+)
+
+const JavaFile = "server.jar" // Java servers jar name in server directory
+
+var (
+	ErrUpgradeRunning error = errors.New("upgrade already running")
+	ErrUpToDate       error = errors.New("server already in this version")
+	ErrUnhealthy      error = errors.New("server not started after upgrade")
+
+	StopTimeout    = time.Minute            // Max time to wait server stop before kill
+	StartTimeout   = time.Minute * 5        // Max time to wait server start after upgrade
+	HealthInterval = time.Millisecond * 500 // Interval to check server output
+
+	// Server files never replaced by upgrade if exists
+	Preserved = []string{"worlds", properties.FileName, "allowlist.json", "permissions.json"}
+)
+
+// Upgrade result
+type Result struct {
+	From       string               `json:"from"`        // Version before upgrade
+	To         string               `json:"to"`          // Version installed
+	Backup     *server.ServerBackup `json:"backup"`      // Backup before upgrade, nil if server not have files
+	Restarted  bool                 `json:"restarted"`   // Server started after upgrade
+	RolledBack bool                 `json:"rolled_back"` // Upgrade failed and server restored
+	Error      string               `json:"error,omitempty"`
+}
+
+// Servers upgrader
+type Upgrader struct {
+	Database db.Database
+	Runner   *runner.Manager
+	Backups  *backup.Manager
+	Versions *versions.Manager
+
+	mu      sync.Mutex
+	running map[int64]bool
+}
+
+// Create new upgrader
+func NewUpgrader(database db.Database, backups *backup.Manager, manager *versions.Manager) *Upgrader {
+	return &Upgrader{
+		Database: database,
+		Runner:   backups.Runner,
+		Backups:  backups,
+		Versions: manager,
+		running:  map[int64]bool{},
+	}
+}
+
+func (up *Upgrader) lock(serverID int64) error {
+	up.mu.Lock()
+	defer up.mu.Unlock()
+	if up.running == nil {
+		up.running = map[int64]bool{}
+	}
+	if up.running[serverID] {
+		return ErrUpgradeRunning
+	}
+	up.running[serverID] = true
+	return nil
+}
+
+func (up *Upgrader) unlock(serverID int64) {
+	up.mu.Lock()
+	defer up.mu.Unlock()
+	delete(up.running, serverID)
+}
+
+// Upgrade server to version query, like "latest", "preview" or exact version.
+//
+// Backup server, stop, replace server files keeping worlds and configs, and start to check if server is healthy.
+// If start fail, old files, world backup and version are restored and server started again if was running.
+func (up *Upgrader) Upgrade(ctx context.Context, srv *server.Server, query string) (*Result, error) {
+	if err := up.lock(srv.ID); err != nil {
+		return nil, err
+	}
+	defer up.unlock(srv.ID)
+
+	// Download first, server is not touched if download fail
+	version, file, err := up.Versions.Fetch(ctx, srv.Software, query)
+	if err != nil {
+		return nil, err
+	} else if version.Version == srv.Version {
+		return nil, ErrUpToDate
+	}
+
+	result := &Result{From: srv.Version, To: version.Version}
+	if result.Backup, err = up.Backups.CreateSafety(srv); err != nil && err != backup.ErrNoFiles {
+		return nil, fmt.Errorf("cannot backup server before upgrade: %s", err)
+	}
+
+	wasRunning := up.Runner.Process(srv.ID) != nil
+	if wasRunning {
+		if err := up.Runner.Stop(srv.ID, StopTimeout); err != nil && up.Runner.Process(srv.ID) != nil {
+			return nil, fmt.Errorf("cannot stop server: %s", err)
+		}
+	}
+
+	dir := up.Runner.Dir(srv.ID)
+	swap, err := install(dir, file)
+	if err != nil {
+		if swap != nil {
+			swap.rollback()
+		}
+		if wasRunning {
+			up.Runner.Start(srv)
+		}
+		return nil, fmt.Errorf("cannot install %s %s: %s", srv.Software, version.Version, err)
+	}
+
+	upgraded := *srv
+	upgraded.Version = version.Version
+	if err = up.Database.UpdateServer(&upgraded); err != nil {
+		swap.rollback()
+		if wasRunning {
+			up.Runner.Start(srv)
+		}
+		return nil, fmt.Errorf("cannot update server version: %s", err)
+	}
+
+	if err = up.healthCheck(ctx, &upgraded); err == nil {
+		swap.commit()
+		srv.Version = upgraded.Version
+		// Keep server stopped if was stopped
+		if result.Restarted = wasRunning; !wasRunning {
+			up.Runner.Stop(srv.ID, StopTimeout)
+		}
+		return result, nil
+	}
+
+	// Rollback
+	result.Error, result.RolledBack = err.Error(), true
+	if proc := up.Runner.Process(srv.ID); proc != nil {
+		proc.Kill()
+		<-proc.Done()
+	}
+	swap.rollback()
+	if err := up.Database.UpdateServer(srv); err != nil {
+		return result, fmt.Errorf("upgrade failed and cannot restore server version: %s", err)
+	}
+	if result.Backup != nil {
+		if _, err := up.Backups.Restore(result.Backup, srv, true); err != nil {
+			return result, fmt.Errorf("upgrade failed and cannot restore backup: %s", err)
+		}
+	}
+	if wasRunning {
+		if _, err := up.Runner.Start(srv); err != nil {
+			return result, fmt.Errorf("upgrade rolled back but cannot start server: %s", err)
+		}
+		result.Restarted = true
+	}
+	return result, nil
+}
+
+// Start server and wait ready message in output
+func (up *Upgrader) healthCheck(ctx context.Context, srv *server.Server) error {
+	proc, err := up.Runner.Start(srv)
+	if err != nil {
+		return fmt.Errorf("%w: %s", ErrUnhealthy, err)
+	}
+
+	ready := "Done ("
+	if strings.EqualFold(srv.Software, "bedrock") {
+		ready = "Server started."
+	}
+
+	timeout := time.After(StartTimeout)
+	ticker := time.NewTicker(HealthInterval)
+	defer ticker.Stop()
+	for {
+		if slices.ContainsFunc(proc.Output.Scrollback(), func(line runner.Line) bool {
+			return line.Stream != runner.Stdin && strings.Contains(line.Text, ready)
+		}) {
+			return nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-proc.Done():
+			return fmt.Errorf("%w: process exited", ErrUnhealthy)
+		case <-timeout:
+			return fmt.Errorf("%w: timeout waiting %q", ErrUnhealthy, ready)
+		case <-ticker.C:
+		}
+	}
+}
+
+// Replaced files, old files are kept until commit
+type swap struct {
+	dir       string
+	old       string   // Directory with replaced files
+	installed []string // Paths relative to dir
+}
+
+// Remove old files
+func (sw *swap) commit() { os.RemoveAll(sw.old) }
+
+// Remove installed files and move old files back
+func (sw *swap) rollback() {
+	for _, name := range slices.Backward(sw.installed) {
+		target := filepath.Join(sw.dir, name)
+		os.Remove(target)
+		if _, err := os.Lstat(filepath.Join(sw.old, name)); err == nil {
+			os.Rename(filepath.Join(sw.old, name), target)
+		}
+	}
+	os.RemoveAll(sw.old)
+}
+
+// Extract zip or copy jar to staging directory and swap with server files
+func install(dir, file string) (*swap, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, err
+	}
+	staging, err := os.MkdirTemp(dir, ".upgrade-new-")
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(staging)
+
+	if strings.EqualFold(filepath.Ext(file), ".zip") {
+		zr, err := zip.OpenReader(file)
+		if err != nil {
+			return nil, err
+		}
+		err = backup.Extract(&zr.Reader, staging)
+		zr.Close()
+		if err != nil {
+			return nil, err
+		}
+		// Mojang zips not always have unix mode
+		for _, name := range []string{"bedrock_server", "bedrock_server.exe"} {
+			if _, err := os.Stat(filepath.Join(staging, name)); err == nil {
+				os.Chmod(filepath.Join(staging, name), 0755)
+			}
+		}
+	} else if err = copyFile(file, filepath.Join(staging, JavaFile)); err != nil {
+		return nil, err
+	}
+
+	old, err := os.MkdirTemp(dir, ".upgrade-old-")
+	if err != nil {
+		return nil, err
+	}
+	sw := &swap{dir: dir, old: old}
+
+	// Check before swap, new files in preserved directories would hide others
+	var keep []string
+	for _, name := range Preserved {
+		if _, err := os.Lstat(filepath.Join(dir, name)); err == nil {
+			keep = append(keep, name)
+		}
+	}
+	err = filepath.WalkDir(staging, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil || entry.IsDir() {
+			return err
+		}
+		name, err := filepath.Rel(staging, path)
+		if err != nil {
+			return err
+		} else if top, _, _ := strings.Cut(filepath.ToSlash(name), "/"); slices.Contains(keep, top) {
+			return nil
+		}
+
+		target := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+			return err
+		}
+		if _, err := os.Lstat(target); err == nil {
+			if err := os.MkdirAll(filepath.Dir(filepath.Join(old, name)), 0755); err != nil {
+				return err
+			} else if err := os.Rename(target, filepath.Join(old, name)); err != nil {
+				return err
+			}
+		}
+		sw.installed = append(sw.installed, name)
+		return os.Rename(path, target)
+	})
+	return sw, err
+}
+
+func copyFile(source, target string) error {
+	r, err := os.Open(source)
+	if err != nil {
+		return err
+	}
+	defer r.Close()
+
+	w, err := os.OpenFile(target, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0644)
+	if err != nil {
+		return err
+	}
+	defer w.Close()
+	if _, err = io.Copy(w, r); err != nil {
+		return err
+	}
+	return w.Close()
+}
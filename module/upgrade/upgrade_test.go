@@ -0,0 +1,146 @@
+package upgrade
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"sirherobrine23.com.br/go-bds/bds/module/backup"
+	"sirherobrine23.com.br/go-bds/bds/module/db"
+	"sirherobrine23.com.br/go-bds/bds/module/runner"
+	"sirherobrine23.com.br/go-bds/bds/module/server"
+	"sirherobrine23.com.br/go-bds/bds/module/users"
+	"sirherobrine23.com.br/go-bds/bds/module/versions"
+)
+
+// Fake servers, "jar" is a shell script
+var fakeJars = map[string]string{
+	"1.0": `echo "[10:00:00] [Server thread/INFO]: Done (1.0s)!"
+while read -r line; do
+  case "$line" in
+    "save-all flush") echo "[10:00:00] [Server thread/INFO]: Saved the game" ;;
+    "stop") exit 0 ;;
+  esac
+done
+`,
+	// Convert world and crash
+	"3.0": `echo converted > world/level.dat
+echo "[10:00:00] [main/ERROR]: Failed to start the minecraft server"
+exit 1
+`,
+}
+
+func init() { fakeJars["2.0"] = "# 2.0\n" + fakeJars["1.0"] }
+
+// Versions from fake jars
+type fakeResolver struct{ url string }
+
+func (fake fakeResolver) Versions(ctx context.Context) ([]*versions.Version, error) {
+	return []*versions.Version{
+		{Software: "java", Version: "3.0", Preview: true},
+		{Software: "java", Version: "2.0"},
+		{Software: "java", Version: "1.0"},
+	}, nil
+}
+
+func (fake fakeResolver) Artifact(ctx context.Context, version *versions.Version) (*versions.Artifact, error) {
+	return &versions.Artifact{Name: "server.sh", URL: fake.url + "/" + version.Version}, nil
+}
+
+// Create upgrader with java server in version and fake files
+func testUpgrader(t *testing.T, version string) (*Upgrader, *server.Server, error) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(fakeJars[r.URL.Path[1:]]))
+	}))
+	t.Cleanup(ts.Close)
+
+	database, err := db.NewSqliteConnection(":memory:")
+	if err != nil {
+		return nil, nil, err
+	}
+	user, err := database.CreateNewUser(&users.User{Username: "upgrade"}, &users.Password{Password: "test1234"})
+	if err != nil {
+		return nil, nil, fmt.Errorf("cannot make new user in database: %s", err)
+	}
+	mcServer, err := database.CreateServer(user, &server.Server{Software: "java", Version: version, Owner: user.UserID})
+	if err != nil {
+		return nil, nil, fmt.Errorf("cannot make new server in database: %s", err)
+	}
+
+	root := t.TempDir()
+	manager := runner.NewManager(filepath.Join(root, "servers"), func(srv *server.Server, dir string) (*exec.Cmd, error) {
+		return exec.Command("sh", JavaFile), nil
+	})
+	versionsManager := versions.NewManager(filepath.Join(root, "cache"))
+	versionsManager.Resolvers = map[string]versions.Resolver{"java": fakeResolver{ts.URL}}
+	upgrader := NewUpgrader(database, backup.NewManager(filepath.Join(root, "backups"), database, manager), versionsManager)
+	HealthInterval = time.Millisecond * 10
+
+	dir := manager.Dir(mcServer.ID)
+	os.MkdirAll(filepath.Join(dir, "world"), 0755)
+	os.WriteFile(filepath.Join(dir, "world", "level.dat"), []byte("world"), 0644)
+	os.WriteFile(filepath.Join(dir, "server.properties"), []byte("level-name=world\n"), 0644)
+	os.WriteFile(filepath.Join(dir, JavaFile), []byte(fakeJars["1.0"]), 0644)
+	return upgrader, mcServer, nil
+}
+
+func TestUpgrade(t *testing.T) {
+	upgrader, mcServer, err := testUpgrader(t, "1.0")
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	database, manager, dir := upgrader.Database, upgrader.Runner, upgrader.Runner.Dir(mcServer.ID)
+	if _, err = manager.Start(mcServer); err != nil {
+		t.Errorf("cannot start fake server: %s", err)
+		return
+	}
+	defer func() { manager.Stop(mcServer.ID, time.Second) }()
+
+	ctx := context.Background()
+	result, err := upgrader.Upgrade(ctx, mcServer, "2.0")
+	if err != nil {
+		t.Errorf("cannot upgrade: %s", err)
+		return
+	} else if result.RolledBack || !result.Restarted || result.Backup == nil || !result.Backup.Safety {
+		t.Errorf("invalid upgrade result: %+v", result)
+		return
+	} else if data, _ := os.ReadFile(filepath.Join(dir, JavaFile)); string(data) != fakeJars["2.0"] {
+		t.Errorf("server file not replaced")
+		return
+	} else if srv, _ := database.Server(mcServer.ID); srv.Version != "2.0" || mcServer.Version != "2.0" {
+		t.Errorf("server version not updated: %s", srv.Version)
+		return
+	}
+
+	if _, err = upgrader.Upgrade(ctx, mcServer, "2.0"); err != ErrUpToDate {
+		t.Errorf("same version upgraded: %v", err)
+		return
+	}
+
+	// Broken version is rolled back
+	if result, err = upgrader.Upgrade(ctx, mcServer, "3.0"); err != nil {
+		t.Errorf("cannot rollback upgrade: %s", err)
+		return
+	} else if !result.RolledBack || !result.Restarted || result.Error == "" {
+		t.Errorf("upgrade not rolled back: %+v", result)
+		return
+	} else if data, _ := os.ReadFile(filepath.Join(dir, JavaFile)); string(data) != fakeJars["2.0"] {
+		t.Errorf("server file not restored")
+		return
+	} else if data, _ := os.ReadFile(filepath.Join(dir, "world", "level.dat")); string(data) != "world" {
+		t.Errorf("world not restored: %q", data)
+		return
+	} else if srv, _ := database.Server(mcServer.ID); srv.Version != "2.0" || mcServer.Version != "2.0" {
+		t.Errorf("server version not restored: %s", srv.Version)
+		return
+	} else if manager.Process(mcServer.ID) == nil {
+		t.Errorf("server not started after rollback")
+	}
+}
@@ -43,6 +43,15 @@ type Token struct {
 	UpdateAt    time.Time        `json:"update_at"`   // Date to update any row in database
 }
 
+// Notification to user, like new version available to server
+type Notification struct {
+	ID       int64     `json:"id"`        // Notification ID
+	UserID   int64     `json:"user_id"`   // User notified, foregin key
+	ServerID int64     `json:"server_id"` // Server reference
+	Message  string    `json:"message"`   // Notification text
+	CreateAt time.Time `json:"create_at"` // Date of creation
+}
+
 // Convert plain key to hash encrypted key
 func (pass *Password) HashPassword(encryptKey string) error {
 	newKey, err := encrypt.Encrypt(pass.Password, encryptKey)
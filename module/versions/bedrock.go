@@ -0,0 +1,132 @@
+package versions
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"path"
+	"regexp"
+	"runtime"
+	"strings"
+
+	"sirherobrine23.com.br/go-bds/bds/module/mcversion"
+)
+
+const (
+	BedrockLinks = "https://net-secondary.web.minecraft-services.net/api/v1.0/download/links" // Bedrock server current downloads
+	BedrockFiles = "https://www.minecraft.net/bedrockdedicatedserver"                         // Bedrock server files base URL
+)
+
+var (
+	bedrockVersion = regexp.MustCompile(`^\d+(\.\d+)*$`)                         // Numeric version, safe to paths and URLs
+	bedrockURL     = regexp.MustCompile(`/bedrock-server-(\d+(?:\.\d+)*)\.zip$`) // Version from download URL
+)
+
+// Bedrock dedicated server, Mojang only list current release and preview, old versions are found by URL pattern
+type Bedrock struct {
+	Links    string       // Downloads links API, default is [BedrockLinks]
+	Files    string       // Files base URL, default is [BedrockFiles]
+	Platform string       // "linux" or "windows", default to current system
+	Client   *http.Client // HTTP client, default is [http.DefaultClient]
+}
+
+type bedrockLinks struct {
+	Result struct {
+		Links []struct {
+			DownloadType string `json:"downloadType"`
+			DownloadURL  string `json:"downloadUrl"`
+		} `json:"links"`
+	} `json:"result"`
+}
+
+func (bds *Bedrock) platform() string {
+	if bds.Platform != "" {
+		return bds.Platform
+	} else if runtime.GOOS == "windows" {
+		return "windows"
+	}
+	return "linux"
+}
+
+func (bds *Bedrock) files() string {
+	if bds.Files != "" {
+		return strings.TrimSuffix(bds.Files, "/")
+	}
+	return BedrockFiles
+}
+
+func (bds *Bedrock) Versions(ctx context.Context) ([]*Version, error) {
+	links := bds.Links
+	if links == "" {
+		links = BedrockLinks
+	}
+	var body bedrockLinks
+	if err := getJSON(ctx, bds.Client, links, &body); err != nil {
+		return nil, err
+	}
+
+	// serverBedrockLinux, serverBedrockPreviewLinux, serverBedrockWindows, serverBedrockPreviewWindows
+	platform := strings.ToLower(bds.platform())
+	var release, preview *Version
+	for _, link := range body.Result.Links {
+		downloadType := strings.ToLower(link.DownloadType)
+		if !strings.HasPrefix(downloadType, "serverbedrock") || !strings.HasSuffix(downloadType, platform) {
+			continue
+		}
+		match := bedrockURL.FindStringSubmatch(link.DownloadURL)
+		if match == nil {
+			continue
+		}
+		version := &Version{Software: "bedrock", Version: match[1], Platform: platform, meta: link.DownloadURL}
+		if version.Preview = strings.Contains(downloadType, "preview"); version.Preview {
+			preview = version
+		} else {
+			release = version
+		}
+	}
+
+	// Preview is listed first only if newer
+	versions := []*Version{}
+	if preview != nil && (release == nil || compareVersions(preview.Version, release.Version) > 0) {
+		versions = append(versions, preview)
+	}
+	if release != nil {
+		versions = append(versions, release)
+	}
+	return versions, nil
+}
+
+// Build URL to version not listed, checked on download
+func (bds *Bedrock) Find(ctx context.Context, version string) (*Version, error) {
+	if !bedrockVersion.MatchString(version) {
+		return nil, ErrVersionNotFound
+	}
+	platform := bds.platform()
+	dir := "bin-linux"
+	if platform == "windows" {
+		dir = "bin-win"
+	}
+	return &Version{
+		Software: "bedrock",
+		Version:  version,
+		Platform: platform,
+		meta:     fmt.Sprintf("%s/%s/bedrock-server-%s.zip", bds.files(), dir, version),
+	}, nil
+}
+
+func (bds *Bedrock) Artifact(ctx context.Context, version *Version) (*Artifact, error) {
+	if version.meta == "" {
+		var err error
+		if version, err = bds.Find(ctx, version.Version); err != nil {
+			return nil, err
+		}
+	}
+	// Mojang not publish checksums, zip is checked after download
+	return &Artifact{Name: path.Base(version.meta), URL: version.meta, Checksum: map[string]string{}}, nil
+}
+
+// Compare numeric versions, not numeric versions are equal
+func compareVersions(a, b string) int {
+	result, _ := mcversion.Compare(a, b)
+	return result
+}
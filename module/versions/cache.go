@@ -0,0 +1,179 @@
+package versions
+
+import (
+	"archive/zip"
+	"context"
+	"crypto/md5"
+	"crypto/sha1"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"hash"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+// Hash functions to artifacts checksums
+var checksums = map[string]func() hash.Hash{
+	"sha1":   sha1.New,
+	"sha256": sha256.New,
+	"md5":    md5.New,
+}
+
+// Downloaded servers files, shared between servers with same version
+type Cache struct {
+	Root   string       // Cache directory
+	Client *http.Client // Client to download, default is [http.DefaultClient]
+
+	mu    sync.Mutex
+	locks map[string]*sync.Mutex
+}
+
+// Create new cache in root
+func NewCache(root string) *Cache {
+	return &Cache{Root: root, locks: map[string]*sync.Mutex{}}
+}
+
+// Artifact path in cache, version names must be file names and bedrock versions numeric
+func (cache *Cache) Path(version *Version, artifact *Artifact) (string, error) {
+	software := strings.ToLower(version.Software)
+	if software == "bedrock" && !bedrockVersion.MatchString(version.Version) {
+		return "", ErrInvalidVersion
+	}
+	if version.Platform != "" {
+		software = software + "-" + version.Platform
+	}
+	name := version.Version
+	if version.Build != "" {
+		name = name + "-" + version.Build
+	}
+
+	file := filepath.Base(artifact.Name)
+	for _, element := range []string{software, name, file} {
+		if element == "" || element == "." || element == ".." || strings.ContainsAny(element, `/\`) {
+			return "", ErrInvalidVersion
+		}
+	}
+	return filepath.Join(cache.Root, software, name, file), nil
+}
+
+// Lock path so only one download run to each file
+func (cache *Cache) lock(name string) func() {
+	cache.mu.Lock()
+	if cache.locks == nil {
+		cache.locks = map[string]*sync.Mutex{}
+	}
+	mu, ok := cache.locks[name]
+	if !ok {
+		mu = &sync.Mutex{}
+		cache.locks[name] = mu
+	}
+	cache.mu.Unlock()
+	mu.Lock()
+	return mu.Unlock
+}
+
+// Return artifact path in cache, download if not exists or if cached file fail verification
+func (cache *Cache) Get(ctx context.Context, version *Version, artifact *Artifact) (string, error) {
+	name, err := cache.Path(version, artifact)
+	if err != nil {
+		return "", err
+	}
+	defer cache.lock(name)()
+
+	if err := Verify(name, artifact); err == nil {
+		return name, nil
+	}
+	if err := cache.download(ctx, name, artifact); err != nil {
+		return "", fmt.Errorf("cannot download %s %s: %w", version.Software, version.Version, err)
+	}
+	return name, nil
+}
+
+// Download to temporary file, verify and rename to name
+func (cache *Cache) download(ctx context.Context, name string, artifact *Artifact) error {
+	client := cache.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	if err := os.MkdirAll(filepath.Dir(name), 0755); err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, artifact.URL, nil)
+	if err != nil {
+		return err
+	}
+	res, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		return fmt.Errorf("cannot get %s: %s", artifact.URL, res.Status)
+	}
+
+	file, err := os.CreateTemp(filepath.Dir(name), ".download-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(file.Name())
+	defer file.Close()
+
+	if _, err = io.Copy(file, res.Body); err != nil {
+		return err
+	} else if err = file.Close(); err != nil {
+		return err
+	} else if err = Verify(file.Name(), artifact); err != nil {
+		return err
+	}
+	return os.Rename(file.Name(), name)
+}
+
+// Check file size and checksums, zip and jar files without checksum are checked opening archive
+func Verify(name string, artifact *Artifact) error {
+	file, err := os.Open(name)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	stat, err := file.Stat()
+	if err != nil {
+		return err
+	} else if artifact.Size > 0 && stat.Size() != artifact.Size {
+		return fmt.Errorf("%w: size %d, expected %d", ErrChecksum, stat.Size(), artifact.Size)
+	}
+
+	hashes, writers := map[string]hash.Hash{}, []io.Writer{}
+	for algo := range artifact.Checksum {
+		if newHash, ok := checksums[algo]; ok {
+			hashes[algo] = newHash()
+			writers = append(writers, hashes[algo])
+		}
+	}
+
+	if len(hashes) == 0 {
+		switch strings.ToLower(filepath.Ext(artifact.Name)) {
+		case ".zip", ".jar":
+			if _, err := zip.NewReader(file, stat.Size()); err != nil {
+				return fmt.Errorf("%w: invalid archive: %s", ErrChecksum, err)
+			}
+		}
+		return nil
+	}
+
+	if _, err := io.Copy(io.MultiWriter(writers...), file); err != nil {
+		return err
+	}
+	for algo, sum := range hashes {
+		if value := hex.EncodeToString(sum.Sum(nil)); !strings.EqualFold(value, artifact.Checksum[algo]) {
+			return fmt.Errorf("%w: %s %s, expected %s", ErrChecksum, algo, value, artifact.Checksum[algo])
+		}
+	}
+	return nil
+}
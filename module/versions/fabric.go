@@ -0,0 +1,93 @@
+package versions
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+)
+
+const FabricAPI = "https://meta.fabricmc.net" // Fabric meta API
+
+// Fabric server launcher, version is game version and build is loader version
+type Fabric struct {
+	API    string       // API URL, default is [FabricAPI]
+	Client *http.Client // HTTP client, default is [http.DefaultClient]
+}
+
+type fabricVersion struct {
+	Version string `json:"version"`
+	Stable  bool   `json:"stable"`
+}
+
+func (fabric *Fabric) api() string {
+	if fabric.API != "" {
+		return strings.TrimSuffix(fabric.API, "/")
+	}
+	return FabricAPI
+}
+
+// Newest stable version in list, or newest if none stable
+func fabricStable(list []fabricVersion) (string, error) {
+	if len(list) == 0 {
+		return "", ErrVersionNotFound
+	} else if index := slices.IndexFunc(list, func(version fabricVersion) bool { return version.Stable }); index != -1 {
+		return list[index].Version, nil
+	}
+	return list[0].Version, nil
+}
+
+func (fabric *Fabric) Versions(ctx context.Context) ([]*Version, error) {
+	var games, loaders []fabricVersion
+	if err := getJSON(ctx, fabric.Client, fabric.api()+"/v2/versions/game", &games); err != nil {
+		return nil, err
+	} else if err = getJSON(ctx, fabric.Client, fabric.api()+"/v2/versions/loader", &loaders); err != nil {
+		return nil, err
+	}
+	loader, err := fabricStable(loaders)
+	if err != nil {
+		return nil, fmt.Errorf("cannot find fabric loader: %s", err)
+	}
+
+	versions := []*Version{}
+	for _, game := range games {
+		versions = append(versions, &Version{
+			Software: "fabric",
+			Version:  game.Version,
+			Preview:  !game.Stable,
+			Build:    loader,
+		})
+	}
+	return versions, nil
+}
+
+func (fabric *Fabric) Artifact(ctx context.Context, version *Version) (*Artifact, error) {
+	var installers []fabricVersion
+	if err := getJSON(ctx, fabric.Client, fabric.api()+"/v2/versions/installer", &installers); err != nil {
+		return nil, err
+	}
+	installer, err := fabricStable(installers)
+	if err != nil {
+		return nil, fmt.Errorf("cannot find fabric installer: %s", err)
+	}
+
+	loader := version.Build
+	if loader == "" {
+		var loaders []fabricVersion
+		if err := getJSON(ctx, fabric.Client, fabric.api()+"/v2/versions/loader", &loaders); err != nil {
+			return nil, err
+		} else if loader, err = fabricStable(loaders); err != nil {
+			return nil, fmt.Errorf("cannot find fabric loader: %s", err)
+		}
+	}
+
+	// Fabric not publish checksums to server launcher, jar is checked after download
+	return &Artifact{
+		Name: fmt.Sprintf("fabric-server-mc.%s-loader.%s-launcher.%s.jar", version.Version, loader, installer),
+		URL: fmt.Sprintf("%s/v2/versions/loader/%s/%s/%s/server/jar", fabric.api(),
+			url.PathEscape(version.Version), url.PathEscape(loader), url.PathEscape(installer)),
+		Checksum: map[string]string{},
+	}, nil
+}
@@ -0,0 +1,85 @@
+package versions
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+const JavaManifest = "https://piston-meta.mojang.com/mc/game/version_manifest_v2.json" // Vanilla versions manifest
+
+// Mojang vanilla java server
+type Java struct {
+	Manifest string       // Versions manifest URL, default is [JavaManifest]
+	Client   *http.Client // HTTP client, default is [http.DefaultClient]
+}
+
+type javaManifest struct {
+	Versions []struct {
+		ID          string    `json:"id"`
+		Type        string    `json:"type"` // release, snapshot, old_beta or old_alpha
+		URL         string    `json:"url"`
+		ReleaseTime time.Time `json:"releaseTime"`
+	} `json:"versions"`
+}
+
+type javaVersion struct {
+	Downloads struct {
+		Server *struct {
+			SHA1 string `json:"sha1"`
+			Size int64  `json:"size"`
+			URL  string `json:"url"`
+		} `json:"server"`
+	} `json:"downloads"`
+	JavaVersion struct {
+		MajorVersion int `json:"majorVersion"`
+	} `json:"javaVersion"`
+}
+
+func (java *Java) Versions(ctx context.Context) ([]*Version, error) {
+	manifest := java.Manifest
+	if manifest == "" {
+		manifest = JavaManifest
+	}
+	var body javaManifest
+	if err := getJSON(ctx, java.Client, manifest, &body); err != nil {
+		return nil, err
+	}
+
+	versions := []*Version{}
+	for _, version := range body.Versions {
+		// Old alpha and beta not have server
+		if version.Type != "release" && version.Type != "snapshot" {
+			continue
+		}
+		versions = append(versions, &Version{
+			Software: "java",
+			Version:  version.ID,
+			Preview:  version.Type == "snapshot",
+			Release:  version.ReleaseTime,
+			meta:     version.URL,
+		})
+	}
+	return versions, nil
+}
+
+func (java *Java) Artifact(ctx context.Context, version *Version) (*Artifact, error) {
+	if version.meta == "" {
+		return nil, ErrVersionNotFound
+	}
+	var body javaVersion
+	if err := getJSON(ctx, java.Client, version.meta, &body); err != nil {
+		return nil, err
+	} else if body.Downloads.Server == nil {
+		return nil, fmt.Errorf("java %s not have server file", version.Version)
+	}
+
+	return &Artifact{
+		Name:     "server.jar",
+		URL:      body.Downloads.Server.URL,
+		Size:     body.Downloads.Server.Size,
+		Checksum: map[string]string{"sha1": body.Downloads.Server.SHA1},
+		Java:     body.JavaVersion.MajorVersion,
+	}, nil
+}
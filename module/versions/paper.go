@@ -0,0 +1,159 @@
+package versions
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+)
+
+const (
+	PaperAPI  = "https://fill.papermc.io"  // PaperMC downloads API
+	PurpurAPI = "https://api.purpurmc.org" // Purpur downloads API
+)
+
+// PaperMC projects, like paper and folia
+type Paper struct {
+	API     string       // API URL, default is [PaperAPI]
+	Project string       // Project name, default is "paper"
+	Client  *http.Client // HTTP client, default is [http.DefaultClient]
+}
+
+type paperVersions struct {
+	Versions []struct {
+		Version struct {
+			ID   string `json:"id"`
+			Java struct {
+				Version struct {
+					Minimum int `json:"minimum"`
+				} `json:"version"`
+			} `json:"java"`
+		} `json:"version"`
+		Builds []int `json:"builds"`
+	} `json:"versions"`
+}
+
+type paperBuild struct {
+	ID        int    `json:"id"`
+	Channel   string `json:"channel"` // ALPHA, BETA, STABLE or RECOMMENDED
+	Downloads map[string]struct {
+		Name      string            `json:"name"`
+		Checksums map[string]string `json:"checksums"`
+		Size      int64             `json:"size"`
+		URL       string            `json:"url"`
+	} `json:"downloads"`
+}
+
+func (paper *Paper) project() (string, string) {
+	api, project := strings.TrimSuffix(paper.API, "/"), paper.Project
+	if api == "" {
+		api = PaperAPI
+	}
+	if project == "" {
+		project = "paper"
+	}
+	return api, project
+}
+
+func (paper *Paper) Versions(ctx context.Context) ([]*Version, error) {
+	api, project := paper.project()
+	var body paperVersions
+	if err := getJSON(ctx, paper.Client, fmt.Sprintf("%s/v3/projects/%s/versions", api, project), &body); err != nil {
+		return nil, err
+	}
+
+	versions := []*Version{}
+	for _, version := range body.Versions {
+		if len(version.Builds) == 0 {
+			continue
+		}
+		versions = append(versions, &Version{
+			Software: project,
+			Version:  version.Version.ID,
+			Preview:  strings.Contains(version.Version.ID, "-"), // 1.21.9-pre2 or 1.21.9-rc1
+			Build:    fmt.Sprint(slices.Max(version.Builds)),
+			Java:     version.Version.Java.Version.Minimum,
+		})
+	}
+	return versions, nil
+}
+
+func (paper *Paper) Artifact(ctx context.Context, version *Version) (*Artifact, error) {
+	api, project := paper.project()
+	build := version.Build
+	if build == "" {
+		build = "latest"
+	}
+
+	var body paperBuild
+	if err := getJSON(ctx, paper.Client, fmt.Sprintf("%s/v3/projects/%s/versions/%s/builds/%s", api, project, url.PathEscape(version.Version), build), &body); err != nil {
+		return nil, err
+	}
+	download, ok := body.Downloads["server:default"]
+	if !ok {
+		return nil, fmt.Errorf("%s %s build %d not have server file", project, version.Version, body.ID)
+	}
+
+	return &Artifact{
+		Name:     download.Name,
+		URL:      download.URL,
+		Size:     download.Size,
+		Checksum: download.Checksums,
+		Java:     version.Java,
+	}, nil
+}
+
+// Purpur, paper fork
+type Purpur struct {
+	API    string       // API URL, default is [PurpurAPI]
+	Client *http.Client // HTTP client, default is [http.DefaultClient]
+}
+
+func (purpur *Purpur) api() string {
+	if purpur.API != "" {
+		return strings.TrimSuffix(purpur.API, "/")
+	}
+	return PurpurAPI
+}
+
+func (purpur *Purpur) Versions(ctx context.Context) ([]*Version, error) {
+	var body struct {
+		Versions []string `json:"versions"` // Oldest first
+	}
+	if err := getJSON(ctx, purpur.Client, purpur.api()+"/v2/purpur", &body); err != nil {
+		return nil, err
+	}
+
+	versions := []*Version{}
+	for _, version := range slices.Backward(body.Versions) {
+		versions = append(versions, &Version{Software: "purpur", Version: version})
+	}
+	return versions, nil
+}
+
+func (purpur *Purpur) Artifact(ctx context.Context, version *Version) (*Artifact, error) {
+	build := version.Build
+	if build == "" {
+		build = "latest"
+	}
+
+	var body struct {
+		Build  string `json:"build"`
+		Result string `json:"result"`
+		MD5    string `json:"md5"`
+	}
+	versionURL := fmt.Sprintf("%s/v2/purpur/%s", purpur.api(), url.PathEscape(version.Version))
+	if err := getJSON(ctx, purpur.Client, versionURL+"/"+build, &body); err != nil {
+		return nil, err
+	} else if body.Result != "SUCCESS" {
+		return nil, fmt.Errorf("purpur %s build %s failed", version.Version, body.Build)
+	}
+
+	return &Artifact{
+		Name:     fmt.Sprintf("purpur-%s-%s.jar", version.Version, body.Build),
+		URL:      fmt.Sprintf("%s/%s/download", versionURL, body.Build),
+		Checksum: map[string]string{"md5": body.MD5},
+	}, nil
+}
@@ -0,0 +1,226 @@
+// Resolve and download Minecraft servers versions from software manifests
+package versions
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	Latest  = "latest"  // Newest stable version
+	Preview = "preview" // Newest preview, snapshot or experimental version
+)
+
+var (
+	ErrSoftwareNotExists error = errors.New("software not exists")
+	ErrVersionNotFound   error = errors.New("version not found")
+	ErrChecksum          error = errors.New("download checksum mismatch")
+	ErrInvalidVersion    error = errors.New("invalid version name")
+
+	ListTTL = time.Minute * 10 // Time to keep versions lists in memory
+)
+
+// Server version from manifest
+type Version struct {
+	Software string    `json:"software"`           // Software name
+	Version  string    `json:"version"`            // Game version
+	Preview  bool      `json:"preview"`            // Preview, snapshot or experimental version
+	Release  time.Time `json:"release,omitzero"`   // Release date if manifest have it
+	Build    string    `json:"build,omitempty"`    // Software build, loader version to Fabric
+	Java     int       `json:"java,omitempty"`     // Minimum java version, 0 if unknown
+	Platform string    `json:"platform,omitempty"` // Bedrock server platform
+
+	meta string // Manifest URL with version details
+}
+
+// Server file to download
+type Artifact struct {
+	Name     string            `json:"name"`           // File name
+	URL      string            `json:"url"`            // Download URL
+	Size     int64             `json:"size,omitempty"` // File size, 0 if unknown
+	Checksum map[string]string `json:"checksum"`       // Hex checksums, sha1, sha256 or md5
+	Java     int               `json:"java,omitempty"` // Minimum java version, 0 if unknown
+}
+
+// Software versions source
+type Resolver interface {
+	Versions(ctx context.Context) ([]*Version, error)                  // Avaible versions, newest first
+	Artifact(ctx context.Context, version *Version) (*Artifact, error) // Resolve version file to download
+}
+
+// Resolver can find version not in list, like old bedrock versions
+type Finder interface {
+	Find(ctx context.Context, version string) (*Version, error)
+}
+
+// Find "latest", "preview" or exact version in list.
+//
+// "preview" return newest version if is preview, so preview is never older than latest
+func Find(list []*Version, query string) (*Version, error) {
+	query = strings.TrimSpace(query)
+	switch strings.ToLower(query) {
+	case "", Latest:
+		if index := slices.IndexFunc(list, func(version *Version) bool { return !version.Preview }); index != -1 {
+			return list[index], nil
+		}
+	case Preview:
+		if len(list) > 0 {
+			return list[0], nil
+		}
+	default:
+		if index := slices.IndexFunc(list, func(version *Version) bool { return version.Version == query }); index != -1 {
+			return list[index], nil
+		}
+	}
+	return nil, ErrVersionNotFound
+}
+
+// Get json from url to target
+func getJSON(ctx context.Context, client *http.Client, url string, target any) error {
+	if client == nil {
+		client = http.DefaultClient
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/json")
+
+	res, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+	if res.StatusCode == http.StatusNotFound {
+		return ErrVersionNotFound
+	} else if res.StatusCode != http.StatusOK {
+		return fmt.Errorf("cannot get %s: %s", url, res.Status)
+	}
+	if err := json.NewDecoder(res.Body).Decode(target); err != nil {
+		return fmt.Errorf("cannot decode %s: %s", url, err)
+	}
+	return nil
+}
+
+type cachedList struct {
+	versions []*Version
+	expire   time.Time
+}
+
+// Resolve softwares versions and keep downloads in cache
+type Manager struct {
+	Resolvers map[string]Resolver // Softwares resolvers, key is lower case software name
+	Cache     *Cache              // Downloaded files
+
+	mu    sync.Mutex
+	lists map[string]cachedList
+}
+
+// Create manager with default resolvers and cache in root
+func NewManager(root string) *Manager {
+	return &Manager{
+		Resolvers: map[string]Resolver{
+			"bedrock": &Bedrock{},
+			"java":    &Java{},
+			"paper":   &Paper{Project: "paper"},
+			"folia":   &Paper{Project: "folia"},
+			"purpur":  &Purpur{},
+			"fabric":  &Fabric{},
+		},
+		Cache: NewCache(root),
+		lists: map[string]cachedList{},
+	}
+}
+
+// Softwares names
+func (mg *Manager) Softwares() []string {
+	names := make([]string, 0, len(mg.Resolvers))
+	for name := range mg.Resolvers {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	return names
+}
+
+// Get software resolver
+func (mg *Manager) Resolver(software string) (Resolver, error) {
+	if resolver, ok := mg.Resolvers[strings.ToLower(software)]; ok {
+		return resolver, nil
+	}
+	return nil, ErrSoftwareNotExists
+}
+
+// Software versions, newest first. Lists are kept for [ListTTL]
+func (mg *Manager) Versions(ctx context.Context, software string) ([]*Version, error) {
+	resolver, err := mg.Resolver(software)
+	if err != nil {
+		return nil, err
+	}
+	software = strings.ToLower(software)
+
+	mg.mu.Lock()
+	cached, ok := mg.lists[software]
+	mg.mu.Unlock()
+	if ok && time.Now().Before(cached.expire) {
+		return cached.versions, nil
+	}
+
+	list, err := resolver.Versions(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("cannot get %s versions: %s", software, err)
+	}
+
+	mg.mu.Lock()
+	defer mg.mu.Unlock()
+	if mg.lists == nil {
+		mg.lists = map[string]cachedList{}
+	}
+	mg.lists[software] = cachedList{versions: list, expire: time.Now().Add(ListTTL)}
+	return list, nil
+}
+
+// Resolve "latest", "preview" or exact version
+func (mg *Manager) Resolve(ctx context.Context, software, query string) (*Version, error) {
+	list, err := mg.Versions(ctx, software)
+	if err != nil {
+		return nil, err
+	}
+	version, err := Find(list, query)
+	if err == ErrVersionNotFound {
+		resolver, _ := mg.Resolver(software)
+		if finder, ok := resolver.(Finder); ok && !strings.EqualFold(query, Latest) && !strings.EqualFold(query, Preview) {
+			return finder.Find(ctx, query)
+		}
+	}
+	return version, err
+}
+
+// Resolve version and download to cache, return version and file path
+func (mg *Manager) Fetch(ctx context.Context, software, query string) (*Version, string, error) {
+	version, err := mg.Resolve(ctx, software, query)
+	if err != nil {
+		return nil, "", err
+	}
+	resolver, _ := mg.Resolver(software)
+	artifact, err := resolver.Artifact(ctx, version)
+	if err != nil {
+		return nil, "", fmt.Errorf("cannot resolve %s %s download: %s", version.Software, version.Version, err)
+	}
+	resolved := *version // Not change version in cached list
+	if resolved.Java == 0 {
+		resolved.Java = artifact.Java
+	}
+
+	file, err := mg.Cache.Get(ctx, &resolved, artifact)
+	if err != nil {
+		return nil, "", err
+	}
+	return &resolved, file, nil
+}
@@ -0,0 +1,162 @@
+package versions
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+)
+
+// Replay recorded manifests, "{{server}}" in files is replaced with test server URL
+func testServer(t *testing.T) (*httptest.Server, *atomic.Int32) {
+	routes := map[string]string{
+		"/api/v1.0/download/links":                     "bedrock_links.json",
+		"/mc/game/version_manifest_v2.json":            "java_manifest.json",
+		"/v1/packages/1.21.8.json":                     "java_1.21.8.json",
+		"/v3/projects/paper/versions":                  "paper_versions.json",
+		"/v3/projects/paper/versions/1.21.8/builds/60": "paper_1.21.8_60.json",
+		"/v2/purpur":               "purpur_versions.json",
+		"/v2/purpur/1.21.8/latest": "purpur_1.21.8_latest.json",
+		"/v2/versions/game":        "fabric_game.json",
+		"/v2/versions/loader":      "fabric_loader.json",
+		"/v2/versions/installer":   "fabric_installer.json",
+	}
+
+	downloads := &atomic.Int32{}
+	var ts *httptest.Server
+	ts = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		name, ok := routes[r.URL.Path]
+		switch {
+		case ok:
+		case strings.HasSuffix(r.URL.Path, ".zip"), strings.HasSuffix(r.URL.Path, ".jar"),
+			strings.HasSuffix(r.URL.Path, "/download"), strings.HasSuffix(r.URL.Path, "/server/jar"):
+			downloads.Add(1)
+			name = "server.jar"
+		default:
+			http.NotFound(w, r)
+			return
+		}
+
+		data, err := os.ReadFile(filepath.Join("testdata", name))
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		w.Write(bytes.ReplaceAll(data, []byte("{{server}}"), []byte(ts.URL)))
+	}))
+	t.Cleanup(ts.Close)
+	return ts, downloads
+}
+
+func testManager(t *testing.T) (*Manager, *atomic.Int32) {
+	ts, downloads := testServer(t)
+	manager := NewManager(t.TempDir())
+	manager.Resolvers = map[string]Resolver{
+		"bedrock": &Bedrock{Links: ts.URL + "/api/v1.0/download/links", Files: ts.URL + "/bedrockdedicatedserver", Platform: "linux"},
+		"java":    &Java{Manifest: ts.URL + "/mc/game/version_manifest_v2.json"},
+		"paper":   &Paper{API: ts.URL, Project: "paper"},
+		"purpur":  &Purpur{API: ts.URL},
+		"fabric":  &Fabric{API: ts.URL},
+	}
+	return manager, downloads
+}
+
+func TestResolve(t *testing.T) {
+	manager, _ := testManager(t)
+	ctx := context.Background()
+
+	for _, test := range []struct{ software, query, version, build string }{
+		{"bedrock", "latest", "1.21.101.1", ""},
+		{"bedrock", "preview", "1.21.110.22", ""},
+		{"bedrock", "1.20.81.01", "1.20.81.01", ""},
+		{"java", "latest", "1.21.8", ""},
+		{"java", "preview", "25w32a", ""},
+		{"java", "1.21.7", "1.21.7", ""},
+		{"paper", "latest", "1.21.8", "60"},
+		{"paper", "preview", "1.21.9-pre2", "3"},
+		{"purpur", "latest", "1.21.8", ""},
+		{"fabric", "latest", "1.21.8", "0.17.2"},
+		{"fabric", "preview", "25w32a", "0.17.2"},
+	} {
+		version, err := manager.Resolve(ctx, test.software, test.query)
+		if err != nil {
+			t.Errorf("cannot resolve %s %s: %s", test.software, test.query, err)
+			return
+		} else if version.Version != test.version || version.Build != test.build {
+			t.Errorf("%s %s resolved to %s build %s, expected %s build %s", test.software, test.query, version.Version, version.Build, test.version, test.build)
+			return
+		}
+	}
+
+	if _, err := manager.Resolve(ctx, "java", "b1.7.3"); err != ErrVersionNotFound {
+		t.Errorf("old beta without server resolved: %v", err)
+		return
+	} else if _, err = manager.Resolve(ctx, "bedrock", "../1.20.81.01"); err != ErrVersionNotFound {
+		t.Errorf("bedrock version with path resolved: %v", err)
+		return
+	} else if _, err = manager.Cache.Path(&Version{Software: "bedrock", Version: "1.20/../.."}, &Artifact{Name: "bedrock-server.zip"}); err != ErrInvalidVersion {
+		t.Errorf("invalid bedrock version accepted in cache: %v", err)
+		return
+	} else if _, err = manager.Resolve(ctx, "forge", "latest"); err != ErrSoftwareNotExists {
+		t.Errorf("unknown software resolved: %v", err)
+	}
+}
+
+func TestFetch(t *testing.T) {
+	manager, downloads := testManager(t)
+	ctx := context.Background()
+
+	expected, _ := os.ReadFile(filepath.Join("testdata", "server.jar"))
+	for _, software := range []string{"bedrock", "java", "paper", "purpur", "fabric"} {
+		version, file, err := manager.Fetch(ctx, software, "latest")
+		if err != nil {
+			t.Errorf("cannot fetch %s: %s", software, err)
+			return
+		} else if data, _ := os.ReadFile(file); !bytes.Equal(data, expected) {
+			t.Errorf("%s downloaded file not match", software)
+			return
+		} else if software == "java" && version.Java != 21 {
+			t.Errorf("java version not set: %d", version.Java)
+			return
+		}
+	}
+
+	// Second fetch use cache
+	if _, _, err := manager.Fetch(ctx, "java", "latest"); err != nil {
+		t.Errorf("cannot fetch cached java: %s", err)
+		return
+	} else if count := downloads.Load(); count != 5 {
+		t.Errorf("expected 5 downloads, got %d", count)
+		return
+	}
+
+	// Corrupted cache is downloaded again
+	version, _ := manager.Resolve(ctx, "java", "latest")
+	artifact, _ := manager.Resolvers["java"].Artifact(ctx, version)
+	name, _ := manager.Cache.Path(version, artifact)
+	os.WriteFile(name, []byte("corrupted"), 0644)
+	if _, _, err := manager.Fetch(ctx, "java", "latest"); err != nil {
+		t.Errorf("cannot fetch java again: %s", err)
+		return
+	} else if count := downloads.Load(); count != 6 {
+		t.Errorf("corrupted cache not downloaded again")
+		return
+	}
+
+	// Checksum mismatch is not saved
+	artifact.Checksum["sha1"] = strings.Repeat("0", 40)
+	version.Version = "mismatch"
+	if _, err := manager.Cache.Get(ctx, version, artifact); !errors.Is(err, ErrChecksum) {
+		t.Errorf("checksum mismatch accepted: %v", err)
+	} else if name, _ = manager.Cache.Path(version, artifact); name == "" {
+		t.Errorf("cannot get cache path")
+	} else if _, err = os.Stat(name); err == nil {
+		t.Errorf("invalid download saved in cache")
+	}
+}
@@ -0,0 +1,96 @@
+package web
+
+import (
+	"errors"
+	"net/http"
+	"os"
+
+	"github.com/go-chi/chi/v5"
+	"sirherobrine23.com.br/go-bds/bds/module/addons"
+)
+
+// Response addons errors
+func addonsError(w http.ResponseWriter, err error) {
+	var conflict *addons.ConflictError
+	switch {
+	case errors.As(err, &conflict):
+		jsonResponse(w, http.StatusConflict, map[string]any{"error": "dependency", "message": err.Error(), "conflicts": conflict.Conflicts})
+	case errors.Is(err, addons.ErrDependency), errors.Is(err, addons.ErrPackExists):
+		jsonResponse(w, http.StatusConflict, map[string]string{"error": "pack", "message": err.Error()})
+	case err == addons.ErrPackNotExists, err == addons.ErrWorldNotExists:
+		jsonResponse(w, http.StatusNotFound, map[string]string{"error": "not found", "message": err.Error()})
+	case err == addons.ErrNoPacks, errors.Is(err, addons.ErrInvalidManifest):
+		jsonResponse(w, http.StatusBadRequest, map[string]string{"error": "invalid pack", "message": err.Error()})
+	default:
+		jsonResponse(w, http.StatusInternalServerError, map[string]string{
+			"error":   "internal error",
+			"message": err.Error(),
+		})
+	}
+}
+
+// List installed packs
+func serverAddons(w http.ResponseWriter, r *http.Request) {
+	packs, err := addons.List(serverDir(r))
+	if err != nil {
+		addonsError(w, err)
+		return
+	}
+	jsonResponse(w, http.StatusOK, packs)
+}
+
+// Install .mcpack or .mcaddon from body, raw or multipart with file in "pack" field
+func serverAddonInstall(w http.ResponseWriter, r *http.Request) {
+	tmp, size, ok := spoolBody(w, r, "pack")
+	if !ok {
+		return
+	}
+	defer os.Remove(tmp.Name())
+	defer tmp.Close()
+
+	packs, err := addons.Install(serverDir(r), tmp, size)
+	if err != nil {
+		addonsError(w, err)
+		return
+	}
+	jsonResponse(w, http.StatusCreated, packs)
+}
+
+// Remove pack and disable in all worlds
+func serverAddonRemove(w http.ResponseWriter, r *http.Request) {
+	if err := addons.Remove(serverDir(r), chi.URLParam(r, "pack")); err != nil {
+		addonsError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// World enabled packs and conflicts
+func serverWorldAddons(w http.ResponseWriter, r *http.Request) {
+	world, err := addons.World(serverDir(r), worldName(r))
+	if err != nil {
+		addonsError(w, err)
+		return
+	}
+	jsonResponse(w, http.StatusOK, world)
+}
+
+// Enable pack and dependencies in world
+func serverWorldAddonEnable(w http.ResponseWriter, r *http.Request) {
+	world, err := addons.Enable(serverDir(r), worldName(r), chi.URLParam(r, "pack"))
+	if err != nil {
+		addonsError(w, err)
+		return
+	}
+	jsonResponse(w, http.StatusOK, world)
+}
+
+// Disable pack in world
+func serverWorldAddonDisable(w http.ResponseWriter, r *http.Request) {
+	world, err := addons.Disable(serverDir(r), worldName(r), chi.URLParam(r, "pack"))
+	if err != nil {
+		addonsError(w, err)
+		return
+	}
+	jsonResponse(w, http.StatusOK, world)
+}
@@ -19,10 +19,22 @@ import (
 var API = chi.NewMux()
 
 // Add this if API only avaible
-func ApiCaller(database db.Database) http.Handler {
+func ApiCaller(services Services) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		// Add database to context
-		ctx := context.WithValue(r.Context(), DatabaseContext, database)
+		// Add services to context
+		ctx := context.WithValue(r.Context(), DatabaseContext, services.Database)
+		ctx = context.WithValue(ctx, RunnerContext, services.Runner)
+		ctx = context.WithValue(ctx, LogsContext, services.Logs)
+		ctx = context.WithValue(ctx, PlayersContext, services.Players)
+		ctx = context.WithValue(ctx, BackupsContext, services.Backups)
+		ctx = context.WithValue(ctx, TasksContext, services.Tasks)
+		ctx = context.WithValue(ctx, VersionsContext, services.Versions)
+		ctx = context.WithValue(ctx, UpgradesContext, services.Upgrades)
+		ctx = context.WithValue(ctx, UpdatesContext, services.Updates)
+		ctx = context.WithValue(ctx, FilesContext, services.Files)
+		ctx = context.WithValue(ctx, WorldsContext, services.Worlds)
+		ctx = context.WithValue(ctx, PortsContext, services.Ports)
+		ctx = context.WithValue(ctx, StatusContext, services.Status)
 		API.ServeHTTP(w, r.WithContext(ctx))
 	})
 }
@@ -36,6 +48,78 @@ func jsonResponse(w http.ResponseWriter, status int, body any) {
 	_ = js.Encode(body) // Ignore error
 }
 
+// Add server to context, user must be owner or friend with one of perms
+func serverMiddleware(perms ...server.ServerPermission) func(next http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			token := Token(r.Context())
+			if token == nil {
+				jsonResponse(w, http.StatusUnauthorized, map[string]string{
+					"error":   "authoraztion",
+					"message": "require token to access this route",
+				})
+				return
+			}
+			serverID, _ := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+			database := Database(r.Context())
+			user := User(r.Context())
+
+			mcServer, err := database.Server(serverID)
+			if err != nil {
+				switch err {
+				case io.EOF, db.ErrServerNotExists:
+					jsonResponse(w, http.StatusNotFound, map[string]string{"error": "server not found"})
+				default:
+					jsonResponse(w, http.StatusInternalServerError, map[string]string{
+						"error":   "internal error",
+						"message": err.Error(),
+					})
+				}
+				return
+			}
+
+			if mcServer.Owner != user.UserID {
+				friends, err := database.ServerFriends(serverID)
+				if err != nil {
+					switch err {
+					case io.EOF:
+						jsonResponse(w, http.StatusBadRequest, map[string]string{"error": "server not found"})
+					default:
+						jsonResponse(w, http.StatusInternalServerError, map[string]string{
+							"error":   "internal error",
+							"message": err.Error(),
+						})
+					}
+					return
+				}
+
+				friendIndex := slices.IndexFunc(friends, func(friend *server.ServerFriends) bool {
+					for _, perm := range friend.Permission {
+						if slices.Contains(perms, perm) && friend.UserID == user.UserID {
+							return true
+						}
+					}
+
+					return false
+				})
+
+				if friendIndex == -1 {
+					jsonResponse(w, http.StatusNotFound, map[string]string{"error": "server not found"})
+					return
+				}
+
+				// Add friend
+				r = r.WithContext(context.WithValue(r.Context(), ServerFriendContext, friends[friendIndex]))
+			}
+
+			// Add server to next call
+			r = r.WithContext(context.WithValue(r.Context(), ServerContext, mcServer))
+
+			next.ServeHTTP(w, r) // call next router
+		})
+	}
+}
+
 func init() {
 	// Default router response
 	API.NotFound(func(w http.ResponseWriter, r *http.Request) {
@@ -56,7 +140,11 @@ func init() {
 				return
 			}
 
-			if Authorization := r.Header.Get("Authorization"); Authorization != "" {
+			Authorization := r.Header.Get("Authorization")
+			if token, ok := websocketToken(r); ok && Authorization == "" {
+				Authorization = "bearer " + token // Browsers cannot set headers in websocket
+			}
+			if Authorization != "" {
 				if !(strings.HasPrefix(strings.ToLower(Authorization), "bearer ") || strings.HasPrefix(strings.ToLower(Authorization), "token ")) {
 					jsonResponse(w, http.StatusUnauthorized, map[string]string{
 						"error":   "basic auth",
@@ -82,79 +170,22 @@ func init() {
 		})
 	})
 
-	// User server
-	API.Route("/server/{id:[0-9]+}", func(API chi.Router) {
-		API.Use(func(next http.Handler) http.Handler {
-			return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-				token := Token(r.Context())
-				if token == nil {
-					jsonResponse(w, http.StatusUnauthorized, map[string]string{
-						"error":   "authoraztion",
-						"message": "require token to access this route",
-					})
-					return
-				}
-				serverID, _ := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
-				db := Database(r.Context())
-				user := User(r.Context())
-
-				mcServer, err := db.Server(serverID)
-				if err != nil {
-					switch err {
-					case io.EOF:
-						jsonResponse(w, http.StatusNotFound, map[string]string{"error": "server not found"})
-					default:
-						jsonResponse(w, http.StatusInternalServerError, map[string]string{
-							"error":   "internal error",
-							"message": err.Error(),
-						})
-					}
-					return
-				}
-
-				if mcServer.Owner != user.UserID {
-					friends, err := db.ServerFriends(serverID)
-					if err != nil {
-						switch err {
-						case io.EOF:
-							jsonResponse(w, http.StatusBadRequest, map[string]string{"error": "server not found"})
-						default:
-							jsonResponse(w, http.StatusInternalServerError, map[string]string{
-								"error":   "internal error",
-								"message": err.Error(),
-							})
-						}
-						return
-					}
-
-					friendIndex := slices.IndexFunc(friends, func(friend *server.ServerFriends) bool {
-						for _, perm := range friend.Permission {
-							if (perm == server.View || perm == server.Edit) && friend.UserID == user.UserID {
-								return true
-							}
-						}
+	// Server console, friends with console permission can access without view permission
+	API.With(serverMiddleware(server.View, server.Edit, server.Console)).Get("/server/{id:[0-9]+}/console", serverConsole)
 
-						return false
-					})
-
-					if friendIndex == -1 {
-						jsonResponse(w, http.StatusNotFound, map[string]string{"error": "server not found"})
-						return
-					}
-
-					// Add friend
-					r = r.WithContext(context.WithValue(r.Context(), ServerFriendContext, friends[friendIndex]))
-				}
-
-				// Add server to next call
-				r = r.WithContext(context.WithValue(r.Context(), ServerContext, mcServer))
+	// User notifications
+	API.Route("/notifications", func(API chi.Router) {
+		API.Use(requireUser)
+		API.Get("/", userNotifications)                                // List notifications, newest first
+		API.Delete("/{notificationID:[0-9]+}", userNotificationDelete) // Remove notification
+	})
 
-				next.ServeHTTP(w, r) // call next router
-			})
-		})
+	// User server
+	API.Route("/server/{id:[0-9]+}", func(API chi.Router) {
+		API.Use(serverMiddleware(server.View, server.Edit))
 
 		// Get Server info
-		API.Get("/", func(w http.ResponseWriter, r *http.Request) {})
+		API.Get("/", serverInfo)
 
 		// Delete server
 		API.Delete("/", func(w http.ResponseWriter, r *http.Request) {})
@@ -162,37 +193,166 @@ func init() {
 		// Update server
 		API.Put("/", func(w http.ResponseWriter, r *http.Request) {})
 
+		// Server logs
+		API.Route("/logs", func(API chi.Router) {
+			API.Get("/", serverLogsSearch)                    // Search lines
+			API.Get("/runs", serverLogsRuns)                  // List runs
+			API.Get("/runs/{run:[0-9]+}", serverLogsDownload) // Download run
+			API.Get("/retention", serverLogsRetention)        // Get retention policy
+			API.Put("/retention", serverLogsRetentionUpdate)  // Update retention policy
+		})
+
 		// Server config
 		API.Route("/config", func(API chi.Router) {
-			API.Get("/", func(w http.ResponseWriter, r *http.Request) {})
-			API.Post("/", func(w http.ResponseWriter, r *http.Request) {})
+			API.Get("/", serverConfig)
+			API.Post("/", serverConfigReplace)
+			API.Patch("/", serverConfigPatch)
 		})
 
+		// Server ports, allocated from runner range
+		API.Get("/ports", serverPorts)
+		API.Post("/ports", serverPortsAllocate) // Allocate new ports
+
 		// Server players
 		API.Route("/players", func(API chi.Router) {
 			// Get current users if avaible
-			API.Get("/", func(w http.ResponseWriter, r *http.Request) {})
+			API.Get("/", serverPlayers)
 
 			API.Route("/{username}", func(API chi.Router) {
-				API.Get("/", func(w http.ResponseWriter, r *http.Request) {})    // Get current status
-				API.Post("/", func(w http.ResponseWriter, r *http.Request) {})   // Post new status
-				API.Delete("/", func(w http.ResponseWriter, r *http.Request) {}) // Delete status
+				API.Get("/", serverPlayer)          // Get current status
+				API.Post("/", serverPlayerAction)   // Post new status
+				API.Delete("/", serverPlayerRevert) // Delete status
 			})
 		})
 
+		// Bedrock allowlist.json
+		API.Route("/allowlist", func(API chi.Router) {
+			API.Use(bedrockOnly)
+			API.Get("/", serverAllowlist)
+			API.Post("/", serverAllowlistAdd)
+			API.Delete("/{player}", serverAllowlistRemove)
+		})
+
+		// Bedrock permissions.json
+		API.Route("/operators", func(API chi.Router) {
+			API.Use(bedrockOnly)
+			API.Get("/", serverOperators)
+			API.Post("/", serverOperatorsSet)
+			API.Delete("/{xuid:[0-9]+}", serverOperatorsRemove)
+		})
+
 		// Backup
 		API.Route("/backup", func(API chi.Router) {
 			// Get all backups
-			API.Get("/", func(w http.ResponseWriter, r *http.Request) {})
+			API.Get("/", serverBackups)
 
 			// Create new backup
-			API.Post("/", func(w http.ResponseWriter, r *http.Request) {})
+			API.Post("/", serverBackupCreate)
 
 			// Download backup
-			API.Get("/{id:[0-9]+}", func(w http.ResponseWriter, r *http.Request) {})
+			API.Get("/{backupID:[0-9]+}", serverBackupDownload)
 
 			// Delete backup
-			API.Delete("/{id:[0-9]+}", func(w http.ResponseWriter, r *http.Request) {})
+			API.Delete("/{backupID:[0-9]+}", serverBackupDelete)
+
+			// Restore backup to server
+			API.Post("/{backupID:[0-9]+}/restore", serverBackupRestore)
+
+			// Restore backup to new server
+			API.Post("/{backupID:[0-9]+}/clone", serverBackupClone)
+
+			// Scheduled backups runs
+			API.Get("/jobs", serverBackupJobs)
+
+			// Get and set backups storage, incremental backups, schedule and retention
+			API.Get("/config", serverBackupConfig)
+			API.Put("/config", serverBackupConfigSet)
+
+			// Check backups archives and chunks
+			API.Get("/fsck", serverBackupFsck)
+		})
+
+		// Upgrade server version with backup and rollback
+		API.Post("/upgrade", serverUpgrade)
+
+		// Update channel and new versions check
+		API.Route("/update", func(API chi.Router) {
+			API.Get("/", serverUpdatePolicy)      // Get policy and available version
+			API.Put("/", serverUpdatePolicySet)   // Set policy
+			API.Post("/check", serverUpdateCheck) // Check now, upgrade if policy allow
+		})
+
+		// Server files, paths in "path" query are relative to server directory
+		API.Route("/files", func(API chi.Router) {
+			API.Get("/", serverFiles)              // List directory or download file
+			API.Put("/", serverFileWrite)          // Write body to file
+			API.Delete("/", serverFileRemove)      // Remove, "recursive=true" to directories with files
+			API.Post("/mkdir", serverFileMkdir)    // Make directory
+			API.Post("/rename", serverFileRename)  // Rename or move
+			API.Post("/upload", serverFilesUpload) // Multipart upload to directory
+			API.Get("/usage", serverFilesUsage)    // Disk usage and quota
+
+			// Resumable uploads
+			API.Post("/uploads", serverUploadCreate)
+			API.Route("/uploads/{uploadID}", func(API chi.Router) {
+				API.Get("/", serverUpload)          // Upload status and offset
+				API.Patch("/", serverUploadWrite)   // Append part in "Upload-Offset"
+				API.Delete("/", serverUploadCancel) // Cancel upload
+			})
+		})
+
+		// Server worlds
+		API.Route("/worlds", func(API chi.Router) {
+			API.Get("/", serverWorlds)       // List worlds
+			API.Post("/", serverWorldImport) // Import .mcworld or .zip, "name" query to world name
+
+			API.Route("/{world}", func(API chi.Router) {
+				API.Get("/", serverWorldExport)                // Download world
+				API.Delete("/", serverWorldDelete)             // Backup and delete world
+				API.Post("/activate", serverWorldSwitch)       // Set level-name
+				API.Post("/regenerate", serverWorldRegenerate) // Backup and make new world with seed
+			})
+		})
+
+		// Bedrock behavior and resource packs, changes apply on server restart
+		API.Route("/addons", func(API chi.Router) {
+			API.Use(bedrockOnly)
+			API.Get("/", serverAddons)               // List installed packs
+			API.Post("/", serverAddonInstall)        // Install .mcpack or .mcaddon
+			API.Delete("/{pack}", serverAddonRemove) // Remove pack
+
+			API.Route("/worlds/{world}", func(API chi.Router) {
+				API.Get("/", serverWorldAddons)                // Enabled packs and conflicts
+				API.Post("/{pack}", serverWorldAddonEnable)    // Enable with dependencies
+				API.Delete("/{pack}", serverWorldAddonDisable) // Disable
+			})
+		})
+
+		// Java plugins and mods, changes apply on server restart
+		API.Route("/plugins", func(API chi.Router) {
+			API.Use(javaOnly)
+			API.Get("/", serverPlugins)        // List plugins and mods with issues
+			API.Post("/", serverPluginInstall) // Install or upgrade jar, "name" query to file name
+
+			API.Route("/{kind}/{file}", func(API chi.Router) {
+				API.Delete("/", serverPluginDelete)       // Delete jar
+				API.Post("/enable", serverPluginEnable)   // Enable jar
+				API.Post("/disable", serverPluginDisable) // Rename to .disabled
+			})
+		})
+
+		// Scheduled tasks
+		API.Route("/schedules", func(API chi.Router) {
+			API.Get("/", serverTasks)       // List tasks
+			API.Post("/", serverTaskCreate) // Create new task
+
+			API.Route("/{taskID:[0-9]+}", func(API chi.Router) {
+				API.Get("/", serverTask)          // Get task
+				API.Put("/", serverTaskUpdate)    // Update task
+				API.Delete("/", serverTaskDelete) // Delete task and history
+				API.Get("/runs", serverTaskRuns)  // Task history
+				API.Post("/run", serverTaskRun)   // Run task now
+			})
 		})
 	})
 
@@ -292,6 +452,13 @@ func init() {
 		})
 	})
 
+	// Softwares versions
+	API.Route("/versions", func(API chi.Router) {
+		API.Get("/", softwares)                           // Softwares names
+		API.Get("/{software}", softwareVersions)          // Versions, newest first
+		API.Get("/{software}/{version}", softwareVersion) // Resolve "latest", "preview" or version
+	})
+
 	// Get user info
 	API.Get("/user", func(w http.ResponseWriter, r *http.Request) {
 		user := User(r.Context())
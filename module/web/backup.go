@@ -0,0 +1,340 @@
+package web
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+	"sirherobrine23.com.br/go-bds/bds/module/backup"
+	"sirherobrine23.com.br/go-bds/bds/module/db"
+	"sirherobrine23.com.br/go-bds/bds/module/server"
+)
+
+// Check if backups is configured
+func backupManager(w http.ResponseWriter, r *http.Request) *backup.Manager {
+	manager := Backups(r.Context())
+	if manager == nil {
+		jsonResponse(w, http.StatusInternalServerError, map[string]string{
+			"error":   "backups",
+			"message": "invalid server configuration or caller, check implementaion",
+		})
+	}
+	return manager
+}
+
+// Get backup from URL and check if is from server in context
+func serverBackup(w http.ResponseWriter, r *http.Request) *server.ServerBackup {
+	backupID, _ := strconv.ParseInt(chi.URLParam(r, "backupID"), 10, 64)
+	mcBackup, err := Database(r.Context()).ServerBackup(backupID)
+	if err != nil {
+		switch err {
+		case db.ErrBackupNotExists:
+			jsonResponse(w, http.StatusNotFound, map[string]string{"error": "backup not found"})
+		default:
+			jsonResponse(w, http.StatusInternalServerError, map[string]string{
+				"error":   "internal error",
+				"message": err.Error(),
+			})
+		}
+		return nil
+	} else if mcBackup.ServerID != Server(r.Context()).ID {
+		jsonResponse(w, http.StatusNotFound, map[string]string{"error": "backup not found"})
+		return nil
+	}
+	return mcBackup
+}
+
+// List server backups
+func serverBackups(w http.ResponseWriter, r *http.Request) {
+	backups, err := Database(r.Context()).ServerBackups(Server(r.Context()).ID)
+	if err != nil {
+		jsonResponse(w, http.StatusInternalServerError, map[string]string{
+			"error":   "internal error",
+			"message": err.Error(),
+		})
+		return
+	}
+	jsonResponse(w, http.StatusOK, backups)
+}
+
+// Create new backup
+func serverBackupCreate(w http.ResponseWriter, r *http.Request) {
+	if !HasPermission(r.Context(), server.Edit) {
+		jsonResponse(w, http.StatusForbidden, map[string]string{"error": "permission", "message": "you dont have permission to access this route"})
+		return
+	}
+
+	manager := backupManager(w, r)
+	if manager == nil {
+		return
+	}
+
+	mcBackup, err := manager.Create(Server(r.Context()))
+	if err != nil {
+		switch err {
+		case backup.ErrBackupRunning:
+			jsonResponse(w, http.StatusConflict, map[string]string{"error": "backup running", "message": err.Error()})
+		case backup.ErrNoFiles:
+			jsonResponse(w, http.StatusBadRequest, map[string]string{"error": "no files", "message": err.Error()})
+		default:
+			jsonResponse(w, http.StatusInternalServerError, map[string]string{
+				"error":   "internal error",
+				"message": err.Error(),
+			})
+		}
+		return
+	}
+	jsonResponse(w, http.StatusCreated, mcBackup)
+}
+
+// Download backup archive, support Range requests
+func serverBackupDownload(w http.ResponseWriter, r *http.Request) {
+	manager := backupManager(w, r)
+	if manager == nil {
+		return
+	}
+	mcBackup := serverBackup(w, r)
+	if mcBackup == nil {
+		return
+	}
+
+	file, err := manager.Open(mcBackup)
+	if err != nil {
+		jsonResponse(w, http.StatusInternalServerError, map[string]string{
+			"error":   "internal error",
+			"message": err.Error(),
+		})
+		return
+	}
+	defer file.Close()
+
+	w.Header().Set("Content-Type", "application/zip")
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=\"%s.zip\"", mcBackup.UUID))
+	http.ServeContent(w, r, mcBackup.UUID+".zip", mcBackup.CreateAt, file)
+}
+
+// Delete backup archive and record
+func serverBackupDelete(w http.ResponseWriter, r *http.Request) {
+	if !HasPermission(r.Context(), server.Edit) {
+		jsonResponse(w, http.StatusForbidden, map[string]string{"error": "permission", "message": "you dont have permission to access this route"})
+		return
+	}
+
+	manager := backupManager(w, r)
+	if manager == nil {
+		return
+	}
+	mcBackup := serverBackup(w, r)
+	if mcBackup == nil {
+		return
+	}
+
+	if err := manager.Delete(mcBackup); err != nil {
+		jsonResponse(w, http.StatusInternalServerError, map[string]string{
+			"error":   "internal error",
+			"message": err.Error(),
+		})
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// Body to clone backup to new server
+type BackupClone struct {
+	Name string `json:"name"` // New server name, random if empty
+}
+
+// Restore backup to server, query force=true restore backups from newer versions
+func serverBackupRestore(w http.ResponseWriter, r *http.Request) {
+	if !HasPermission(r.Context(), server.Edit) {
+		jsonResponse(w, http.StatusForbidden, map[string]string{"error": "permission", "message": "you dont have permission to access this route"})
+		return
+	}
+
+	manager := backupManager(w, r)
+	if manager == nil {
+		return
+	}
+	mcBackup := serverBackup(w, r)
+	if mcBackup == nil {
+		return
+	}
+
+	force, _ := strconv.ParseBool(r.URL.Query().Get("force"))
+	result, err := manager.Restore(mcBackup, Server(r.Context()), force)
+	if err != nil {
+		restoreError(w, err)
+		return
+	}
+	jsonResponse(w, http.StatusOK, result)
+}
+
+// Create new server to user from backup
+func serverBackupClone(w http.ResponseWriter, r *http.Request) {
+	if !HasPermission(r.Context(), server.Edit) {
+		jsonResponse(w, http.StatusForbidden, map[string]string{"error": "permission", "message": "you dont have permission to access this route"})
+		return
+	}
+
+	manager := backupManager(w, r)
+	if manager == nil {
+		return
+	}
+	mcBackup := serverBackup(w, r)
+	if mcBackup == nil {
+		return
+	}
+
+	var body BackupClone
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil && err != io.EOF {
+		jsonResponse(w, http.StatusBadRequest, map[string]string{"error": "invalid json", "message": err.Error()})
+		return
+	}
+
+	user := User(r.Context())
+	newServer, err := Database(r.Context()).CreateServer(user, &server.Server{
+		Owner:    user.UserID,
+		Name:     body.Name,
+		Software: mcBackup.Software,
+		Version:  mcBackup.Version,
+	})
+	if err != nil {
+		jsonResponse(w, http.StatusInternalServerError, map[string]string{
+			"error":   "internal error",
+			"message": err.Error(),
+		})
+		return
+	}
+
+	result, err := manager.Restore(mcBackup, newServer, false)
+	if err != nil {
+		// Remove server created to clone
+		if runner := Runner(r.Context()); runner != nil {
+			os.RemoveAll(runner.Dir(newServer.ID))
+		}
+		Database(r.Context()).DeleteServer(newServer)
+		restoreError(w, err)
+		return
+	}
+	jsonResponse(w, http.StatusCreated, map[string]any{"server": newServer, "warnings": result.Warnings})
+}
+
+func restoreError(w http.ResponseWriter, err error) {
+	switch err {
+	case backup.ErrSoftwareMismatch, backup.ErrInvalidArchive:
+		jsonResponse(w, http.StatusBadRequest, map[string]string{"error": "invalid backup", "message": err.Error()})
+	case backup.ErrNewerVersion:
+		jsonResponse(w, http.StatusConflict, map[string]string{"error": "newer version", "message": "backup is from newer version, use force=true to restore anyway"})
+	case backup.ErrBackupRunning:
+		jsonResponse(w, http.StatusConflict, map[string]string{"error": "backup running", "message": err.Error()})
+	default:
+		jsonResponse(w, http.StatusInternalServerError, map[string]string{
+			"error":   "internal error",
+			"message": err.Error(),
+		})
+	}
+}
+
+// Server backups config
+type BackupConfig struct {
+	server.BackupConfig
+	Default   string   `json:"default"`   // Global storage
+	Available []string `json:"available"` // Storages configured in instance
+}
+
+// Get server backups config
+func serverBackupConfig(w http.ResponseWriter, r *http.Request) {
+	manager := backupManager(w, r)
+	if manager == nil {
+		return
+	}
+
+	config, err := Database(r.Context()).BackupConfig(Server(r.Context()).ID)
+	if err != nil {
+		jsonResponse(w, http.StatusInternalServerError, map[string]string{
+			"error":   "internal error",
+			"message": err.Error(),
+		})
+		return
+	}
+	jsonResponse(w, http.StatusOK, BackupConfig{BackupConfig: *config, Default: manager.DefaultStorage, Available: manager.StorageNames()})
+}
+
+// Set server backups config, new backups are saved with new config and old backups keep in old storage
+func serverBackupConfigSet(w http.ResponseWriter, r *http.Request) {
+	if !HasPermission(r.Context(), server.Edit) {
+		jsonResponse(w, http.StatusForbidden, map[string]string{"error": "permission", "message": "you dont have permission to access this route"})
+		return
+	}
+
+	manager := backupManager(w, r)
+	if manager == nil {
+		return
+	}
+
+	var body BackupConfig
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		jsonResponse(w, http.StatusBadRequest, map[string]string{"error": "invalid json", "message": err.Error()})
+		return
+	} else if body.Storage != "" {
+		if _, err := manager.Storage(body.Storage); err != nil {
+			jsonResponse(w, http.StatusBadRequest, map[string]string{"error": "invalid storage", "message": err.Error()})
+			return
+		}
+	}
+	if body.KeepLast < 0 || body.KeepDaily < 0 || body.KeepWeekly < 0 {
+		jsonResponse(w, http.StatusBadRequest, map[string]string{"error": "invalid retention", "message": "keep values cannot be negative"})
+		return
+	}
+
+	config := &body.BackupConfig
+	config.ServerID = Server(r.Context()).ID
+	if err := Database(r.Context()).SetBackupConfig(config); err != nil {
+		jsonResponse(w, http.StatusInternalServerError, map[string]string{
+			"error":   "internal error",
+			"message": err.Error(),
+		})
+		return
+	}
+	jsonResponse(w, http.StatusOK, BackupConfig{BackupConfig: *config, Default: manager.DefaultStorage, Available: manager.StorageNames()})
+}
+
+// Scheduled backups runs, query limit to max jobs, default 50
+func serverBackupJobs(w http.ResponseWriter, r *http.Request) {
+	limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
+	if limit <= 0 {
+		limit = 50
+	}
+
+	jobs, err := Database(r.Context()).BackupJobs(Server(r.Context()).ID, limit)
+	if err != nil {
+		jsonResponse(w, http.StatusInternalServerError, map[string]string{
+			"error":   "internal error",
+			"message": err.Error(),
+		})
+		return
+	}
+	jsonResponse(w, http.StatusOK, jobs)
+}
+
+// Check server backups archives and chunks
+func serverBackupFsck(w http.ResponseWriter, r *http.Request) {
+	manager := backupManager(w, r)
+	if manager == nil {
+		return
+	}
+
+	report, err := manager.Fsck(Server(r.Context()).ID)
+	if err != nil {
+		jsonResponse(w, http.StatusInternalServerError, map[string]string{
+			"error":   "internal error",
+			"message": err.Error(),
+		})
+		return
+	}
+	jsonResponse(w, http.StatusOK, report)
+}
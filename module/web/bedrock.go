@@ -0,0 +1,191 @@
+package web
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+	"sirherobrine23.com.br/go-bds/bds/module/bedrock"
+	"sirherobrine23.com.br/go-bds/bds/module/server"
+)
+
+// Body to add operator
+type OperatorBody struct {
+	XUID       string             `json:"xuid"`       // Player XUID, if empty find by name
+	Name       string             `json:"name"`       // Player gamertag, used to find XUID
+	Permission bedrock.Permission `json:"permission"` // visitor, member or operator
+}
+
+// Allow only bedrock servers and users with edit permission to change files
+func bedrockOnly(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !strings.EqualFold(Server(r.Context()).Software, "bedrock") {
+			jsonResponse(w, http.StatusBadRequest, map[string]string{"error": "software", "message": "only avaible to bedrock servers"})
+			return
+		} else if Runner(r.Context()) == nil {
+			jsonResponse(w, http.StatusInternalServerError, map[string]string{
+				"error":   "runner",
+				"message": "invalid server configuration or caller, check implementaion",
+			})
+			return
+		} else if r.Method != http.MethodGet && !HasPermission(r.Context(), server.Edit) {
+			jsonResponse(w, http.StatusForbidden, map[string]string{"error": "permission", "message": "you dont have permission to access this route"})
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// Send reload command if server running, return true if command sent
+func reloadCommand(r *http.Request, command string) bool {
+	if proc := Runner(r.Context()).Process(Server(r.Context()).ID); proc != nil {
+		return proc.SendCommand(command) == nil
+	}
+	return false
+}
+
+func serverDir(r *http.Request) string {
+	return Runner(r.Context()).Dir(Server(r.Context()).ID)
+}
+
+// Get allowlist.json
+func serverAllowlist(w http.ResponseWriter, r *http.Request) {
+	list, err := bedrock.ReadAllowlist(serverDir(r))
+	if err != nil {
+		jsonResponse(w, http.StatusInternalServerError, map[string]string{
+			"error":   "internal error",
+			"message": err.Error(),
+		})
+		return
+	}
+	jsonResponse(w, http.StatusOK, list)
+}
+
+// Add or update player in allowlist.json
+func serverAllowlistAdd(w http.ResponseWriter, r *http.Request) {
+	var entry bedrock.AllowlistEntry
+	if err := json.NewDecoder(r.Body).Decode(&entry); err != nil {
+		jsonResponse(w, http.StatusBadRequest, map[string]string{"error": "invalid json", "message": err.Error()})
+		return
+	} else if entry.Name = strings.TrimSpace(entry.Name); entry.Name == "" {
+		jsonResponse(w, http.StatusBadRequest, map[string]string{"error": "invalid name", "message": "set player name"})
+		return
+	}
+
+	// Fill XUID if player joined before
+	if mcServer := Server(r.Context()); entry.XUID == "" {
+		if player, err := Database(r.Context()).Player(mcServer.ID, entry.Name); err == nil {
+			entry.XUID = player.XUID
+		}
+	}
+
+	list, err := bedrock.UpdateAllowlist(serverDir(r), func(list bedrock.Allowlist) (bedrock.Allowlist, error) {
+		return list.Add(&entry), nil
+	})
+	if err != nil {
+		jsonResponse(w, http.StatusInternalServerError, map[string]string{
+			"error":   "internal error",
+			"message": err.Error(),
+		})
+		return
+	}
+	jsonResponse(w, http.StatusOK, map[string]any{"allowlist": list, "reloaded": reloadCommand(r, bedrock.ReloadAllowlist)})
+}
+
+// Remove player from allowlist.json by name or XUID
+func serverAllowlistRemove(w http.ResponseWriter, r *http.Request) {
+	list, err := bedrock.UpdateAllowlist(serverDir(r), func(list bedrock.Allowlist) (bedrock.Allowlist, error) {
+		return list.Remove(chi.URLParam(r, "player"))
+	})
+	if err != nil {
+		switch err {
+		case bedrock.ErrEntryNotExists:
+			jsonResponse(w, http.StatusNotFound, map[string]string{"error": "player not found"})
+		default:
+			jsonResponse(w, http.StatusInternalServerError, map[string]string{
+				"error":   "internal error",
+				"message": err.Error(),
+			})
+		}
+		return
+	}
+	jsonResponse(w, http.StatusOK, map[string]any{"allowlist": list, "reloaded": reloadCommand(r, bedrock.ReloadAllowlist)})
+}
+
+// Get permissions.json
+func serverOperators(w http.ResponseWriter, r *http.Request) {
+	list, err := bedrock.ReadPermissions(serverDir(r))
+	if err != nil {
+		jsonResponse(w, http.StatusInternalServerError, map[string]string{
+			"error":   "internal error",
+			"message": err.Error(),
+		})
+		return
+	}
+	jsonResponse(w, http.StatusOK, list)
+}
+
+// Set player permission in permissions.json
+func serverOperatorsSet(w http.ResponseWriter, r *http.Request) {
+	var body OperatorBody
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		jsonResponse(w, http.StatusBadRequest, map[string]string{"error": "invalid json", "message": err.Error()})
+		return
+	} else if body.Permission == "" {
+		body.Permission = bedrock.Operator
+	}
+
+	// Find XUID from players seen in server or allowlist
+	if body.XUID == "" && body.Name != "" {
+		mcServer := Server(r.Context())
+		if player, err := Database(r.Context()).Player(mcServer.ID, body.Name); err == nil {
+			body.XUID = player.XUID
+		} else if list, err := bedrock.ReadAllowlist(serverDir(r)); err == nil {
+			if entry := list.Find(body.Name); entry != nil {
+				body.XUID = entry.XUID
+			}
+		}
+	}
+	if body.XUID == "" {
+		jsonResponse(w, http.StatusBadRequest, map[string]string{"error": "unknown xuid", "message": "set xuid or name of player joined in server"})
+		return
+	}
+
+	list, err := bedrock.UpdatePermissions(serverDir(r), func(list bedrock.Permissions) (bedrock.Permissions, error) {
+		return list.Set(body.XUID, body.Permission)
+	})
+	if err != nil {
+		switch err {
+		case bedrock.ErrInvalidPermission:
+			jsonResponse(w, http.StatusBadRequest, map[string]string{"error": "invalid permission", "message": err.Error()})
+		default:
+			jsonResponse(w, http.StatusInternalServerError, map[string]string{
+				"error":   "internal error",
+				"message": err.Error(),
+			})
+		}
+		return
+	}
+	jsonResponse(w, http.StatusOK, map[string]any{"operators": list, "reloaded": reloadCommand(r, bedrock.ReloadPermissions)})
+}
+
+// Remove XUID from permissions.json
+func serverOperatorsRemove(w http.ResponseWriter, r *http.Request) {
+	list, err := bedrock.UpdatePermissions(serverDir(r), func(list bedrock.Permissions) (bedrock.Permissions, error) {
+		return list.Remove(chi.URLParam(r, "xuid"))
+	})
+	if err != nil {
+		switch err {
+		case bedrock.ErrEntryNotExists:
+			jsonResponse(w, http.StatusNotFound, map[string]string{"error": "operator not found"})
+		default:
+			jsonResponse(w, http.StatusInternalServerError, map[string]string{
+				"error":   "internal error",
+				"message": err.Error(),
+			})
+		}
+		return
+	}
+	jsonResponse(w, http.StatusOK, map[string]any{"operators": list, "reloaded": reloadCommand(r, bedrock.ReloadPermissions)})
+}
@@ -0,0 +1,171 @@
+package web
+
+import (
+	"encoding/json"
+	"net/http"
+	"path/filepath"
+	"slices"
+	"strconv"
+
+	"sirherobrine23.com.br/go-bds/bds/module/properties"
+	"sirherobrine23.com.br/go-bds/bds/module/rcon"
+	"sirherobrine23.com.br/go-bds/bds/module/server"
+)
+
+// Response to server config routes
+type ServerConfig struct {
+	Properties      map[string]string `json:"properties"`                 // server.properties keys
+	Schema          []properties.Key  `json:"schema,omitempty"`           // Known keys to server software
+	RestartRequired bool              `json:"restart_required,omitempty"` // Changed keys require restart
+	Restarted       bool              `json:"restarted,omitempty"`        // Server restarted after change
+}
+
+// Return server.properties path, write error if runner not configured
+func propertiesPath(w http.ResponseWriter, r *http.Request) string {
+	manager := Runner(r.Context())
+	if manager == nil {
+		jsonResponse(w, http.StatusInternalServerError, map[string]string{
+			"error":   "runner",
+			"message": "invalid server configuration or caller, check implementaion",
+		})
+		return ""
+	}
+	return filepath.Join(manager.Dir(Server(r.Context()).ID), properties.FileName)
+}
+
+// Get server.properties with schema to server software
+func serverConfig(w http.ResponseWriter, r *http.Request) {
+	name := propertiesPath(w, r)
+	if name == "" {
+		return
+	}
+
+	file, err := properties.Open(name)
+	if err != nil {
+		jsonResponse(w, http.StatusInternalServerError, map[string]string{
+			"error":   "internal error",
+			"message": err.Error(),
+		})
+		return
+	}
+
+	mcServer := Server(r.Context())
+	file.SetDefaults(mcServer.Software) // Only in response, file is not changed
+	rcon.Redact(file)
+	jsonResponse(w, http.StatusOK, ServerConfig{
+		Properties: file.Map(),
+		Schema:     properties.Schema(mcServer.Software),
+	})
+}
+
+// Replace all keys in server.properties, keys not in body are removed
+func serverConfigReplace(w http.ResponseWriter, r *http.Request) {
+	updateConfig(w, r, true)
+}
+
+// Update only keys in body, null value remove key
+func serverConfigPatch(w http.ResponseWriter, r *http.Request) {
+	updateConfig(w, r, false)
+}
+
+func updateConfig(w http.ResponseWriter, r *http.Request, replace bool) {
+	if !HasPermission(r.Context(), server.Edit) {
+		jsonResponse(w, http.StatusForbidden, map[string]string{"error": "permission", "message": "you dont have permission to access this route"})
+		return
+	}
+
+	name := propertiesPath(w, r)
+	if name == "" {
+		return
+	}
+
+	var changes map[string]*string
+	if err := json.NewDecoder(r.Body).Decode(&changes); err != nil {
+		jsonResponse(w, http.StatusBadRequest, map[string]string{"error": "invalid json", "message": err.Error()})
+		return
+	}
+
+	mcServer := Server(r.Context())
+	for key, value := range changes {
+		if value == nil {
+			continue
+		} else if err := properties.Validate(mcServer.Software, key, *value); err != nil {
+			jsonResponse(w, http.StatusBadRequest, map[string]string{"error": "invalid value", "message": err.Error()})
+			return
+		}
+	}
+
+	file, err := properties.Open(name)
+	if err != nil {
+		jsonResponse(w, http.StatusInternalServerError, map[string]string{
+			"error":   "internal error",
+			"message": err.Error(),
+		})
+		return
+	}
+
+	// RCON keys are set by server on start
+	for key, value := range changes {
+		if current, exists := file.Get(key); rcon.Managed(key) && ((value == nil && exists) || (value != nil && (!exists || current != *value))) {
+			jsonResponse(w, http.StatusForbidden, map[string]string{"error": "managed key", "message": rcon.ErrManagedKey.Error()})
+			return
+		}
+	}
+
+	var response ServerConfig
+	changed := map[string]bool{}
+	if replace {
+		for _, key := range file.Keys() {
+			if rcon.Managed(key) {
+				continue
+			} else if value, ok := changes[key]; !ok || value == nil {
+				file.Delete(key)
+				changed[key] = true
+			}
+		}
+	}
+	for key, value := range changes {
+		old, exists := file.Get(key)
+		switch {
+		case value == nil && exists:
+			file.Delete(key)
+		case value != nil && (!exists || old != *value):
+			file.Set(key, *value)
+		default:
+			continue
+		}
+		changed[key] = true
+	}
+
+	if err = file.Save(name); err != nil {
+		jsonResponse(w, http.StatusInternalServerError, map[string]string{
+			"error":   "internal error",
+			"message": err.Error(),
+		})
+		return
+	}
+
+	schema := properties.Schema(mcServer.Software)
+	rcon.Redact(file)
+	response.Properties = file.Map()
+	response.RestartRequired = slices.ContainsFunc(schema, func(key properties.Key) bool { return key.Restart && changed[key.Name] })
+
+	// Restart server if requested and running
+	if restart, _ := strconv.ParseBool(r.URL.Query().Get("restart")); restart && len(changed) > 0 {
+		if manager := Runner(r.Context()); manager.Process(mcServer.ID) != nil {
+			if _, err := manager.Restart(mcServer, 0); err != nil {
+				if portsError(w, err) {
+					return
+				}
+				jsonResponse(w, http.StatusInternalServerError, map[string]string{
+					"error":   "restart",
+					"message": err.Error(),
+				})
+				return
+			}
+			response.Restarted, response.RestartRequired = true, false
+		}
+	}
+
+	jsonResponse(w, http.StatusOK, response)
+}
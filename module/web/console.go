@@ -0,0 +1,116 @@
+package web
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/coder/websocket"
+	"github.com/coder/websocket/wsjson"
+	"sirherobrine23.com.br/go-bds/bds/module/runner"
+	"sirherobrine23.com.br/go-bds/bds/module/server"
+)
+
+var ConsoleWriteTimeout = time.Second * 10 // Time to client receive line before disconnect
+
+// Websocket subprotocol to send token, browsers connect with ["bearer", "<token>"] subprotocols
+const TokenProtocol = "bearer"
+
+// Get token from websocket subprotocols
+func websocketToken(r *http.Request) (string, bool) {
+	var protocols []string
+	for _, value := range r.Header.Values("Sec-WebSocket-Protocol") {
+		for protocol := range strings.SplitSeq(value, ",") {
+			protocols = append(protocols, strings.TrimSpace(protocol))
+		}
+	}
+	if len(protocols) == 2 && protocols[0] == TokenProtocol && protocols[1] != "" {
+		return protocols[1], true
+	}
+	return "", false
+}
+
+// Command sent by websocket client
+type ConsoleCommand struct {
+	Command string `json:"command"`
+}
+
+// Stream server console to websocket and receive commands
+func serverConsole(w http.ResponseWriter, r *http.Request) {
+	manager, mcServer := Runner(r.Context()), Server(r.Context())
+	if manager == nil {
+		jsonResponse(w, http.StatusInternalServerError, map[string]string{
+			"error":   "runner",
+			"message": "invalid server configuration or caller, check implementaion",
+		})
+		return
+	}
+
+	proc := manager.Process(mcServer.ID)
+	if proc == nil {
+		jsonResponse(w, http.StatusConflict, map[string]string{"error": "server not running"})
+		return
+	}
+
+	conn, err := websocket.Accept(w, r, &websocket.AcceptOptions{Subprotocols: []string{TokenProtocol}})
+	if err != nil {
+		return // Accept write error to client
+	}
+	defer conn.CloseNow()
+
+	canSend := HasPermission(r.Context(), server.Console)
+	sub := proc.Output.Subscribe(0)
+	defer sub.Close()
+
+	ctx, cancel := context.WithCancel(r.Context())
+	defer cancel()
+
+	// Read commands from client
+	go func() {
+		defer cancel()
+		for {
+			var cmd ConsoleCommand
+			if err := wsjson.Read(ctx, conn, &cmd); err != nil {
+				return
+			} else if !canSend {
+				consoleWrite(ctx, conn, map[string]string{"error": "permission", "message": "you dont have permission to send commands"})
+				continue
+			} else if err := proc.SendCommand(cmd.Command); err != nil {
+				consoleWrite(ctx, conn, map[string]string{"error": "command", "message": err.Error()})
+			}
+		}
+	}()
+
+	for _, line := range sub.Replay {
+		if err := consoleWrite(ctx, conn, line); err != nil {
+			return
+		}
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case line, ok := <-sub.C:
+			if !ok {
+				switch sub.Err() {
+				case runner.ErrSlowConsumer:
+					conn.Close(websocket.StatusTryAgainLater, "client too slow")
+				default:
+					conn.Close(websocket.StatusNormalClosure, "server stopped")
+				}
+				return
+			}
+			if err := consoleWrite(ctx, conn, line); err != nil {
+				return
+			}
+		}
+	}
+}
+
+func consoleWrite(ctx context.Context, conn *websocket.Conn, body any) error {
+	ctx, cancel := context.WithTimeout(ctx, ConsoleWriteTimeout)
+	defer cancel()
+	return wsjson.Write(ctx, conn, body)
+}
@@ -0,0 +1,488 @@
+package web
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/http"
+	"os"
+	"path"
+	"strconv"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+	"sirherobrine23.com.br/go-bds/bds/module/files"
+	"sirherobrine23.com.br/go-bds/bds/module/properties"
+	"sirherobrine23.com.br/go-bds/bds/module/rcon"
+	"sirherobrine23.com.br/go-bds/bds/module/server"
+)
+
+// Check if files is configured
+func filesManager(w http.ResponseWriter, r *http.Request) *files.Manager {
+	manager := Files(r.Context())
+	if manager == nil {
+		jsonResponse(w, http.StatusInternalServerError, map[string]string{
+			"error":   "files",
+			"message": "invalid server configuration or caller, check implementaion",
+		})
+	}
+	return manager
+}
+
+// Open server directory from context, response with error if cannot open
+func serverFS(w http.ResponseWriter, r *http.Request) *files.FS {
+	manager := filesManager(w, r)
+	if manager == nil {
+		return nil
+	}
+
+	fsys, err := manager.Open(Server(r.Context()).ID)
+	if err != nil {
+		filesError(w, err)
+		return nil
+	}
+	return fsys
+}
+
+// Check edit permission to change files
+func filesEdit(w http.ResponseWriter, r *http.Request) bool {
+	if !HasPermission(r.Context(), server.Edit) {
+		jsonResponse(w, http.StatusForbidden, map[string]string{"error": "permission", "message": "you dont have permission to access this route"})
+		return false
+	}
+	return true
+}
+
+// Check if name is server.properties, RCON keys in it are managed by server
+func isProperties(fsys *files.FS, name string) bool {
+	if name, err := files.Clean(name); err == nil && name == properties.FileName {
+		return true
+	}
+	return fsys.SameFile(name, properties.FileName)
+}
+
+// Read server.properties in server directory, empty file if not exists
+func openProperties(fsys *files.FS) (*properties.File, error) {
+	file, err := fsys.Open(properties.FileName)
+	if errors.Is(err, fs.ErrNotExist) {
+		return properties.New(), nil
+	} else if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	return properties.Parse(file)
+}
+
+// Response files errors
+func filesError(w http.ResponseWriter, err error) {
+	switch {
+	case files.IsEscape(err):
+		jsonResponse(w, http.StatusForbidden, map[string]string{"error": "path", "message": "path is outside server directory"})
+	case errors.Is(err, fs.ErrNotExist), err == files.ErrUploadNotExists:
+		jsonResponse(w, http.StatusNotFound, map[string]string{"error": "not found", "message": err.Error()})
+	case err == files.ErrExists, err == files.ErrUploadOffset:
+		jsonResponse(w, http.StatusConflict, map[string]string{"error": "conflict", "message": err.Error()})
+	case err == files.ErrQuota, err == files.ErrFileSize, err == files.ErrUploadSize:
+		jsonResponse(w, http.StatusRequestEntityTooLarge, map[string]string{"error": "quota", "message": err.Error()})
+	case err == rcon.ErrManagedKey:
+		jsonResponse(w, http.StatusForbidden, map[string]string{"error": "managed key", "message": err.Error()})
+	case err == files.ErrTooManyUploads:
+		jsonResponse(w, http.StatusTooManyRequests, map[string]string{"error": "uploads", "message": err.Error()})
+	case err == files.ErrInvalidPath, err == files.ErrIsDir, err == files.ErrNotDir, err == files.ErrNotEmpty:
+		jsonResponse(w, http.StatusBadRequest, map[string]string{"error": "path", "message": err.Error()})
+	default:
+		jsonResponse(w, http.StatusInternalServerError, map[string]string{
+			"error":   "internal error",
+			"message": err.Error(),
+		})
+	}
+}
+
+// List directory or download file, "download=true" to save as attachment
+func serverFiles(w http.ResponseWriter, r *http.Request) {
+	fsys := serverFS(w, r)
+	if fsys == nil {
+		return
+	}
+	defer fsys.Close()
+
+	name := r.URL.Query().Get("path")
+	file, err := fsys.Open(name)
+	if err == files.ErrIsDir {
+		list, err := fsys.List(name)
+		if err != nil {
+			filesError(w, err)
+			return
+		}
+		jsonResponse(w, http.StatusOK, list)
+		return
+	} else if err != nil {
+		filesError(w, err)
+		return
+	}
+	defer file.Close()
+
+	info, err := file.Stat()
+	if err != nil {
+		filesError(w, err)
+		return
+	}
+	if r.URL.Query().Get("download") == "true" {
+		w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", info.Name()))
+	}
+	if isProperties(fsys, name) {
+		config, err := properties.Parse(file)
+		if err != nil {
+			filesError(w, err)
+			return
+		}
+		rcon.Redact(config)
+		var buff bytes.Buffer
+		config.WriteTo(&buff)
+		http.ServeContent(w, r, info.Name(), info.ModTime(), bytes.NewReader(buff.Bytes()))
+		return
+	}
+	http.ServeContent(w, r, info.Name(), info.ModTime(), file)
+}
+
+// Write body to file
+func serverFileWrite(w http.ResponseWriter, r *http.Request) {
+	if !filesEdit(w, r) {
+		return
+	}
+	fsys := serverFS(w, r)
+	if fsys == nil {
+		return
+	}
+	defer fsys.Close()
+
+	name := r.URL.Query().Get("path")
+	var body io.Reader = r.Body
+	if isProperties(fsys, name) {
+		old, err := openProperties(fsys)
+		if err != nil {
+			filesError(w, err)
+			return
+		}
+		config, err := properties.Parse(r.Body)
+		if err != nil {
+			jsonResponse(w, http.StatusBadRequest, map[string]string{"error": "invalid body", "message": err.Error()})
+			return
+		}
+		if level, ok := config.Get("level-name"); ok {
+			if err = properties.Validate(Server(r.Context()).Software, "level-name", level); err != nil {
+				jsonResponse(w, http.StatusBadRequest, map[string]string{"error": "invalid value", "message": err.Error()})
+				return
+			}
+		}
+		if err = rcon.KeepManaged(old, config); err != nil {
+			filesError(w, err)
+			return
+		}
+		var buff bytes.Buffer
+		config.WriteTo(&buff)
+		body = &buff
+	}
+	if _, err := fsys.Write(name, body); err != nil {
+		filesError(w, err)
+		return
+	}
+	entry, err := fsys.Stat(name)
+	if err != nil {
+		filesError(w, err)
+		return
+	}
+	jsonResponse(w, http.StatusOK, entry)
+}
+
+// Remove file or directory
+func serverFileRemove(w http.ResponseWriter, r *http.Request) {
+	if !filesEdit(w, r) {
+		return
+	}
+	fsys := serverFS(w, r)
+	if fsys == nil {
+		return
+	}
+	defer fsys.Close()
+
+	if err := fsys.Remove(r.URL.Query().Get("path"), r.URL.Query().Get("recursive") == "true"); err != nil {
+		filesError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// Make directory and parents
+func serverFileMkdir(w http.ResponseWriter, r *http.Request) {
+	if !filesEdit(w, r) {
+		return
+	}
+	fsys := serverFS(w, r)
+	if fsys == nil {
+		return
+	}
+	defer fsys.Close()
+
+	name := r.URL.Query().Get("path")
+	if err := fsys.MkdirAll(name); err != nil {
+		filesError(w, err)
+		return
+	}
+	entry, err := fsys.Stat(name)
+	if err != nil {
+		filesError(w, err)
+		return
+	}
+	jsonResponse(w, http.StatusCreated, entry)
+}
+
+// Body to rename file
+type FileRename struct {
+	From string `json:"from"`
+	To   string `json:"to"`
+}
+
+// Rename or move file or directory
+func serverFileRename(w http.ResponseWriter, r *http.Request) {
+	if !filesEdit(w, r) {
+		return
+	}
+
+	var body FileRename
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		jsonResponse(w, http.StatusBadRequest, map[string]string{"error": "invalid json", "message": err.Error()})
+		return
+	}
+
+	fsys := serverFS(w, r)
+	if fsys == nil {
+		return
+	}
+	defer fsys.Close()
+
+	if isProperties(fsys, body.From) || isProperties(fsys, body.To) {
+		filesError(w, rcon.ErrManagedKey)
+		return
+	} else if err := fsys.Rename(body.From, body.To); err != nil {
+		filesError(w, err)
+		return
+	}
+	entry, err := fsys.Stat(body.To)
+	if err != nil {
+		filesError(w, err)
+		return
+	}
+	jsonResponse(w, http.StatusOK, entry)
+}
+
+// Upload multipart files to directory in "path"
+func serverFilesUpload(w http.ResponseWriter, r *http.Request) {
+	if !filesEdit(w, r) {
+		return
+	}
+	reader, err := r.MultipartReader()
+	if err != nil {
+		jsonResponse(w, http.StatusBadRequest, map[string]string{"error": "invalid body", "message": err.Error()})
+		return
+	}
+
+	fsys := serverFS(w, r)
+	if fsys == nil {
+		return
+	}
+	defer fsys.Close()
+
+	dir := r.URL.Query().Get("path")
+	uploaded := []*files.Entry{}
+	for {
+		part, err := reader.NextPart()
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			jsonResponse(w, http.StatusBadRequest, map[string]string{"error": "invalid body", "message": err.Error()})
+			return
+		}
+
+		filename := part.FileName() // Only base name
+		if filename == "" || filename == "." || filename == ".." {
+			part.Close()
+			continue // Not file
+		}
+		name := path.Join(dir, filename)
+		if isProperties(fsys, name) {
+			part.Close()
+			filesError(w, rcon.ErrManagedKey)
+			return
+		}
+		_, err = fsys.Write(name, part)
+		part.Close()
+		if err != nil {
+			filesError(w, err)
+			return
+		}
+		if entry, err := fsys.Stat(name); err == nil {
+			uploaded = append(uploaded, entry)
+		}
+	}
+	jsonResponse(w, http.StatusCreated, uploaded)
+}
+
+// Server disk usage
+func serverFilesUsage(w http.ResponseWriter, r *http.Request) {
+	fsys := serverFS(w, r)
+	if fsys == nil {
+		return
+	}
+	defer fsys.Close()
+
+	used, err := fsys.Usage()
+	if err != nil {
+		filesError(w, err)
+		return
+	}
+	jsonResponse(w, http.StatusOK, map[string]int64{"used": used, "quota": Files(r.Context()).Quota})
+}
+
+// Body to start resumable upload
+type FileUpload struct {
+	Path string `json:"path"` // Target file
+	Size int64  `json:"size"` // Final file size
+}
+
+// Start resumable upload
+func serverUploadCreate(w http.ResponseWriter, r *http.Request) {
+	if !filesEdit(w, r) {
+		return
+	}
+	manager := filesManager(w, r)
+	if manager == nil {
+		return
+	}
+
+	var body FileUpload
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		jsonResponse(w, http.StatusBadRequest, map[string]string{"error": "invalid json", "message": err.Error()})
+		return
+	}
+
+	fsys := serverFS(w, r)
+	if fsys == nil {
+		return
+	}
+	managed := isProperties(fsys, body.Path)
+	fsys.Close()
+	if managed {
+		filesError(w, rcon.ErrManagedKey)
+		return
+	}
+
+	upload, err := manager.CreateUpload(Server(r.Context()).ID, body.Path, body.Size)
+	if err != nil {
+		filesError(w, err)
+		return
+	}
+	w.Header().Set("Upload-Offset", "0")
+	jsonResponse(w, http.StatusCreated, upload)
+}
+
+// Get upload offset to resume
+func serverUpload(w http.ResponseWriter, r *http.Request) {
+	manager := filesManager(w, r)
+	if manager == nil {
+		return
+	}
+
+	upload, err := manager.Upload(Server(r.Context()).ID, chi.URLParam(r, "uploadID"))
+	if err != nil {
+		filesError(w, err)
+		return
+	}
+	w.Header().Set("Upload-Offset", strconv.FormatInt(upload.Offset, 10))
+	jsonResponse(w, http.StatusOK, upload)
+}
+
+// Append body to upload, "Upload-Offset" header must be current offset
+func serverUploadWrite(w http.ResponseWriter, r *http.Request) {
+	if !filesEdit(w, r) {
+		return
+	}
+	manager := filesManager(w, r)
+	if manager == nil {
+		return
+	}
+
+	offset, err := strconv.ParseInt(r.Header.Get("Upload-Offset"), 10, 64)
+	if err != nil {
+		jsonResponse(w, http.StatusBadRequest, map[string]string{"error": "invalid offset", "message": "require Upload-Offset header with current upload offset"})
+		return
+	}
+
+	upload, err := manager.WriteUpload(Server(r.Context()).ID, chi.URLParam(r, "uploadID"), offset, r.Body)
+	if upload != nil {
+		w.Header().Set("Upload-Offset", strconv.FormatInt(upload.Offset, 10))
+	}
+	if err != nil {
+		filesError(w, err)
+		return
+	} else if upload.Done {
+		jsonResponse(w, http.StatusCreated, upload)
+		return
+	}
+	jsonResponse(w, http.StatusOK, upload)
+}
+
+// Cancel upload
+func serverUploadCancel(w http.ResponseWriter, r *http.Request) {
+	if !filesEdit(w, r) {
+		return
+	}
+	manager := filesManager(w, r)
+	if manager == nil {
+		return
+	}
+
+	if err := manager.CancelUpload(Server(r.Context()).ID, chi.URLParam(r, "uploadID")); err != nil {
+		filesError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// Save raw body or multipart file in field to temporary file, archives require random access.
+// Body is limited to files quota if configured.
+func spoolBody(w http.ResponseWriter, r *http.Request, field string) (*os.File, int64, bool) {
+	if manager := Files(r.Context()); manager != nil && manager.Quota > 0 {
+		r.Body = http.MaxBytesReader(w, r.Body, manager.Quota)
+	}
+	var body io.Reader = r.Body
+	if strings.HasPrefix(r.Header.Get("Content-Type"), "multipart/") {
+		file, _, err := r.FormFile(field)
+		if err != nil {
+			jsonResponse(w, http.StatusBadRequest, map[string]string{"error": "invalid body", "message": err.Error()})
+			return nil, 0, false
+		}
+		defer file.Close()
+		body = file
+	}
+
+	tmp, err := os.CreateTemp("", "upload-*.zip")
+	if err != nil {
+		jsonResponse(w, http.StatusInternalServerError, map[string]string{
+			"error":   "internal error",
+			"message": err.Error(),
+		})
+		return nil, 0, false
+	}
+	size, err := io.Copy(tmp, body)
+	if err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		jsonResponse(w, http.StatusBadRequest, map[string]string{"error": "invalid body", "message": err.Error()})
+		return nil, 0, false
+	}
+	return tmp, size, true
+}
@@ -0,0 +1,175 @@
+package web
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"sirherobrine23.com.br/go-bds/bds/module/logs"
+	"sirherobrine23.com.br/go-bds/bds/module/server"
+)
+
+// Check if logs storage is configured
+func logsStore(w http.ResponseWriter, r *http.Request) *logs.Store {
+	store := Logs(r.Context())
+	if store == nil {
+		jsonResponse(w, http.StatusInternalServerError, map[string]string{
+			"error":   "logs",
+			"message": "invalid server configuration or caller, check implementaion",
+		})
+	}
+	return store
+}
+
+// Search server logs with query: from, to (RFC3339), level (comma separated), q, run and limit
+func serverLogsSearch(w http.ResponseWriter, r *http.Request) {
+	store := logsStore(w, r)
+	if store == nil {
+		return
+	}
+
+	query := r.URL.Query()
+	filter := logs.Filter{Text: query.Get("q"), RunID: query.Get("run"), Limit: 1000}
+	for key, target := range map[string]*time.Time{"from": &filter.From, "to": &filter.To} {
+		if value := query.Get(key); value != "" {
+			var err error
+			if *target, err = time.Parse(time.RFC3339, value); err != nil {
+				jsonResponse(w, http.StatusBadRequest, map[string]string{"error": "invalid " + key, "message": err.Error()})
+				return
+			}
+		}
+	}
+	if levels := query.Get("level"); levels != "" {
+		filter.Levels = strings.Split(strings.ToLower(levels), ",")
+	}
+	if limit := query.Get("limit"); limit != "" {
+		var err error
+		if filter.Limit, err = strconv.Atoi(limit); err != nil {
+			jsonResponse(w, http.StatusBadRequest, map[string]string{"error": "invalid limit", "message": err.Error()})
+			return
+		}
+	}
+
+	entries, err := store.Search(Server(r.Context()).ID, filter)
+	if err != nil {
+		jsonResponse(w, http.StatusInternalServerError, map[string]string{
+			"error":   "internal error",
+			"message": err.Error(),
+		})
+		return
+	}
+	jsonResponse(w, http.StatusOK, entries)
+}
+
+// List server runs
+func serverLogsRuns(w http.ResponseWriter, r *http.Request) {
+	store := logsStore(w, r)
+	if store == nil {
+		return
+	}
+
+	runs, err := store.Runs(Server(r.Context()).ID)
+	if err != nil {
+		jsonResponse(w, http.StatusInternalServerError, map[string]string{
+			"error":   "internal error",
+			"message": err.Error(),
+		})
+		return
+	} else if runs == nil {
+		runs = []*logs.Run{}
+	}
+	jsonResponse(w, http.StatusOK, runs)
+}
+
+// Download run logs, json lines by default or plain text with ?format=text
+func serverLogsDownload(w http.ResponseWriter, r *http.Request) {
+	store := logsStore(w, r)
+	if store == nil {
+		return
+	}
+
+	mcServer, runID := Server(r.Context()), chi.URLParam(r, "run")
+	logFile, err := store.Open(mcServer.ID, runID)
+	if err != nil {
+		switch err {
+		case logs.ErrRunNotExists:
+			jsonResponse(w, http.StatusNotFound, map[string]string{"error": "run not found"})
+		default:
+			jsonResponse(w, http.StatusInternalServerError, map[string]string{
+				"error":   "internal error",
+				"message": err.Error(),
+			})
+		}
+		return
+	}
+	defer logFile.Close()
+
+	if r.URL.Query().Get("format") != "text" {
+		w.Header().Set("Content-Type", "application/x-ndjson")
+		w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=\"%d-%s.jsonl\"", mcServer.ID, runID))
+		io.Copy(w, logFile)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=\"%d-%s.log\"", mcServer.ID, runID))
+	scan, buff := bufio.NewScanner(logFile), bufio.NewWriter(w)
+	defer buff.Flush()
+	scan.Buffer(make([]byte, 0, 64*1024), 2*1024*1024)
+	for scan.Scan() {
+		var entry logs.Entry
+		if json.Unmarshal(scan.Bytes(), &entry) == nil {
+			fmt.Fprintf(buff, "%s %s\n", entry.Time.Format(time.RFC3339), entry.Text)
+		}
+	}
+}
+
+// Get server logs retention policy
+func serverLogsRetention(w http.ResponseWriter, r *http.Request) {
+	policy, err := Database(r.Context()).LogRetention(Server(r.Context()).ID)
+	if err != nil {
+		jsonResponse(w, http.StatusInternalServerError, map[string]string{
+			"error":   "internal error",
+			"message": err.Error(),
+		})
+		return
+	}
+	jsonResponse(w, http.StatusOK, policy)
+}
+
+// Update server logs retention policy and remove old runs
+func serverLogsRetentionUpdate(w http.ResponseWriter, r *http.Request) {
+	if !HasPermission(r.Context(), server.Edit) {
+		jsonResponse(w, http.StatusForbidden, map[string]string{"error": "permission", "message": "you dont have permission to access this route"})
+		return
+	}
+
+	var policy server.LogRetention
+	if err := json.NewDecoder(r.Body).Decode(&policy); err != nil {
+		jsonResponse(w, http.StatusBadRequest, map[string]string{"error": "invalid json", "message": err.Error()})
+		return
+	} else if policy.MaxAge < 0 || policy.MaxRuns < 0 {
+		jsonResponse(w, http.StatusBadRequest, map[string]string{"error": "invalid policy", "message": "max_age and max_runs must be positive"})
+		return
+	}
+
+	policy.ServerID = Server(r.Context()).ID
+	if err := Database(r.Context()).SetLogRetention(&policy); err != nil {
+		jsonResponse(w, http.StatusInternalServerError, map[string]string{
+			"error":   "internal error",
+			"message": err.Error(),
+		})
+		return
+	}
+
+	if store := Logs(r.Context()); store != nil {
+		store.Prune(policy.ServerID, &policy)
+	}
+	jsonResponse(w, http.StatusOK, policy)
+}
@@ -0,0 +1,50 @@
+package web
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+	"sirherobrine23.com.br/go-bds/bds/module/users"
+)
+
+// Require user token to notifications routes
+func requireUser(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if User(r.Context()) == nil {
+			jsonResponse(w, http.StatusUnauthorized, map[string]string{
+				"error":   "authoraztion",
+				"message": "require token to access this route",
+			})
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// List user notifications
+func userNotifications(w http.ResponseWriter, r *http.Request) {
+	notifications, err := Database(r.Context()).Notifications(User(r.Context()).UserID)
+	if err != nil {
+		jsonResponse(w, http.StatusInternalServerError, map[string]string{
+			"error":   "internal error",
+			"message": err.Error(),
+		})
+		return
+	}
+	jsonResponse(w, http.StatusOK, notifications)
+}
+
+// Remove user notification
+func userNotificationDelete(w http.ResponseWriter, r *http.Request) {
+	notificationID, _ := strconv.ParseInt(chi.URLParam(r, "notificationID"), 10, 64)
+	notification := &users.Notification{ID: notificationID, UserID: User(r.Context()).UserID}
+	if err := Database(r.Context()).DeleteNotification(notification); err != nil {
+		jsonResponse(w, http.StatusInternalServerError, map[string]string{
+			"error":   "internal error",
+			"message": err.Error(),
+		})
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
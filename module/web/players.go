@@ -0,0 +1,161 @@
+package web
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"sirherobrine23.com.br/go-bds/bds/module/db"
+	"sirherobrine23.com.br/go-bds/bds/module/players"
+	"sirherobrine23.com.br/go-bds/bds/module/runner"
+	"sirherobrine23.com.br/go-bds/bds/module/server"
+)
+
+// Body to POST /server/{id}/players/{username}
+type PlayerActionBody struct {
+	Action server.PlayerAction `json:"action"` // kick, ban, pardon, op, deop, whitelist or unwhitelist
+	Reason string              `json:"reason"` // Reason to kick or ban
+}
+
+// Player status
+type PlayerStatus struct {
+	Online  *players.Online         `json:"online"`  // Current session, null if offline
+	Player  *server.Player          `json:"player"`  // Player storaged in database, null if never seen
+	History []*server.PlayerHistory `json:"history"` // Player history, newest first
+}
+
+// List online players
+func serverPlayers(w http.ResponseWriter, r *http.Request) {
+	tracker := Players(r.Context())
+	if tracker == nil {
+		jsonResponse(w, http.StatusOK, []*players.Online{})
+		return
+	}
+	jsonResponse(w, http.StatusOK, tracker.Online(Server(r.Context()).ID))
+}
+
+// Get player status and history
+func serverPlayer(w http.ResponseWriter, r *http.Request) {
+	mcServer, username := Server(r.Context()), chi.URLParam(r, "username")
+	database := Database(r.Context())
+
+	var status PlayerStatus
+	if tracker := Players(r.Context()); tracker != nil {
+		status.Online = tracker.Player(mcServer.ID, username)
+	}
+
+	var err error
+	if status.Player, err = database.Player(mcServer.ID, username); err != nil && err != db.ErrPlayerNotExists {
+		jsonResponse(w, http.StatusInternalServerError, map[string]string{
+			"error":   "internal error",
+			"message": err.Error(),
+		})
+		return
+	} else if status.Player == nil && status.Online == nil {
+		jsonResponse(w, http.StatusNotFound, map[string]string{"error": "player not found"})
+		return
+	}
+
+	if status.History, err = database.PlayerHistory(mcServer.ID, username); err != nil {
+		jsonResponse(w, http.StatusInternalServerError, map[string]string{
+			"error":   "internal error",
+			"message": err.Error(),
+		})
+		return
+	}
+	jsonResponse(w, http.StatusOK, status)
+}
+
+// Kick, ban, op, deop or whitelist player
+func serverPlayerAction(w http.ResponseWriter, r *http.Request) {
+	var body PlayerActionBody
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		jsonResponse(w, http.StatusBadRequest, map[string]string{"error": "invalid json", "message": err.Error()})
+		return
+	}
+	playerAction(w, r, body.Action, body.Reason)
+}
+
+// Revert ban, op or whitelist, action in query: ?action=ban
+func serverPlayerRevert(w http.ResponseWriter, r *http.Request) {
+	action, err := players.Revert(server.PlayerAction(r.URL.Query().Get("action")))
+	if err != nil {
+		jsonResponse(w, http.StatusBadRequest, map[string]string{"error": "invalid action", "message": "action must be ban, op or whitelist"})
+		return
+	}
+	playerAction(w, r, action, "")
+}
+
+func playerAction(w http.ResponseWriter, r *http.Request, action server.PlayerAction, reason string) {
+	if !HasPermission(r.Context(), server.Console, server.Edit) {
+		jsonResponse(w, http.StatusForbidden, map[string]string{"error": "permission", "message": "you dont have permission to access this route"})
+		return
+	}
+
+	mcServer, username := Server(r.Context()), chi.URLParam(r, "username")
+	commands, err := players.Commands(mcServer.Software, action, username, reason)
+	if err != nil {
+		switch err {
+		case players.ErrInvalidUsername:
+			jsonResponse(w, http.StatusBadRequest, map[string]string{"error": "invalid username", "message": err.Error()})
+		case players.ErrInvalidReason:
+			jsonResponse(w, http.StatusBadRequest, map[string]string{"error": "invalid reason", "message": err.Error()})
+		case players.ErrUnsupported:
+			jsonResponse(w, http.StatusNotImplemented, map[string]string{"error": "unsupported", "message": err.Error()})
+		default:
+			jsonResponse(w, http.StatusBadRequest, map[string]string{"error": "invalid action", "message": err.Error()})
+		}
+		return
+	}
+
+	var proc *runner.Process
+	if manager := Runner(r.Context()); manager != nil {
+		proc = manager.Process(mcServer.ID)
+	}
+	if proc == nil {
+		jsonResponse(w, http.StatusConflict, map[string]string{"error": "server not running"})
+		return
+	}
+
+	for _, command := range commands {
+		if err := proc.SendCommand(command); err != nil {
+			jsonResponse(w, http.StatusInternalServerError, map[string]string{
+				"error":   "command",
+				"message": err.Error(),
+			})
+			return
+		}
+	}
+
+	database := Database(r.Context())
+	player, err := database.Player(mcServer.ID, username)
+	if err != nil {
+		player = &server.Player{ServerID: mcServer.ID, Username: username}
+	}
+	players.Apply(player, action)
+	if err = database.UpdatePlayer(player); err == nil {
+		err = database.AddPlayerHistory(&server.PlayerHistory{
+			ServerID: mcServer.ID,
+			Username: username,
+			Action:   action,
+			Reason:   reason,
+			UserID:   User(r.Context()).UserID,
+		})
+	}
+	if err != nil {
+		jsonResponse(w, http.StatusInternalServerError, map[string]string{
+			"error":   "internal error",
+			"message": err.Error(),
+		})
+		return
+	}
+
+	if player, err = database.Player(mcServer.ID, username); err != nil {
+		jsonResponse(w, http.StatusInternalServerError, map[string]string{
+			"error":   "internal error",
+			"message": err.Error(),
+		})
+		return
+	}
+	jsonResponse(w, http.StatusOK, map[string]any{"player": player, "commands": commands})
+}
@@ -0,0 +1,117 @@
+package web
+
+import (
+	"errors"
+	"net/http"
+	"os"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+	"sirherobrine23.com.br/go-bds/bds/module/plugins"
+	"sirherobrine23.com.br/go-bds/bds/module/server"
+)
+
+// Allow only java servers and require edit permission to change
+func javaOnly(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.EqualFold(Server(r.Context()).Software, "bedrock") {
+			jsonResponse(w, http.StatusBadRequest, map[string]string{"error": "software", "message": "only avaible to java servers"})
+			return
+		} else if Runner(r.Context()) == nil {
+			jsonResponse(w, http.StatusInternalServerError, map[string]string{
+				"error":   "runner",
+				"message": "invalid server configuration or caller, check implementaion",
+			})
+			return
+		} else if r.Method != http.MethodGet && !HasPermission(r.Context(), server.Edit) {
+			jsonResponse(w, http.StatusForbidden, map[string]string{"error": "permission", "message": "you dont have permission to access this route"})
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// Response plugins errors
+func pluginsError(w http.ResponseWriter, err error) {
+	switch {
+	case err == plugins.ErrJarNotExists:
+		jsonResponse(w, http.StatusNotFound, map[string]string{"error": "not found", "message": err.Error()})
+	case err == plugins.ErrJarExists:
+		jsonResponse(w, http.StatusConflict, map[string]string{"error": "jar", "message": err.Error()})
+	case err == plugins.ErrInvalidJar, err == plugins.ErrInvalidName, err == plugins.ErrInvalidKind, err == plugins.ErrJavaOnly:
+		jsonResponse(w, http.StatusBadRequest, map[string]string{"error": "invalid jar", "message": err.Error()})
+	default:
+		jsonResponse(w, http.StatusInternalServerError, map[string]string{
+			"error":   "internal error",
+			"message": err.Error(),
+		})
+	}
+}
+
+// List plugins and mods with issues
+func serverPlugins(w http.ResponseWriter, r *http.Request) {
+	jars, err := plugins.List(serverDir(r), Server(r.Context()))
+	if err != nil {
+		pluginsError(w, err)
+		return
+	}
+	jsonResponse(w, http.StatusOK, jars)
+}
+
+// Install or upgrade jar from body, raw with "name" query or multipart with file in "jar" field
+func serverPluginInstall(w http.ResponseWriter, r *http.Request) {
+	tmp, size, ok := spoolBody(w, r, "jar")
+	if !ok {
+		return
+	}
+	defer os.Remove(tmp.Name())
+	defer tmp.Close()
+
+	name := r.URL.Query().Get("name")
+	if name == "" && r.MultipartForm != nil && len(r.MultipartForm.File["jar"]) > 0 {
+		name = r.MultipartForm.File["jar"][0].Filename
+	}
+
+	jar, err := plugins.Install(serverDir(r), Server(r.Context()), name, tmp, size)
+	if err != nil {
+		pluginsError(w, err)
+		return
+	}
+	jsonResponse(w, http.StatusCreated, jar)
+}
+
+func pluginKind(r *http.Request) plugins.Kind {
+	return plugins.Kind(chi.URLParam(r, "kind"))
+}
+
+// Enable jar
+func serverPluginEnable(w http.ResponseWriter, r *http.Request) {
+	jar, err := plugins.SetEnabled(serverDir(r), Server(r.Context()), pluginKind(r), chi.URLParam(r, "file"), true)
+	if err != nil {
+		pluginsError(w, err)
+		return
+	}
+	jsonResponse(w, http.StatusOK, jar)
+}
+
+// Disable jar, keep file with ".disabled" extension
+func serverPluginDisable(w http.ResponseWriter, r *http.Request) {
+	jar, err := plugins.SetEnabled(serverDir(r), Server(r.Context()), pluginKind(r), chi.URLParam(r, "file"), false)
+	if err != nil {
+		pluginsError(w, err)
+		return
+	}
+	jsonResponse(w, http.StatusOK, jar)
+}
+
+// Delete jar file
+func serverPluginDelete(w http.ResponseWriter, r *http.Request) {
+	if err := plugins.Delete(serverDir(r), Server(r.Context()), pluginKind(r), chi.URLParam(r, "file")); err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			err = plugins.ErrJarNotExists
+		}
+		pluginsError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
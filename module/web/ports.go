@@ -0,0 +1,70 @@
+package web
+
+import (
+	"errors"
+	"net/http"
+
+	"sirherobrine23.com.br/go-bds/bds/module/network"
+	"sirherobrine23.com.br/go-bds/bds/module/server"
+)
+
+// Server ports
+type ServerPorts struct {
+	Port            int  `json:"port"`                       // IPv4 port
+	PortV6          int  `json:"port_v6,omitempty"`          // IPv6 port, only to bedrock
+	RCONPort        int  `json:"rcon_port,omitempty"`        // RCON port, only to java
+	RestartRequired bool `json:"restart_required,omitempty"` // Server running with old ports
+}
+
+// Response ports conflict, return false if err is not ports error
+func portsError(w http.ResponseWriter, err error) bool {
+	var conflict *network.ConflictError
+	switch {
+	case errors.As(err, &conflict):
+		jsonResponse(w, http.StatusConflict, map[string]any{"error": "port", "message": err.Error(), "port": conflict.Port, "server_id": conflict.ServerID})
+	case err == network.ErrNoPorts:
+		jsonResponse(w, http.StatusConflict, map[string]string{"error": "port", "message": err.Error()})
+	default:
+		return false
+	}
+	return true
+}
+
+// Get server ports
+func serverPorts(w http.ResponseWriter, r *http.Request) {
+	mcServer := Server(r.Context())
+	jsonResponse(w, http.StatusOK, ServerPorts{Port: mcServer.Port, PortV6: mcServer.PortV6, RCONPort: mcServer.RCONPort})
+}
+
+// Allocate new ports to server from runner range
+func serverPortsAllocate(w http.ResponseWriter, r *http.Request) {
+	if !HasPermission(r.Context(), server.Edit) {
+		jsonResponse(w, http.StatusForbidden, map[string]string{"error": "permission", "message": "you dont have permission to access this route"})
+		return
+	}
+	alloc := Ports(r.Context())
+	if alloc == nil {
+		jsonResponse(w, http.StatusInternalServerError, map[string]string{
+			"error":   "ports",
+			"message": "invalid server configuration or caller, check implementaion",
+		})
+		return
+	}
+
+	mcServer := Server(r.Context())
+	if err := alloc.Allocate(mcServer); err != nil {
+		if !portsError(w, err) {
+			jsonResponse(w, http.StatusInternalServerError, map[string]string{
+				"error":   "internal error",
+				"message": err.Error(),
+			})
+		}
+		return
+	}
+	jsonResponse(w, http.StatusOK, ServerPorts{
+		Port:            mcServer.Port,
+		PortV6:          mcServer.PortV6,
+		RCONPort:        mcServer.RCONPort,
+		RestartRequired: alloc.Runner.Process(mcServer.ID) != nil,
+	})
+}
@@ -0,0 +1,187 @@
+package web
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"sirherobrine23.com.br/go-bds/bds/module/db"
+	"sirherobrine23.com.br/go-bds/bds/module/schedule"
+	"sirherobrine23.com.br/go-bds/bds/module/server"
+)
+
+// Get task from URL and check if is from server in context
+func serverTaskFromURL(w http.ResponseWriter, r *http.Request) *server.Task {
+	taskID, _ := strconv.ParseInt(chi.URLParam(r, "taskID"), 10, 64)
+	task, err := Database(r.Context()).Task(taskID)
+	if err != nil {
+		switch err {
+		case db.ErrTaskNotExists:
+			jsonResponse(w, http.StatusNotFound, map[string]string{"error": "task not found"})
+		default:
+			jsonResponse(w, http.StatusInternalServerError, map[string]string{
+				"error":   "internal error",
+				"message": err.Error(),
+			})
+		}
+		return nil
+	} else if task.ServerID != Server(r.Context()).ID {
+		jsonResponse(w, http.StatusNotFound, map[string]string{"error": "task not found"})
+		return nil
+	}
+	return task
+}
+
+// List server tasks
+func serverTasks(w http.ResponseWriter, r *http.Request) {
+	tasks, err := Database(r.Context()).Tasks(Server(r.Context()).ID)
+	if err != nil {
+		jsonResponse(w, http.StatusInternalServerError, map[string]string{
+			"error":   "internal error",
+			"message": err.Error(),
+		})
+		return
+	}
+	jsonResponse(w, http.StatusOK, tasks)
+}
+
+// Get task
+func serverTask(w http.ResponseWriter, r *http.Request) {
+	if task := serverTaskFromURL(w, r); task != nil {
+		jsonResponse(w, http.StatusOK, task)
+	}
+}
+
+// Create new task, enabled by default
+func serverTaskCreate(w http.ResponseWriter, r *http.Request) {
+	if !HasPermission(r.Context(), server.Edit) {
+		jsonResponse(w, http.StatusForbidden, map[string]string{"error": "permission", "message": "you dont have permission to access this route"})
+		return
+	}
+
+	task := &server.Task{Enabled: true}
+	if err := json.NewDecoder(r.Body).Decode(task); err != nil {
+		jsonResponse(w, http.StatusBadRequest, map[string]string{"error": "invalid json", "message": err.Error()})
+		return
+	} else if err = schedule.Validate(task); err != nil {
+		jsonResponse(w, http.StatusBadRequest, map[string]string{"error": "invalid task", "message": err.Error()})
+		return
+	}
+
+	task.ServerID = Server(r.Context()).ID
+	task, err := Database(r.Context()).CreateTask(task)
+	if err != nil {
+		jsonResponse(w, http.StatusInternalServerError, map[string]string{
+			"error":   "internal error",
+			"message": err.Error(),
+		})
+		return
+	}
+	jsonResponse(w, http.StatusCreated, task)
+}
+
+// Update task, fields not in body are kept
+func serverTaskUpdate(w http.ResponseWriter, r *http.Request) {
+	if !HasPermission(r.Context(), server.Edit) {
+		jsonResponse(w, http.StatusForbidden, map[string]string{"error": "permission", "message": "you dont have permission to access this route"})
+		return
+	}
+
+	task := serverTaskFromURL(w, r)
+	if task == nil {
+		return
+	}
+
+	taskID, serverID, lastRun, createAt := task.ID, task.ServerID, task.LastRun, task.CreateAt
+	if err := json.NewDecoder(r.Body).Decode(task); err != nil {
+		jsonResponse(w, http.StatusBadRequest, map[string]string{"error": "invalid json", "message": err.Error()})
+		return
+	} else if err = schedule.Validate(task); err != nil {
+		jsonResponse(w, http.StatusBadRequest, map[string]string{"error": "invalid task", "message": err.Error()})
+		return
+	}
+
+	task.ID, task.ServerID, task.LastRun, task.CreateAt = taskID, serverID, lastRun, createAt
+	if err := Database(r.Context()).UpdateTask(task); err != nil {
+		jsonResponse(w, http.StatusInternalServerError, map[string]string{
+			"error":   "internal error",
+			"message": err.Error(),
+		})
+		return
+	}
+	jsonResponse(w, http.StatusOK, task)
+}
+
+// Delete task and history
+func serverTaskDelete(w http.ResponseWriter, r *http.Request) {
+	if !HasPermission(r.Context(), server.Edit) {
+		jsonResponse(w, http.StatusForbidden, map[string]string{"error": "permission", "message": "you dont have permission to access this route"})
+		return
+	}
+
+	task := serverTaskFromURL(w, r)
+	if task == nil {
+		return
+	} else if err := Database(r.Context()).DeleteTask(task); err != nil {
+		jsonResponse(w, http.StatusInternalServerError, map[string]string{
+			"error":   "internal error",
+			"message": err.Error(),
+		})
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// Task runs history, query limit to max runs, default 50
+func serverTaskRuns(w http.ResponseWriter, r *http.Request) {
+	task := serverTaskFromURL(w, r)
+	if task == nil {
+		return
+	}
+
+	limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
+	if limit <= 0 {
+		limit = 50
+	}
+
+	runs, err := Database(r.Context()).TaskRuns(task.ID, limit)
+	if err != nil {
+		jsonResponse(w, http.StatusInternalServerError, map[string]string{
+			"error":   "internal error",
+			"message": err.Error(),
+		})
+		return
+	}
+	jsonResponse(w, http.StatusOK, runs)
+}
+
+// Run task now in background, result is recorded in task history
+func serverTaskRun(w http.ResponseWriter, r *http.Request) {
+	if !HasPermission(r.Context(), server.Edit) {
+		jsonResponse(w, http.StatusForbidden, map[string]string{"error": "permission", "message": "you dont have permission to access this route"})
+		return
+	}
+
+	scheduler := Tasks(r.Context())
+	if scheduler == nil {
+		jsonResponse(w, http.StatusInternalServerError, map[string]string{
+			"error":   "tasks",
+			"message": "invalid server configuration or caller, check implementaion",
+		})
+		return
+	}
+
+	task := serverTaskFromURL(w, r)
+	if task == nil {
+		return
+	} else if scheduler.Running(task.ID) {
+		jsonResponse(w, http.StatusConflict, map[string]string{"error": "task running", "message": schedule.ErrTaskRunning.Error()})
+		return
+	}
+	// Restart countdown can take minutes, not wait it
+	go scheduler.RunTask(context.Background(), task, time.Now())
+	jsonResponse(w, http.StatusAccepted, task)
+}
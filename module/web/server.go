@@ -0,0 +1,27 @@
+package web
+
+import (
+	"net/http"
+
+	"sirherobrine23.com.br/go-bds/bds/module/server"
+	"sirherobrine23.com.br/go-bds/bds/module/status"
+)
+
+// Server with running status
+type ServerInfo struct {
+	*server.Server
+	Running bool           `json:"running"`          // Server process running
+	Status  *status.Health `json:"status,omitempty"` // Last status from server ping, nil if not pinged yet
+}
+
+// Get server info and status
+func serverInfo(w http.ResponseWriter, r *http.Request) {
+	info := ServerInfo{Server: Server(r.Context())}
+	if manager := Runner(r.Context()); manager != nil {
+		info.Running = manager.Process(info.ID) != nil
+	}
+	if poller := Status(r.Context()); poller != nil && info.Running {
+		info.Status = poller.Status(info.ID)
+	}
+	jsonResponse(w, http.StatusOK, info)
+}
@@ -0,0 +1,202 @@
+package web
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"sirherobrine23.com.br/go-bds/bds/module/backup"
+	"sirherobrine23.com.br/go-bds/bds/module/server"
+	"sirherobrine23.com.br/go-bds/bds/module/upgrade"
+	"sirherobrine23.com.br/go-bds/bds/module/versions"
+)
+
+// Check if versions is configured
+func versionsManager(w http.ResponseWriter, r *http.Request) *versions.Manager {
+	manager := Versions(r.Context())
+	if manager == nil {
+		jsonResponse(w, http.StatusInternalServerError, map[string]string{
+			"error":   "versions",
+			"message": "invalid server configuration or caller, check implementaion",
+		})
+	}
+	return manager
+}
+
+func versionsError(w http.ResponseWriter, err error) {
+	switch err {
+	case versions.ErrSoftwareNotExists:
+		jsonResponse(w, http.StatusNotFound, map[string]string{"error": "software not found", "message": err.Error()})
+	case versions.ErrVersionNotFound:
+		jsonResponse(w, http.StatusNotFound, map[string]string{"error": "version not found", "message": err.Error()})
+	default:
+		jsonResponse(w, http.StatusBadGateway, map[string]string{"error": "manifest", "message": err.Error()})
+	}
+}
+
+// List softwares names
+func softwares(w http.ResponseWriter, r *http.Request) {
+	if manager := versionsManager(w, r); manager != nil {
+		jsonResponse(w, http.StatusOK, manager.Softwares())
+	}
+}
+
+// List software versions, newest first
+func softwareVersions(w http.ResponseWriter, r *http.Request) {
+	manager := versionsManager(w, r)
+	if manager == nil {
+		return
+	}
+
+	list, err := manager.Versions(r.Context(), chi.URLParam(r, "software"))
+	if err != nil {
+		versionsError(w, err)
+		return
+	}
+	jsonResponse(w, http.StatusOK, list)
+}
+
+// Resolve "latest", "preview" or version
+func softwareVersion(w http.ResponseWriter, r *http.Request) {
+	manager := versionsManager(w, r)
+	if manager == nil {
+		return
+	}
+
+	version, err := manager.Resolve(r.Context(), chi.URLParam(r, "software"), chi.URLParam(r, "version"))
+	if err != nil {
+		versionsError(w, err)
+		return
+	}
+	jsonResponse(w, http.StatusOK, version)
+}
+
+// Body to upgrade server
+type ServerUpgrade struct {
+	Version string `json:"version"` // "latest", "preview" or version
+}
+
+// Upgrade server in place, wait upgrade and health check
+func serverUpgrade(w http.ResponseWriter, r *http.Request) {
+	if !HasPermission(r.Context(), server.Edit) {
+		jsonResponse(w, http.StatusForbidden, map[string]string{"error": "permission", "message": "you dont have permission to access this route"})
+		return
+	}
+
+	upgrader := Upgrades(r.Context())
+	if upgrader == nil {
+		jsonResponse(w, http.StatusInternalServerError, map[string]string{
+			"error":   "upgrades",
+			"message": "invalid server configuration or caller, check implementaion",
+		})
+		return
+	}
+
+	body := ServerUpgrade{Version: versions.Latest}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil && err != io.EOF {
+		jsonResponse(w, http.StatusBadRequest, map[string]string{"error": "invalid json", "message": err.Error()})
+		return
+	}
+
+	// Not stop upgrade in middle if client disconnect
+	result, err := upgrader.Upgrade(context.WithoutCancel(r.Context()), Server(r.Context()), body.Version)
+	if err != nil {
+		switch {
+		case err == upgrade.ErrUpgradeRunning, err == upgrade.ErrUpToDate, err == backup.ErrBackupRunning:
+			jsonResponse(w, http.StatusConflict, map[string]string{"error": "upgrade", "message": err.Error()})
+		case err == versions.ErrSoftwareNotExists, err == versions.ErrVersionNotFound:
+			versionsError(w, err)
+		default:
+			jsonResponse(w, http.StatusInternalServerError, map[string]string{
+				"error":   "internal error",
+				"message": err.Error(),
+			})
+		}
+		return
+	}
+	jsonResponse(w, http.StatusOK, result)
+}
+
+// Get server update policy
+func serverUpdatePolicy(w http.ResponseWriter, r *http.Request) {
+	policy, err := Database(r.Context()).UpdatePolicy(Server(r.Context()).ID)
+	if err != nil {
+		jsonResponse(w, http.StatusInternalServerError, map[string]string{
+			"error":   "internal error",
+			"message": err.Error(),
+		})
+		return
+	}
+	jsonResponse(w, http.StatusOK, policy)
+}
+
+// Set server update policy, check status is kept
+func serverUpdatePolicySet(w http.ResponseWriter, r *http.Request) {
+	if !HasPermission(r.Context(), server.Edit) {
+		jsonResponse(w, http.StatusForbidden, map[string]string{"error": "permission", "message": "you dont have permission to access this route"})
+		return
+	}
+
+	database := Database(r.Context())
+	current, err := database.UpdatePolicy(Server(r.Context()).ID)
+	if err != nil {
+		jsonResponse(w, http.StatusInternalServerError, map[string]string{
+			"error":   "internal error",
+			"message": err.Error(),
+		})
+		return
+	}
+
+	var body server.UpdatePolicy
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		jsonResponse(w, http.StatusBadRequest, map[string]string{"error": "invalid json", "message": err.Error()})
+		return
+	} else if err = upgrade.ValidatePolicy(&body); err != nil {
+		jsonResponse(w, http.StatusBadRequest, map[string]string{"error": "invalid policy", "message": err.Error()})
+		return
+	}
+
+	current.Channel, current.AutoUpgrade, current.MaxPlayers = body.Channel, body.AutoUpgrade, body.MaxPlayers
+	current.WindowStart, current.WindowEnd = body.WindowStart, body.WindowEnd
+	if err := database.SetUpdatePolicy(current); err != nil {
+		jsonResponse(w, http.StatusInternalServerError, map[string]string{
+			"error":   "internal error",
+			"message": err.Error(),
+		})
+		return
+	}
+	jsonResponse(w, http.StatusOK, current)
+}
+
+// Check new version now, server is upgraded if policy allow
+func serverUpdateCheck(w http.ResponseWriter, r *http.Request) {
+	if !HasPermission(r.Context(), server.Edit) {
+		jsonResponse(w, http.StatusForbidden, map[string]string{"error": "permission", "message": "you dont have permission to access this route"})
+		return
+	}
+
+	checker := Updates(r.Context())
+	if checker == nil {
+		jsonResponse(w, http.StatusInternalServerError, map[string]string{
+			"error":   "updates",
+			"message": "invalid server configuration or caller, check implementaion",
+		})
+		return
+	}
+
+	policy, err := Database(r.Context()).UpdatePolicy(Server(r.Context()).ID)
+	if err != nil {
+		jsonResponse(w, http.StatusInternalServerError, map[string]string{
+			"error":   "internal error",
+			"message": err.Error(),
+		})
+		return
+	}
+
+	// Error is saved in policy
+	result, _ := checker.Check(context.WithoutCancel(r.Context()), policy, time.Now())
+	jsonResponse(w, http.StatusOK, map[string]any{"policy": policy, "upgrade": result})
+}
@@ -2,17 +2,58 @@ package web
 
 import (
 	"context"
+	"slices"
 
+	"sirherobrine23.com.br/go-bds/bds/module/backup"
 	"sirherobrine23.com.br/go-bds/bds/module/db"
+	"sirherobrine23.com.br/go-bds/bds/module/files"
+	"sirherobrine23.com.br/go-bds/bds/module/logs"
+	"sirherobrine23.com.br/go-bds/bds/module/network"
+	"sirherobrine23.com.br/go-bds/bds/module/players"
+	"sirherobrine23.com.br/go-bds/bds/module/runner"
+	"sirherobrine23.com.br/go-bds/bds/module/schedule"
 	"sirherobrine23.com.br/go-bds/bds/module/server"
+	"sirherobrine23.com.br/go-bds/bds/module/status"
+	"sirherobrine23.com.br/go-bds/bds/module/upgrade"
 	"sirherobrine23.com.br/go-bds/bds/module/users"
+	"sirherobrine23.com.br/go-bds/bds/module/versions"
+	"sirherobrine23.com.br/go-bds/bds/module/worlds"
 )
 
+// Backends used by API routes
+type Services struct {
+	Database db.Database         // Database connection
+	Runner   *runner.Manager     // Local servers runner
+	Logs     *logs.Store         // Servers logs storage
+	Players  *players.Tracker    // Online players tracker
+	Backups  *backup.Manager     // Servers backups
+	Tasks    *schedule.Scheduler // Servers scheduled tasks
+	Versions *versions.Manager   // Servers softwares versions
+	Upgrades *upgrade.Upgrader   // Servers in place upgrades
+	Updates  *upgrade.Checker    // Servers updates checker
+	Files    *files.Manager      // Servers files maneger
+	Worlds   *worlds.Manager     // Servers worlds
+	Ports    *network.Allocator  // Servers ports in runner
+	Status   *status.Poller      // Running servers status
+}
+
 type routesTypeContext string
 
 // Use to get values from context
 const (
 	DatabaseContext routesTypeContext = "Database"
+	RunnerContext   routesTypeContext = "runner"
+	LogsContext     routesTypeContext = "logs"
+	PlayersContext  routesTypeContext = "players"
+	BackupsContext  routesTypeContext = "backups"
+	TasksContext    routesTypeContext = "tasks"
+	VersionsContext routesTypeContext = "versions"
+	UpgradesContext routesTypeContext = "upgrades"
+	UpdatesContext  routesTypeContext = "updates"
+	FilesContext    routesTypeContext = "files"
+	WorldsContext   routesTypeContext = "worlds"
+	PortsContext    routesTypeContext = "ports"
+	StatusContext   routesTypeContext = "status"
 	UserContext     routesTypeContext = "user"
 	TokenContext    routesTypeContext = "token"
 
@@ -28,6 +69,102 @@ func Database(ctx context.Context) db.Database {
 	return nil
 }
 
+// Get servers [*runner.Manager] from context
+func Runner(ctx context.Context) *runner.Manager {
+	if manager, ok := ctx.Value(RunnerContext).(*runner.Manager); ok {
+		return manager
+	}
+	return nil
+}
+
+// Get servers logs [*logs.Store] from context
+func Logs(ctx context.Context) *logs.Store {
+	if store, ok := ctx.Value(LogsContext).(*logs.Store); ok {
+		return store
+	}
+	return nil
+}
+
+// Get [*players.Tracker] from context
+func Players(ctx context.Context) *players.Tracker {
+	if tracker, ok := ctx.Value(PlayersContext).(*players.Tracker); ok {
+		return tracker
+	}
+	return nil
+}
+
+// Get [*backup.Manager] from context
+func Backups(ctx context.Context) *backup.Manager {
+	if manager, ok := ctx.Value(BackupsContext).(*backup.Manager); ok {
+		return manager
+	}
+	return nil
+}
+
+// Get tasks [*schedule.Scheduler] from context
+func Tasks(ctx context.Context) *schedule.Scheduler {
+	if scheduler, ok := ctx.Value(TasksContext).(*schedule.Scheduler); ok {
+		return scheduler
+	}
+	return nil
+}
+
+// Get [*versions.Manager] from context
+func Versions(ctx context.Context) *versions.Manager {
+	if manager, ok := ctx.Value(VersionsContext).(*versions.Manager); ok {
+		return manager
+	}
+	return nil
+}
+
+// Get [*upgrade.Upgrader] from context
+func Upgrades(ctx context.Context) *upgrade.Upgrader {
+	if upgrader, ok := ctx.Value(UpgradesContext).(*upgrade.Upgrader); ok {
+		return upgrader
+	}
+	return nil
+}
+
+// Get updates [*upgrade.Checker] from context
+func Updates(ctx context.Context) *upgrade.Checker {
+	if checker, ok := ctx.Value(UpdatesContext).(*upgrade.Checker); ok {
+		return checker
+	}
+	return nil
+}
+
+// Get servers [*files.Manager] from context
+func Files(ctx context.Context) *files.Manager {
+	if manager, ok := ctx.Value(FilesContext).(*files.Manager); ok {
+		return manager
+	}
+	return nil
+}
+
+// Get [*worlds.Manager] from context
+func Worlds(ctx context.Context) *worlds.Manager {
+	if manager, ok := ctx.Value(WorldsContext).(*worlds.Manager); ok {
+		return manager
+	}
+	return nil
+}
+
+// Get servers [*network.Allocator] from context
+func Ports(ctx context.Context) *network.Allocator {
+	if alloc, ok := ctx.Value(PortsContext).(*network.Allocator); ok {
+		return alloc
+	}
+	return nil
+}
+
+// Get servers [*status.Poller] from context
+func Status(ctx context.Context) *status.Poller {
+	if poller, ok := ctx.Value(StatusContext).(*status.Poller); ok {
+		return poller
+	}
+	return nil
+}
+
 // Get [*users.User] from context if exists
 func User(ctx context.Context) *users.User {
 	if user, ok := ctx.Value(UserContext).(*users.User); ok {
@@ -54,8 +191,27 @@ func Server(ctx context.Context) *server.Server {
 
 // Get [*server.ServerFriends] from context if exists
 func ServerFriend(ctx context.Context) *server.ServerFriends {
-	if server, ok := ctx.Value(ServerContext).(*server.ServerFriends); ok {
+	if server, ok := ctx.Value(ServerFriendContext).(*server.ServerFriends); ok {
 		return server
 	}
 	return nil
 }
+
+// Check if user in context is server owner or friend with one of permissions
+func HasPermission(ctx context.Context, perms ...server.ServerPermission) bool {
+	mcServer, user := Server(ctx), User(ctx)
+	if mcServer == nil || user == nil {
+		return false
+	} else if mcServer.Owner == user.UserID {
+		return true
+	}
+
+	if friend := ServerFriend(ctx); friend != nil && friend.UserID == user.UserID {
+		for _, perm := range friend.Permission {
+			if slices.Contains(perms, perm) {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,189 @@
+package web
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+	"sirherobrine23.com.br/go-bds/bds/module/backup"
+	"sirherobrine23.com.br/go-bds/bds/module/files"
+	"sirherobrine23.com.br/go-bds/bds/module/server"
+	"sirherobrine23.com.br/go-bds/bds/module/worlds"
+)
+
+// Check if worlds is configured
+func worldsManager(w http.ResponseWriter, r *http.Request) *worlds.Manager {
+	manager := Worlds(r.Context())
+	if manager == nil {
+		jsonResponse(w, http.StatusInternalServerError, map[string]string{
+			"error":   "worlds",
+			"message": "invalid server configuration or caller, check implementaion",
+		})
+	}
+	return manager
+}
+
+// World name from URL, names can have spaces
+func worldName(r *http.Request) string {
+	name, err := url.PathUnescape(chi.URLParam(r, "world"))
+	if err != nil {
+		return chi.URLParam(r, "world")
+	}
+	return name
+}
+
+// Response worlds errors
+func worldsError(w http.ResponseWriter, err error) {
+	switch {
+	case err == worlds.ErrWorldNotExists, errors.Is(err, fs.ErrNotExist):
+		jsonResponse(w, http.StatusNotFound, map[string]string{"error": "world not found", "message": err.Error()})
+	case err == worlds.ErrWorldExists, err == worlds.ErrActiveWorld, err == worlds.ErrWorldBusy, err == backup.ErrBackupRunning:
+		jsonResponse(w, http.StatusConflict, map[string]string{"error": "world", "message": err.Error()})
+	case err == worlds.ErrInvalidName, err == worlds.ErrInvalidLevel, errors.Is(err, worlds.ErrInvalidWorld):
+		jsonResponse(w, http.StatusBadRequest, map[string]string{"error": "invalid world", "message": err.Error()})
+	case err == files.ErrQuota, err == files.ErrFileSize:
+		jsonResponse(w, http.StatusRequestEntityTooLarge, map[string]string{"error": "quota", "message": err.Error()})
+	default:
+		jsonResponse(w, http.StatusInternalServerError, map[string]string{
+			"error":   "internal error",
+			"message": err.Error(),
+		})
+	}
+}
+
+// List server worlds
+func serverWorlds(w http.ResponseWriter, r *http.Request) {
+	manager := worldsManager(w, r)
+	if manager == nil {
+		return
+	}
+	list, err := manager.List(Server(r.Context()))
+	if err != nil {
+		worldsError(w, err)
+		return
+	}
+	jsonResponse(w, http.StatusOK, list)
+}
+
+// Import world from body, raw zip or multipart with file in "world" field
+func serverWorldImport(w http.ResponseWriter, r *http.Request) {
+	if !HasPermission(r.Context(), server.Edit) {
+		jsonResponse(w, http.StatusForbidden, map[string]string{"error": "permission", "message": "you dont have permission to access this route"})
+		return
+	}
+	manager := worldsManager(w, r)
+	if manager == nil {
+		return
+	}
+
+	tmp, size, ok := spoolBody(w, r, "world")
+	if !ok {
+		return
+	}
+	defer os.Remove(tmp.Name())
+	defer tmp.Close()
+
+	world, err := manager.Import(Server(r.Context()), r.URL.Query().Get("name"), tmp, size)
+	if err != nil {
+		worldsError(w, err)
+		return
+	}
+	jsonResponse(w, http.StatusCreated, world)
+}
+
+// Download world as .mcworld on Bedrock or .zip on Java
+func serverWorldExport(w http.ResponseWriter, r *http.Request) {
+	manager := worldsManager(w, r)
+	if manager == nil {
+		return
+	}
+
+	mcServer, name := Server(r.Context()), worldName(r)
+	if _, err := manager.World(mcServer, name); err != nil {
+		worldsError(w, err)
+		return
+	}
+
+	ext := ".zip"
+	if strings.EqualFold(mcServer.Software, "bedrock") {
+		ext = ".mcworld"
+	}
+	w.Header().Set("Content-Type", "application/zip")
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", name+ext))
+	manager.Export(mcServer, name, w) // Headers already sent
+}
+
+// Set active world
+func serverWorldSwitch(w http.ResponseWriter, r *http.Request) {
+	if !HasPermission(r.Context(), server.Edit) {
+		jsonResponse(w, http.StatusForbidden, map[string]string{"error": "permission", "message": "you dont have permission to access this route"})
+		return
+	}
+	manager := worldsManager(w, r)
+	if manager == nil {
+		return
+	}
+
+	world, err := manager.Switch(Server(r.Context()), worldName(r))
+	if err != nil {
+		worldsError(w, err)
+		return
+	}
+	jsonResponse(w, http.StatusOK, world)
+}
+
+// Body to regenerate world
+type WorldRegenerate struct {
+	Seed string `json:"seed"` // New world seed, empty to random
+}
+
+// Backup and make new world with seed
+func serverWorldRegenerate(w http.ResponseWriter, r *http.Request) {
+	if !HasPermission(r.Context(), server.Edit) {
+		jsonResponse(w, http.StatusForbidden, map[string]string{"error": "permission", "message": "you dont have permission to access this route"})
+		return
+	}
+	manager := worldsManager(w, r)
+	if manager == nil {
+		return
+	}
+
+	var body WorldRegenerate
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil && err != io.EOF {
+		jsonResponse(w, http.StatusBadRequest, map[string]string{"error": "invalid json", "message": err.Error()})
+		return
+	}
+
+	mcBackup, err := manager.Regenerate(Server(r.Context()), worldName(r), body.Seed)
+	if err != nil {
+		worldsError(w, err)
+		return
+	}
+	jsonResponse(w, http.StatusOK, map[string]any{"backup": mcBackup})
+}
+
+// Backup and delete world
+func serverWorldDelete(w http.ResponseWriter, r *http.Request) {
+	if !HasPermission(r.Context(), server.Edit) {
+		jsonResponse(w, http.StatusForbidden, map[string]string{"error": "permission", "message": "you dont have permission to access this route"})
+		return
+	}
+	manager := worldsManager(w, r)
+	if manager == nil {
+		return
+	}
+
+	mcBackup, err := manager.Delete(Server(r.Context()), worldName(r))
+	if err != nil {
+		worldsError(w, err)
+		return
+	}
+	jsonResponse(w, http.StatusOK, map[string]any{"backup": mcBackup})
+}
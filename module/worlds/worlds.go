@@ -0,0 +1,484 @@
+// List, import, export, switch, regenerate and delete servers worlds
+package worlds
+
+import (
+	"archive/zip"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"sirherobrine23.com.br/go-bds/bds/module/backup"
+	"sirherobrine23.com.br/go-bds/bds/module/files"
+	"sirherobrine23.com.br/go-bds/bds/module/properties"
+	"sirherobrine23.com.br/go-bds/bds/module/runner"
+	"sirherobrine23.com.br/go-bds/bds/module/server"
+)
+
+const (
+	Dir           = "worlds"        // Worlds directory in server directory
+	LevelFile     = "level.dat"     // File to identify world directory
+	LevelNameFile = "levelname.txt" // Bedrock world display name
+)
+
+var (
+	ErrWorldNotExists error = errors.New("world not exists")
+	ErrWorldExists    error = errors.New("world already exists")
+	ErrInvalidName    error = errors.New("invalid world name")
+	ErrInvalidWorld   error = errors.New("archive not have world, level.dat not found")
+	ErrActiveWorld    error = errors.New("cannot delete active world")
+	ErrWorldBusy      error = errors.New("other world operation running to server")
+	ErrInvalidLevel   error = errors.New("level-name is outside server directory")
+
+	StopTimeout = time.Minute     // Max time to wait server stop before kill
+	SaveTimeout = time.Minute * 2 // Max time to wait running server save world before export
+)
+
+// World in server worlds directory
+type World struct {
+	Name      string    `json:"name"`       // Directory name
+	LevelName string    `json:"level_name"` // Display name, levelname.txt on Bedrock
+	Path      string    `json:"path"`       // Path relative to server directory
+	Active    bool      `json:"active"`     // World in level-name
+	Size      int64     `json:"size"`
+	ModTime   time.Time `json:"mod_time"`
+}
+
+// Servers worlds maneger
+type Manager struct {
+	Runner  *runner.Manager
+	Backups *backup.Manager // Backup server before destructive changes
+	Files   *files.Manager  // Check servers quota to imports, if nil not checked
+
+	running sync.Map // Server ID -> bool
+}
+
+// Create new worlds maneger
+func NewManager(backups *backup.Manager, filesManager *files.Manager) *Manager {
+	return &Manager{Runner: backups.Runner, Backups: backups, Files: filesManager}
+}
+
+func (mg *Manager) lock(serverID int64) error {
+	if _, running := mg.running.LoadOrStore(serverID, true); running {
+		return ErrWorldBusy
+	}
+	return nil
+}
+
+func (mg *Manager) unlock(serverID int64) { mg.running.Delete(serverID) }
+
+// Check world name is single directory name
+func ValidName(name string) bool {
+	return name != "" && name != "." && name != ".." && !strings.HasPrefix(name, ".") && !strings.ContainsAny(name, "/\\\x00") && len(name) <= 255
+}
+
+// Java servers split dimensions in "<level>_nether" and "<level>_the_end" directories
+func dimensions(software, name string) []string {
+	if strings.EqualFold(software, "bedrock") {
+		return []string{name}
+	}
+	return []string{name, name + "_nether", name + "_the_end"}
+}
+
+// level-name to world in worlds directory, Bedrock level-name is directory in worlds, Java is path to world
+func levelName(software, name string) string {
+	if strings.EqualFold(software, "bedrock") {
+		return name
+	}
+	return path.Join(Dir, name)
+}
+
+// Active level-name, with server default if not set
+func activeLevel(srv *server.Server, dir string) (string, error) {
+	file, err := properties.Open(filepath.Join(dir, properties.FileName))
+	if err != nil {
+		return "", err
+	}
+	name, ok := file.Get("level-name")
+	if !properties.ValidLevel(srv.Software, name) {
+		return "", ErrInvalidLevel
+	} else if !ok || name == "" {
+		if strings.EqualFold(srv.Software, "bedrock") {
+			return "Bedrock level", nil
+		}
+		return "world", nil
+	}
+	return name, nil
+}
+
+// Set server.properties keys
+func setProperties(dir string, values map[string]string) error {
+	name := filepath.Join(dir, properties.FileName)
+	file, err := properties.Open(name)
+	if err != nil {
+		return err
+	}
+	for key, value := range values {
+		file.Set(key, value)
+	}
+	return file.Save(name)
+}
+
+func dirSize(dir string) int64 {
+	var size int64
+	filepath.WalkDir(dir, func(_ string, entry fs.DirEntry, err error) error {
+		if err == nil && entry.Type().IsRegular() {
+			if info, err := entry.Info(); err == nil {
+				size += info.Size()
+			}
+		}
+		return nil
+	})
+	return size
+}
+
+func newWorld(srv *server.Server, serverDir, name, relPath, active string) *World {
+	world := &World{Name: name, LevelName: name, Path: relPath, Active: levelName(srv.Software, name) == active || relPath == active}
+	target := filepath.Join(serverDir, filepath.FromSlash(relPath))
+	if info, err := os.Stat(target); err == nil {
+		world.ModTime = info.ModTime()
+	}
+	if data, err := os.ReadFile(filepath.Join(target, LevelNameFile)); err == nil && len(strings.TrimSpace(string(data))) > 0 {
+		world.LevelName = strings.TrimSpace(string(data))
+	}
+	for _, dim := range dimensions(srv.Software, path.Base(relPath)) {
+		world.Size += dirSize(filepath.Join(target, "..", dim))
+	}
+	return world
+}
+
+// List server worlds, Java worlds outside worlds directory are listed if active
+func (mg *Manager) List(srv *server.Server) ([]*World, error) {
+	dir := mg.Runner.Dir(srv.ID)
+	active, err := activeLevel(srv, dir)
+	if err == ErrInvalidLevel {
+		return nil, err
+	} else if err != nil {
+		return nil, fmt.Errorf("cannot read server properties: %s", err)
+	}
+
+	worlds := []*World{}
+	entries, err := os.ReadDir(filepath.Join(dir, Dir))
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return nil, err
+	}
+	names := map[string]bool{}
+	for _, entry := range entries {
+		if entry.IsDir() && ValidName(entry.Name()) {
+			names[entry.Name()] = true
+		}
+	}
+	for _, entry := range entries {
+		name := entry.Name()
+		if !names[name] {
+			continue
+		}
+		// Java dimensions are part of world
+		if base, ok := strings.CutSuffix(name, "_nether"); ok && names[base] && !strings.EqualFold(srv.Software, "bedrock") {
+			continue
+		} else if base, ok := strings.CutSuffix(name, "_the_end"); ok && names[base] && !strings.EqualFold(srv.Software, "bedrock") {
+			continue
+		}
+		worlds = append(worlds, newWorld(srv, dir, name, path.Join(Dir, name), active))
+	}
+
+	// Old Java servers have world in server root
+	if !strings.EqualFold(srv.Software, "bedrock") && !strings.HasPrefix(active, Dir+"/") {
+		if _, err := os.Stat(filepath.Join(dir, filepath.FromSlash(active), LevelFile)); err == nil {
+			worlds = append(worlds, newWorld(srv, dir, path.Base(active), active, active))
+		}
+	}
+	slices.SortFunc(worlds, func(a, b *World) int { return strings.Compare(a.Name, b.Name) })
+	return worlds, nil
+}
+
+// Get world by name
+func (mg *Manager) World(srv *server.Server, name string) (*World, error) {
+	worlds, err := mg.List(srv)
+	if err != nil {
+		return nil, err
+	}
+	for _, world := range worlds {
+		if world.Name == name {
+			return world, nil
+		}
+	}
+	return nil, ErrWorldNotExists
+}
+
+// Find directory with level.dat nearest to archive root
+func worldRoot(zr *zip.Reader) (string, error) {
+	root, depth := "", -1
+	for _, file := range zr.File {
+		name := path.Clean(file.Name)
+		if path.Base(name) != LevelFile || file.FileInfo().IsDir() {
+			continue
+		}
+		dir := path.Dir(name)
+		if level := strings.Count(dir, "/"); dir == "." {
+			return ".", nil
+		} else if depth == -1 || level < depth {
+			root, depth = dir, level
+		}
+	}
+	if depth == -1 {
+		return "", ErrInvalidWorld
+	}
+	return root, nil
+}
+
+// Import .mcworld or .zip world to worlds directory, if name is empty use levelname.txt or archive directory name
+func (mg *Manager) Import(srv *server.Server, name string, r io.ReaderAt, size int64) (*World, error) {
+	zr, err := zip.NewReader(r, size)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrInvalidWorld, err)
+	}
+	root, err := worldRoot(zr)
+	if err != nil {
+		return nil, err
+	}
+	if name == "" && root != "." {
+		name = path.Base(root)
+	}
+	if name == "" {
+		name = "world"
+		if file, err := zr.Open(path.Join(root, LevelNameFile)); err == nil {
+			data, _ := io.ReadAll(io.LimitReader(file, 256))
+			file.Close()
+			if levelName := strings.TrimSpace(string(data)); ValidName(levelName) {
+				name = levelName
+			}
+		}
+	}
+	if !ValidName(name) {
+		return nil, ErrInvalidName
+	}
+
+	if err := mg.lock(srv.ID); err != nil {
+		return nil, err
+	}
+	defer mg.unlock(srv.ID)
+
+	dir := mg.Runner.Dir(srv.ID)
+	target := filepath.Join(dir, Dir, name)
+	if _, err := os.Lstat(target); err == nil {
+		return nil, ErrWorldExists
+	}
+
+	if mg.Files != nil {
+		var total int64
+		for _, file := range zr.File {
+			total += int64(file.UncompressedSize64)
+		}
+		fsys, err := mg.Files.Open(srv.ID)
+		if err != nil {
+			return nil, err
+		}
+		err = fsys.Reserve(path.Join(Dir, name), total)
+		fsys.Close()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if err := os.MkdirAll(filepath.Join(dir, Dir), 0755); err != nil {
+		return nil, err
+	}
+	tmp, err := os.MkdirTemp(filepath.Join(dir, Dir), ".import-")
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(tmp)
+	if err = backup.Extract(zr, tmp); err != nil {
+		return nil, fmt.Errorf("cannot extract world: %s", err)
+	}
+	if err = os.Rename(filepath.Join(tmp, filepath.FromSlash(root)), target); err != nil {
+		return nil, err
+	}
+	return mg.World(srv, name)
+}
+
+// Write world as zip to w, running server save world before.
+//
+// Bedrock exports can be imported as .mcworld, files are in archive root.
+func (mg *Manager) Export(srv *server.Server, name string, w io.Writer) error {
+	world, err := mg.World(srv, name)
+	if err != nil {
+		return err
+	}
+	dir := filepath.Join(mg.Runner.Dir(srv.ID), filepath.FromSlash(world.Path))
+	proc := mg.Runner.Process(srv.ID)
+	if proc == nil || !world.Active {
+		return exportDir(w, dir)
+	}
+
+	// Archive to temporary file while save is held, server write world again before archive is sent to slow clients
+	tmp, err := os.CreateTemp("", "world-export-*.zip")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	defer tmp.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), SaveTimeout)
+	defer cancel()
+	err = backup.HoldWorld(ctx, proc, func(worldFiles []backup.File) error {
+		if worldFiles == nil {
+			return exportDir(tmp, dir)
+		}
+		var list []backup.File
+		for _, file := range worldFiles {
+			if name, ok := strings.CutPrefix(file.Path, world.Path+"/"); ok {
+				list = append(list, backup.File{Path: name, Size: file.Size})
+			}
+		}
+		return backup.Archive(tmp, dir, list)
+	})
+	if err != nil {
+		return err
+	} else if _, err = tmp.Seek(0, io.SeekStart); err != nil {
+		return err
+	}
+	_, err = io.Copy(w, tmp)
+	return err
+}
+
+// Write all files in dir as zip to w
+func exportDir(w io.Writer, dir string) error {
+	paths := []string{}
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return err
+	}
+	for _, entry := range entries {
+		paths = append(paths, entry.Name())
+	}
+	list, err := backup.Walk(dir, paths)
+	if err != nil {
+		return err
+	}
+	return backup.Archive(w, dir, list)
+}
+
+// Set world as active level, running server is restarted
+func (mg *Manager) Switch(srv *server.Server, name string) (*World, error) {
+	if err := mg.lock(srv.ID); err != nil {
+		return nil, err
+	}
+	defer mg.unlock(srv.ID)
+
+	world, err := mg.World(srv, name)
+	if err != nil {
+		return nil, err
+	} else if world.Active {
+		return world, nil
+	}
+
+	dir := mg.Runner.Dir(srv.ID)
+	if err = setProperties(dir, map[string]string{"level-name": levelName(srv.Software, name)}); err != nil {
+		return nil, fmt.Errorf("cannot set level-name: %s", err)
+	}
+	if mg.Runner.Process(srv.ID) != nil {
+		if _, err = mg.Runner.Restart(srv, StopTimeout); err != nil {
+			return nil, fmt.Errorf("world switched but cannot restart server: %s", err)
+		}
+	}
+	world.Active = true
+	return world, nil
+}
+
+// Backup server before change world
+func (mg *Manager) backup(srv *server.Server) (*server.ServerBackup, error) {
+	mcBackup, err := mg.Backups.CreateSafety(srv)
+	if err != nil && err != backup.ErrNoFiles {
+		return nil, fmt.Errorf("cannot backup server: %s", err)
+	}
+	return mcBackup, nil
+}
+
+// Backup server, remove world files and set it as active level with new seed, empty seed to random.
+// Server make new world on next start, running server is restarted.
+func (mg *Manager) Regenerate(srv *server.Server, name, seed string) (*server.ServerBackup, error) {
+	if !ValidName(name) {
+		return nil, ErrInvalidName
+	} else if err := mg.lock(srv.ID); err != nil {
+		return nil, err
+	}
+	defer mg.unlock(srv.ID)
+
+	world, err := mg.World(srv, name)
+	if err != nil && err != ErrWorldNotExists {
+		return nil, err
+	}
+	mcBackup, err := mg.backup(srv)
+	if err != nil {
+		return nil, err
+	}
+
+	wasRunning := mg.Runner.Process(srv.ID) != nil
+	if wasRunning {
+		if err := mg.Runner.Stop(srv.ID, StopTimeout); err != nil && mg.Runner.Process(srv.ID) != nil {
+			return mcBackup, fmt.Errorf("cannot stop server: %s", err)
+		}
+	}
+
+	dir := mg.Runner.Dir(srv.ID)
+	relPath := path.Join(Dir, name)
+	if world != nil {
+		relPath = world.Path
+	}
+	for _, dim := range dimensions(srv.Software, path.Base(relPath)) {
+		if err := os.RemoveAll(filepath.Join(dir, filepath.FromSlash(path.Dir(relPath)), dim)); err != nil {
+			return mcBackup, fmt.Errorf("cannot remove world: %s", err)
+		}
+	}
+
+	level := levelName(srv.Software, name)
+	if world != nil && !strings.EqualFold(srv.Software, "bedrock") {
+		level = world.Path // Keep old Java worlds in root
+	}
+	if err := setProperties(dir, map[string]string{"level-name": level, "level-seed": seed}); err != nil {
+		return mcBackup, fmt.Errorf("cannot set world seed: %s", err)
+	}
+	if wasRunning {
+		if _, err := mg.Runner.Start(srv); err != nil {
+			return mcBackup, fmt.Errorf("world removed but cannot start server: %s", err)
+		}
+	}
+	return mcBackup, nil
+}
+
+// Backup server and remove world, active world cannot be deleted
+func (mg *Manager) Delete(srv *server.Server, name string) (*server.ServerBackup, error) {
+	if err := mg.lock(srv.ID); err != nil {
+		return nil, err
+	}
+	defer mg.unlock(srv.ID)
+
+	world, err := mg.World(srv, name)
+	if err != nil {
+		return nil, err
+	} else if world.Active {
+		return nil, ErrActiveWorld
+	}
+	mcBackup, err := mg.backup(srv)
+	if err != nil {
+		return nil, err
+	}
+
+	dir := filepath.Join(mg.Runner.Dir(srv.ID), filepath.FromSlash(path.Dir(world.Path)))
+	for _, dim := range dimensions(srv.Software, world.Name) {
+		if err := os.RemoveAll(filepath.Join(dir, dim)); err != nil {
+			return mcBackup, fmt.Errorf("cannot remove world: %s", err)
+		}
+	}
+	return mcBackup, nil
+}
@@ -0,0 +1,131 @@
+package worlds
+
+import (
+	"archive/zip"
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"sirherobrine23.com.br/go-bds/bds/module/backup"
+	"sirherobrine23.com.br/go-bds/bds/module/db"
+	"sirherobrine23.com.br/go-bds/bds/module/properties"
+	"sirherobrine23.com.br/go-bds/bds/module/runner"
+	"sirherobrine23.com.br/go-bds/bds/module/server"
+	"sirherobrine23.com.br/go-bds/bds/module/users"
+)
+
+func mcworld(files map[string]string) *bytes.Reader {
+	var buff bytes.Buffer
+	zw := zip.NewWriter(&buff)
+	for name, data := range files {
+		w, _ := zw.Create(name)
+		w.Write([]byte(data))
+	}
+	zw.Close()
+	return bytes.NewReader(buff.Bytes())
+}
+
+func TestWorlds(t *testing.T) {
+	database, err := db.NewSqliteConnection(":memory:")
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	user, err := database.CreateNewUser(&users.User{Username: "worlds"}, &users.Password{Password: "test1234"})
+	if err != nil {
+		t.Errorf("cannot make new user in database: %s", err)
+		return
+	}
+	mcServer, err := database.CreateServer(user, &server.Server{Software: "bedrock", Version: "1.21.50", Owner: user.UserID})
+	if err != nil {
+		t.Errorf("cannot make new server in database: %s", err)
+		return
+	}
+
+	root := t.TempDir()
+	manager := runner.NewManager(filepath.Join(root, "servers"), nil)
+	worlds := NewManager(backup.NewManager(filepath.Join(root, "backups"), database, manager), nil)
+	dir := manager.Dir(mcServer.ID)
+	os.MkdirAll(filepath.Join(dir, Dir, "Bedrock level", "db"), 0755)
+	os.WriteFile(filepath.Join(dir, Dir, "Bedrock level", LevelFile), []byte("level"), 0644)
+	os.WriteFile(filepath.Join(dir, properties.FileName), []byte("level-name=Bedrock level\n"), 0644)
+
+	archive := mcworld(map[string]string{LevelFile: "imported", LevelNameFile: "Imported", "db/CURRENT": "MANIFEST-000001\n"})
+	world, err := worlds.Import(mcServer, "", archive, archive.Size())
+	if err != nil {
+		t.Errorf("cannot import world: %s", err)
+		return
+	} else if world.Name != "Imported" || world.Active {
+		t.Errorf("invalid imported world: %+v", world)
+		return
+	} else if _, err = worlds.Import(mcServer, "", archive, archive.Size()); err != ErrWorldExists {
+		t.Errorf("world imported twice: %v", err)
+		return
+	}
+	archive = mcworld(map[string]string{"README.txt": "not world"})
+	if _, err = worlds.Import(mcServer, "other", archive, archive.Size()); err != ErrInvalidWorld {
+		t.Errorf("archive without world imported: %v", err)
+		return
+	}
+
+	var buff bytes.Buffer
+	if err = worlds.Export(mcServer, "Imported", &buff); err != nil {
+		t.Errorf("cannot export world: %s", err)
+		return
+	} else if zr, err := zip.NewReader(bytes.NewReader(buff.Bytes()), int64(buff.Len())); err != nil {
+		t.Errorf("invalid export: %s", err)
+		return
+	} else if _, err = zr.Open(LevelFile); err != nil {
+		t.Errorf("level.dat not in archive root: %s", err)
+		return
+	}
+
+	if world, err = worlds.Switch(mcServer, "Imported"); err != nil || !world.Active {
+		t.Errorf("cannot switch world: %+v %v", world, err)
+		return
+	} else if file, _ := properties.Open(filepath.Join(dir, properties.FileName)); file == nil {
+		t.Errorf("cannot read properties")
+		return
+	} else if level, _ := file.Get("level-name"); level != "Imported" {
+		t.Errorf("level-name not changed: %q", level)
+		return
+	}
+
+	if _, err = worlds.Delete(mcServer, "Imported"); err != ErrActiveWorld {
+		t.Errorf("active world deleted: %v", err)
+		return
+	} else if mcBackup, err := worlds.Delete(mcServer, "Bedrock level"); err != nil || mcBackup == nil {
+		t.Errorf("cannot delete world with backup: %v", err)
+		return
+	} else if list, _ := worlds.List(mcServer); len(list) != 1 {
+		t.Errorf("world not deleted: %+v", list)
+		return
+	}
+
+	if _, err = worlds.Regenerate(mcServer, "Imported", "1234"); err != nil {
+		t.Errorf("cannot regenerate world: %s", err)
+		return
+	} else if _, err = os.Stat(filepath.Join(dir, Dir, "Imported")); err == nil {
+		t.Errorf("world files not removed")
+		return
+	} else if file, _ := properties.Open(filepath.Join(dir, properties.FileName)); file == nil {
+		t.Errorf("cannot read properties")
+	} else if seed, _ := file.Get("level-seed"); seed != "1234" {
+		t.Errorf("seed not set: %q", seed)
+		return
+	}
+
+	// level-name outside server directory is refused
+	os.MkdirAll(filepath.Join(dir, "..", "outside"), 0755)
+	os.WriteFile(filepath.Join(dir, properties.FileName), []byte("level-name=../outside\n"), 0644)
+	if _, err = worlds.List(mcServer); err != ErrInvalidLevel {
+		t.Errorf("level-name outside server listed: %v", err)
+		return
+	} else if _, err = worlds.Regenerate(mcServer, "outside", ""); err != ErrInvalidLevel {
+		t.Errorf("regenerate with level-name outside server: %v", err)
+		return
+	} else if _, err = os.Stat(filepath.Join(dir, "..", "outside")); err != nil {
+		t.Errorf("directory outside server removed")
+	}
+}